@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/reviz0r/golang-layout/pkg/config"
+)
+
+func configFlags(flags *pflag.FlagSet) {
+	flags.String("format", "yaml", "`format` of config: yaml or json")
+}
+
+// configCommand prints config with values of defaults, files, env and flags merged, secrets are redacted
+func configCommand(conf *viper.Viper, layers *config.Layers, flags *pflag.FlagSet, args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		return errors.New("usage: profile config print [--format yaml|json]")
+	}
+
+	format, _ := flags.GetString("format")
+	out, err := config.Render(config.Dump(layers), format)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(string(out))
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/reviz0r/golang-layout/pkg/config"
+	"github.com/reviz0r/golang-layout/pkg/server"
+)
+
+// healthcheckTimeout bounds time of connecting to the server
+const healthcheckTimeout = 5 * time.Second
+
+// healthcheck succeeds if grpc server listening on grpc.address reports it is serving
+// by grpc.health.v1.Health, it is intended for container health checks. Connection uses gateway.tls.
+func healthcheck(conf *viper.Viper, layers *config.Layers, flags *pflag.FlagSet, args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: profile healthcheck")
+	}
+
+	c, err := config.Load(conf)
+	if err != nil {
+		return err
+	}
+
+	// address without host is dialed and verified as localhost
+	address := c.GRPC.Address
+	if host, port, err := net.SplitHostPort(address); err == nil && host == "" {
+		address = net.JoinHostPort("localhost", port)
+	}
+
+	credentials, store, err := server.GatewayCredentials(c.Gateway.TLS, address)
+	if err != nil {
+		return fmt.Errorf("healthcheck: %v", err)
+	}
+	if store != nil {
+		defer store.Close()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthcheckTimeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, address, credentials, grpc.WithBlock())
+	if err != nil {
+		return fmt.Errorf("healthcheck: cannot connect to grpc server: %v", err)
+	}
+	defer conn.Close()
+
+	res, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		return fmt.Errorf("healthcheck: %v", err)
+	}
+	if res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("healthcheck: server is %s", res.GetStatus())
+	}
+
+	fmt.Println("ok")
+	return nil
+}
@@ -1,45 +1,117 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
 	"go.uber.org/fx"
 
 	"github.com/reviz0r/golang-layout/pkg/config"
-	"github.com/reviz0r/golang-layout/pkg/db"
 	"github.com/reviz0r/golang-layout/pkg/logger"
-	"github.com/reviz0r/golang-layout/pkg/server"
-	"github.com/reviz0r/golang-layout/pkg/tracer"
-
-	profileInternal "github.com/reviz0r/golang-layout/internal/profile"
-	profilePkg "github.com/reviz0r/golang-layout/pkg/profile"
 )
 
+// command is subcommand of the binary, its args are left after config flags are parsed.
+// flags registers flags of the command, it is nil if there are no ones.
+type command struct {
+	name        string
+	args        string
+	description string
+	flags       func(flags *pflag.FlagSet)
+	run         func(conf *viper.Viper, layers *config.Layers, flags *pflag.FlagSet, args []string) error
+}
+
+var commands = []command{
+	{"serve", "", "run grpc and http servers (default)", nil, serve},
+	{"migrate", "up|down|status|goto N", "apply or revert database migrations", nil, migrate},
+	{"config", "print", "print effective config with secrets redacted", configFlags, configCommand},
+	{"users", "import|export", "import users from stdin or export them to stdout as CSV or NDJSON", usersFlags, usersCommand},
+	{"healthcheck", "", "check that grpc server is serving", nil, healthcheck},
+	{"version", "", "print version", nil, printVersion},
+}
+
 func main() {
-	app := fx.New(
-		fx.NopLogger,
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
 
-		config.Module,
-		config.DefaultValues,
-		logger.Module,
+func run(args []string) error {
+	name := "serve"
+	if len(args) != 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
 
-		db.Module,
+	if name == "help" {
+		fmt.Print(usage())
+		return nil
+	}
 
-		// grpc modules
-		server.Module,
-		server.InterceptorsModule,
-		server.GrpcLoggingPayloadModule,
-		server.PrometheusMetrics,
-		tracer.Module,
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		return fmt.Errorf("unknown command %q\n%s", name, usage())
+	}
 
-		// gateway modules
-		server.GatewayMuxModule,
-		server.HTTPModule,
-		server.PrometheusMetricsHandler,
+	sources, err := config.ParseSources(args)
+	if err != nil {
+		return err
+	}
 
-		// logic modules
-		profileInternal.Module,
-		profilePkg.GatewayModule,
-		profilePkg.SwaggerModule,
-	)
+	conf, layers, err := config.NewConfigFrom(sources)
+	if err != nil {
+		return err
+	}
+	config.SetConfigDefaults(conf)
 
-	app.Run()
+	flags := pflag.NewFlagSet("profile "+name, pflag.ContinueOnError)
+	flags.SortFlags = false
+	if cmd.flags != nil {
+		cmd.flags(flags)
+	}
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: profile %s %s\n%s\n\nflags:\n%s", cmd.name, cmd.args, cmd.description, flags.FlagUsages())
+	}
+	if err := config.ParseFlags(conf, layers, flags, args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	// invalid config is reported before any command runs, with all its problems at once
+	if _, err := config.Load(conf); err != nil {
+		return err
+	}
+
+	return cmd.run(conf, layers, flags, flags.Args())
+}
+
+func usage() string {
+	var b strings.Builder
+	b.WriteString("usage: profile <command> [args] [--config file] [--env env] [--key=value] [--set key=value]\n\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(&b, "  %-30s %s\n", cmd.name+" "+cmd.args, cmd.description)
+	}
+	b.WriteString("\nrun 'profile <command> --help' for config flags\n")
+	return b.String()
+}
+
+// baseModules give config parsed from flags and logger to app of a command
+func baseModules(conf *viper.Viper, layers *config.Layers) fx.Option {
+	return fx.Options(
+		fx.NopLogger,
+
+		config.Use(conf, layers),
+		config.DefaultValues,
+		logger.Module,
+	)
 }
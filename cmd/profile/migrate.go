@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.uber.org/fx"
+
+	"github.com/reviz0r/golang-layout/pkg/config"
+	"github.com/reviz0r/golang-layout/pkg/db"
+)
+
+const migrateUsage = `usage: profile migrate up|down|status|goto N
+  up       apply all pending migrations
+  down     revert the last applied migration
+  status   print the current version and pending migrations
+  goto N   apply or revert migrations up to version N, 0 reverts all`
+
+// migrate runs migrate subcommand against database.dsn
+func migrate(conf *viper.Viper, layers *config.Layers, flags *pflag.FlagSet, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	var migrator *db.Migrator
+	app := fx.New(
+		baseModules(conf, layers),
+
+		fx.Provide(db.NewDatabase, db.NewMigrator),
+		fx.Populate(&migrator),
+	)
+
+	ctx := context.Background()
+	if err := app.Start(ctx); err != nil {
+		return err
+	}
+	defer app.Stop(ctx)
+
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		return migrator.Up(ctx)
+
+	case args[0] == "down" && len(args) == 1:
+		return migrator.Down(ctx)
+
+	case args[0] == "goto" && len(args) == 2:
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version %q\n%s", args[1], migrateUsage)
+		}
+		return migrator.Goto(ctx, version)
+
+	case args[0] == "status" && len(args) == 1:
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("version: %d", status.Version)
+		if status.Dirty {
+			fmt.Print(" (dirty)")
+		}
+		fmt.Println()
+		for _, m := range status.Applied {
+			fmt.Printf("  applied  %06d_%s\n", m.Version, m.Name)
+		}
+		for _, m := range status.Pending {
+			fmt.Printf("  pending  %06d_%s\n", m.Version, m.Name)
+		}
+		return nil
+	}
+
+	return errors.New(migrateUsage)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.uber.org/fx"
+
+	"github.com/reviz0r/golang-layout/pkg/auth"
+	"github.com/reviz0r/golang-layout/pkg/config"
+	"github.com/reviz0r/golang-layout/pkg/db"
+	"github.com/reviz0r/golang-layout/pkg/health"
+	"github.com/reviz0r/golang-layout/pkg/logger"
+	"github.com/reviz0r/golang-layout/pkg/outbox"
+	"github.com/reviz0r/golang-layout/pkg/server"
+	"github.com/reviz0r/golang-layout/pkg/tracer"
+
+	profileInternal "github.com/reviz0r/golang-layout/internal/profile"
+	profilePkg "github.com/reviz0r/golang-layout/pkg/profile"
+)
+
+// serve runs servers until the process is stopped
+func serve(conf *viper.Viper, layers *config.Layers, flags *pflag.FlagSet, args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: profile serve")
+	}
+
+	app := fx.New(
+		baseModules(conf, layers),
+		config.LogOnStart,
+
+		db.Module,
+		db.ReloadModule,
+		logger.ReloadModule,
+		outbox.Module,
+
+		// grpc modules
+		server.Module,
+		server.InterceptorsModule,
+		auth.Module,
+		server.GrpcLoggingPayloadModule,
+		server.PrometheusMetrics,
+		tracer.Module,
+
+		// gateway modules
+		server.GatewayMuxModule,
+		server.HTTPModule,
+		server.PrometheusMetricsHandler,
+		server.ConfigDebugHandler,
+		health.Module,
+
+		// logic modules
+		profileInternal.Module,
+		profileInternal.PurgeModule,
+		profilePkg.GatewayModule,
+		profilePkg.ImportExportModule,
+		profilePkg.SwaggerModule,
+	)
+
+	app.Run()
+	return nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.uber.org/fx"
+
+	profileInternal "github.com/reviz0r/golang-layout/internal/profile"
+	"github.com/reviz0r/golang-layout/pkg/auth"
+	"github.com/reviz0r/golang-layout/pkg/config"
+	"github.com/reviz0r/golang-layout/pkg/db"
+	profilePkg "github.com/reviz0r/golang-layout/pkg/profile"
+)
+
+// cliSubject is actor of changes made by commands, it is recorded to audit log
+const cliSubject = "cli"
+
+// importBatchSize is number of rows imported in one transaction
+const importBatchSize = 500
+
+func usersFlags(flags *pflag.FlagSet) {
+	flags.String("format", profilePkg.FormatNDJSON, "`format` of users: csv or ndjson")
+	flags.Bool("dry-run", false, "only check imported users")
+	flags.String("filter", "", "filter `expression` of exported users")
+	flags.Bool("show-deleted", false, "export deleted users too")
+}
+
+// usersCommand runs user service in process to import or export users
+func usersCommand(conf *viper.Viper, layers *config.Layers, flags *pflag.FlagSet, args []string) error {
+	if len(args) != 1 || (args[0] != "import" && args[0] != "export") {
+		return errors.New("usage: profile users import|export")
+	}
+
+	var users profileInternal.UserRepository
+	app := fx.New(
+		baseModules(conf, layers),
+
+		db.Module,
+		fx.Provide(profileInternal.NewPostgresUserRepository),
+		fx.Populate(&users),
+	)
+
+	ctx := context.Background()
+	if err := app.Start(ctx); err != nil {
+		return err
+	}
+	defer app.Stop(ctx)
+
+	service := &profileInternal.UserService{Users: users}
+	ctx = auth.NewContext(ctx, &auth.Principal{Subject: cliSubject})
+
+	format, _ := flags.GetString("format")
+
+	if args[0] == "import" {
+		dryRun, _ := flags.GetBool("dry-run")
+		return importUsers(ctx, service, os.Stdin, format, dryRun)
+	}
+
+	filter, _ := flags.GetString("filter")
+	showDeleted, _ := flags.GetBool("show-deleted")
+	return exportUsers(ctx, service, os.Stdout, format, &profilePkg.ExportUsersRequest{Filter: filter, ShowDeleted: showDeleted})
+}
+
+// importUsers imports rows in batches and prints summary, it stops on unreadable row
+func importUsers(ctx context.Context, service *profileInternal.UserService, r io.Reader, format string, dryRun bool) error {
+	rows, err := profilePkg.NewRowReader(r, format)
+	if err != nil {
+		return err
+	}
+
+	imp := profileInternal.NewUserImport(dryRun)
+	var batch []*profilePkg.ImportUsersRequest_Row
+	for {
+		row, err := rows.Read()
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if row != nil {
+			batch = append(batch, row)
+		}
+
+		if len(batch) == importBatchSize || (err == io.EOF && len(batch) != 0) {
+			if err := service.ImportBatch(ctx, imp, batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	s, err := (&jsonpb.Marshaler{OrigName: true, Indent: "  "}).MarshalToString(imp.Result())
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(s)
+	return nil
+}
+
+// exportUsers writes users selected by request in format
+func exportUsers(ctx context.Context, service *profileInternal.UserService, w io.Writer, format string, in *profilePkg.ExportUsersRequest) error {
+	users, err := profilePkg.NewUserWriter(w, format)
+	if err != nil {
+		return err
+	}
+
+	if err := service.Export(ctx, in, users.Write); err != nil {
+		return err
+	}
+
+	return users.Flush()
+}
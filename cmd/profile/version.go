@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/reviz0r/golang-layout/pkg/config"
+)
+
+// version and commit are set on build by -ldflags "-X main.version=... -X main.commit=..."
+var (
+	version = "dev"
+	commit  = "unknown"
+)
+
+func printVersion(conf *viper.Viper, layers *config.Layers, flags *pflag.FlagSet, args []string) error {
+	fmt.Printf("profile %s (commit %s, %s)\n", version, commit, runtime.Version())
+	return nil
+}
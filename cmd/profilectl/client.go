@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/reviz0r/golang-layout/pkg/profile"
+)
+
+// client is connection to user service with settings of requests and output
+type client struct {
+	profile.UserServiceClient
+
+	conn    *grpc.ClientConn
+	token   string
+	apiKey  string
+	timeout time.Duration
+	output  string
+}
+
+func dial(conf *viper.Viper) (*client, error) {
+	output := conf.GetString("output")
+	switch output {
+	case outputTable, outputJSON, outputYAML:
+	default:
+		return nil, fmt.Errorf("unknown output format %q", output)
+	}
+
+	opts := []grpc.DialOption{grpc.WithInsecure()}
+	if conf.GetBool("tls.enabled") {
+		config, err := tlsConfig(conf)
+		if err != nil {
+			return nil, err
+		}
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(config))}
+	}
+
+	conn, err := grpc.Dial(conf.GetString("address"), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to %s: %v", conf.GetString("address"), err)
+	}
+
+	return &client{
+		UserServiceClient: profile.NewUserServiceClient(conn),
+
+		conn:    conn,
+		token:   conf.GetString("token"),
+		apiKey:  conf.GetString("api_key"),
+		timeout: conf.GetDuration("timeout"),
+		output:  output,
+	}, nil
+}
+
+func tlsConfig(conf *viper.Viper) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         conf.GetString("tls.server_name"),
+		InsecureSkipVerify: conf.GetBool("tls.insecure_skip_verify"),
+	}
+
+	if caFile := conf.GetString("tls.ca_file"); caFile != "" {
+		ca, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates in %s", caFile)
+		}
+	}
+
+	certFile, keyFile := conf.GetString("tls.cert_file"), conf.GetString("tls.key_file")
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("both tls.cert_file and tls.key_file must be set for mutual TLS")
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// context gives context of request with timeout, auth token and API key
+func (c *client) context() (context.Context, context.CancelFunc) {
+	ctx := context.Background()
+	if c.token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+c.token)
+	}
+	if c.apiKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", c.apiKey)
+	}
+
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+func (c *client) Close() error {
+	return c.conn.Close()
+}
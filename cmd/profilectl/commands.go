@@ -0,0 +1,212 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"google.golang.org/genproto/protobuf/field_mask"
+
+	"github.com/reviz0r/golang-layout/pkg/profile"
+)
+
+func createFlags(flags *pflag.FlagSet) {
+	flags.String("name", "", "`name` of user")
+	flags.String("email", "", "`email` of user")
+}
+
+func create(c *client, flags *pflag.FlagSet, args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: profilectl create --name NAME --email EMAIL")
+	}
+
+	name, _ := flags.GetString("name")
+	email, _ := flags.GetString("email")
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	res, err := c.Create(ctx, &profile.CreateRequest{User: &profile.User{Name: name, Email: email}})
+	if err != nil {
+		return err
+	}
+
+	return c.print(res, []string{"id"})
+}
+
+func getFlags(flags *pflag.FlagSet) {
+	fieldsFlag(flags, "fields to print, all by default")
+	flags.Bool("show-deleted", false, "give user even if it is deleted")
+}
+
+func get(c *client, flags *pflag.FlagSet, args []string) error {
+	id, err := userID(args, "usage: profilectl get ID")
+	if err != nil {
+		return err
+	}
+
+	fields, _ := flags.GetStringSlice("fields")
+	showDeleted, _ := flags.GetBool("show-deleted")
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	res, err := c.Read(ctx, &profile.ReadRequest{Id: id, Fields: fieldMask(fields), ShowDeleted: showDeleted})
+	if err != nil {
+		return err
+	}
+
+	return c.printUsers([]*profile.User{res.GetUser()}, fields)
+}
+
+func listFlags(flags *pflag.FlagSet) {
+	fieldsFlag(flags, "fields to print, all by default")
+	flags.String("filter", "", "filter `expression`, e.g. 'email:\"*@corp.com\"'")
+	flags.String("order-by", "", "order of users, e.g. 'name desc, id'")
+	flags.Int32("limit", 100, "number of users on page")
+	flags.String("page-token", "", "`token` of page from previous list")
+	flags.Bool("all", false, "follow page tokens to list all users")
+	flags.Bool("show-deleted", false, "list deleted users too")
+	flags.Bool("total", false, "print total count of users")
+}
+
+func list(c *client, flags *pflag.FlagSet, args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: profilectl list")
+	}
+
+	fields, _ := flags.GetStringSlice("fields")
+	all, _ := flags.GetBool("all")
+
+	req := &profile.ReadAllRequest{Fields: fieldMask(fields)}
+	req.Filter, _ = flags.GetString("filter")
+	req.OrderBy, _ = flags.GetString("order-by")
+	req.Limit, _ = flags.GetInt32("limit")
+	req.PageToken, _ = flags.GetString("page-token")
+	req.ShowDeleted, _ = flags.GetBool("show-deleted")
+	req.IncludeTotal, _ = flags.GetBool("total")
+
+	merged := &profile.ReadAllResponse{}
+	for {
+		ctx, cancel := c.context()
+		res, err := c.ReadAll(ctx, req)
+		cancel()
+		if err != nil {
+			return err
+		}
+
+		merged.Users = append(merged.Users, res.GetUsers()...)
+		merged.Limit, merged.Total, merged.NextPageToken = res.GetLimit(), res.GetTotal(), res.GetNextPageToken()
+
+		if !all || res.GetNextPageToken() == "" {
+			break
+		}
+		req.PageToken, req.IncludeTotal = res.GetNextPageToken(), false
+	}
+
+	if c.output != outputTable {
+		return c.print(merged, nil)
+	}
+
+	if err := c.printUsers(merged.GetUsers(), fields); err != nil {
+		return err
+	}
+	if req.GetIncludeTotal() || merged.GetTotal() != 0 {
+		fmt.Fprintf(os.Stderr, "total: %d\n", merged.GetTotal())
+	}
+	if merged.GetNextPageToken() != "" {
+		fmt.Fprintf(os.Stderr, "next page: --page-token %s\n", merged.GetNextPageToken())
+	}
+
+	return nil
+}
+
+func updateFlags(flags *pflag.FlagSet) {
+	fieldsFlag(flags, "fields to update (required)")
+	flags.String("name", "", "new `name` of user")
+	flags.String("email", "", "new `email` of user")
+	flags.String("etag", "", "`etag` of user from previous get, update fails if user was changed since then")
+}
+
+func update(c *client, flags *pflag.FlagSet, args []string) error {
+	id, err := userID(args, "usage: profilectl update ID --fields name,email")
+	if err != nil {
+		return err
+	}
+
+	fields, _ := flags.GetStringSlice("fields")
+	if len(fields) == 0 {
+		return errors.New("--fields must be specified")
+	}
+
+	req := &profile.UpdateRequest{Id: id, User: &profile.User{}, Fields: fieldMask(fields)}
+	req.User.Name, _ = flags.GetString("name")
+	req.User.Email, _ = flags.GetString("email")
+	req.Etag, _ = flags.GetString("etag")
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	if _, err := c.Update(ctx, req); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "user %d updated\n", id)
+	return nil
+}
+
+func deleteFlags(flags *pflag.FlagSet) {
+	flags.String("etag", "", "`etag` of user from previous get, delete fails if user was changed since then")
+}
+
+// remove deletes user, it is not named delete to keep the builtin
+func remove(c *client, flags *pflag.FlagSet, args []string) error {
+	id, err := userID(args, "usage: profilectl delete ID")
+	if err != nil {
+		return err
+	}
+
+	etag, _ := flags.GetString("etag")
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	if _, err := c.Delete(ctx, &profile.DeleteRequest{Id: id, Etag: etag}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "user %d deleted\n", id)
+	return nil
+}
+
+func fieldsFlag(flags *pflag.FlagSet, usage string) {
+	flags.StringSlice("fields", nil, usage+", e.g. name,email")
+}
+
+func fieldMask(fields []string) *field_mask.FieldMask {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	paths := make([]string, len(fields))
+	for i, field := range fields {
+		paths[i] = strings.TrimSpace(field)
+	}
+	return &field_mask.FieldMask{Paths: paths}
+}
+
+func userID(args []string, usage string) (int64, error) {
+	if len(args) != 1 {
+		return 0, errors.New(usage)
+	}
+
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid user id %q", args[0])
+	}
+
+	return id, nil
+}
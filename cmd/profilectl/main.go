@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// command is subcommand of profilectl, it registers its own flags before args are parsed
+type command struct {
+	name        string
+	args        string
+	description string
+	flags       func(flags *pflag.FlagSet)
+	run         func(c *client, flags *pflag.FlagSet, args []string) error
+}
+
+var commands = []command{
+	{"create", "--name NAME --email EMAIL", "create user and print its id", createFlags, create},
+	{"get", "ID", "print user", getFlags, get},
+	{"list", "", "print users page by page", listFlags, list},
+	{"update", "ID --fields name,email", "update given fields of user", updateFlags, update},
+	{"delete", "ID", "delete user", deleteFlags, remove},
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "--help" || args[0] == "-h" {
+		fmt.Print(usage())
+		return nil
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == args[0] {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		return fmt.Errorf("unknown command %q\n%s", args[0], usage())
+	}
+
+	flags := pflag.NewFlagSet("profilectl "+cmd.name, pflag.ContinueOnError)
+	flags.SortFlags = false
+	cmd.flags(flags)
+	connectionFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: profilectl %s %s\n%s\n\nflags:\n%s", cmd.name, cmd.args, cmd.description, flags.FlagUsages())
+	}
+
+	if err := flags.Parse(args[1:]); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	conf, err := newConfig(flags)
+	if err != nil {
+		return err
+	}
+
+	c, err := dial(conf)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	return cmd.run(c, flags, flags.Args())
+}
+
+func usage() string {
+	var b strings.Builder
+	b.WriteString("usage: profilectl <command> [args] [flags]\n\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(&b, "  %-35s %s\n", cmd.name+" "+cmd.args, cmd.description)
+	}
+	b.WriteString(`
+connection is configured by flags, PROFILECTL_* env (e.g. PROFILECTL_TOKEN, PROFILECTL_API_KEY,
+PROFILECTL_TLS_CA_FILE)
+or profilectl.yaml in the current directory or in $HOME/.config/profilectl
+
+run 'profilectl <command> --help' for flags
+`)
+	return b.String()
+}
+
+// connectionFlags registers flags of connection and output shared by all commands
+func connectionFlags(flags *pflag.FlagSet) {
+	flags.String("config", "", "config `file`, by default profilectl.yaml is searched")
+	flags.String("address", "localhost:50051", "`host:port` of grpc server")
+	flags.Duration("timeout", 10*time.Second, "timeout of request")
+	flags.String("token", "", "bearer `token` sent in authorization metadata")
+	flags.String("api-key", "", "API `key` sent in x-api-key metadata")
+	flags.Bool("tls.enabled", false, "connect with TLS")
+	flags.String("tls.ca_file", "", "CA `file` to verify server, system pool is used by default")
+	flags.String("tls.cert_file", "", "client certificate `file` for mutual TLS")
+	flags.String("tls.key_file", "", "client key `file` for mutual TLS")
+	flags.String("tls.server_name", "", "server `name` to verify instead of host of address")
+	flags.Bool("tls.insecure_skip_verify", false, "do not verify server certificate")
+	flags.StringP("output", "o", "table", "output `format`: table, json or yaml")
+}
+
+// newConfig gives settings from flags, env and config file in this order of precedence
+func newConfig(flags *pflag.FlagSet) (*viper.Viper, error) {
+	conf := viper.New()
+	conf.SetEnvPrefix("profilectl")
+	conf.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	conf.AutomaticEnv()
+
+	if err := conf.BindPFlags(flags); err != nil {
+		return nil, err
+	}
+	// key of config and env must not have dash, flag has it as other CLIs do
+	if err := conf.BindPFlag("api_key", flags.Lookup("api-key")); err != nil {
+		return nil, err
+	}
+
+	if file := conf.GetString("config"); file != "" {
+		conf.SetConfigFile(file)
+	} else {
+		conf.SetConfigName("profilectl")
+		conf.SetConfigType("yaml")
+		conf.AddConfigPath(".")
+		conf.AddConfigPath("$HOME/.config/profilectl")
+	}
+
+	if err := conf.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return nil, err
+		}
+	}
+
+	return conf, nil
+}
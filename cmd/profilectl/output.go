@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"gopkg.in/yaml.v2"
+
+	"github.com/reviz0r/golang-layout/pkg/profile"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// userColumns are printed in table if fields are not specified
+var userColumns = []string{"id", "name", "email", "etag", "create_time", "update_time", "delete_time"}
+
+// print prints message in output format, table has given columns of the message
+func (c *client) print(msg proto.Message, columns []string) error {
+	switch c.output {
+	case outputJSON:
+		if err := (&jsonpb.Marshaler{OrigName: true, Indent: "  "}).Marshal(os.Stdout, msg); err != nil {
+			return err
+		}
+		_, err := fmt.Println()
+		return err
+
+	case outputYAML:
+		var buf bytes.Buffer
+		if err := (&jsonpb.Marshaler{OrigName: true}).Marshal(&buf, msg); err != nil {
+			return err
+		}
+
+		// JSON is YAML, MapSlice keeps order of fields
+		var doc yaml.MapSlice
+		if err := yaml.Unmarshal(buf.Bytes(), &doc); err != nil {
+			return err
+		}
+
+		out, err := yaml.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+
+	return printTable([]proto.Message{msg}, columns)
+}
+
+// printUsers prints users as list in JSON and YAML, and as rows of given fields in table
+func (c *client) printUsers(users []*profile.User, fields []string) error {
+	if c.output != outputTable {
+		return c.print(&profile.ReadAllResponse{Users: users}, nil)
+	}
+
+	columns := userColumns
+	if len(fields) != 0 {
+		columns = fields
+	}
+
+	rows := make([]proto.Message, len(users))
+	for i, user := range users {
+		rows[i] = user
+	}
+
+	return printTable(rows, columns)
+}
+
+func printTable(rows []proto.Message, columns []string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.ToUpper(strings.Join(columns, "\t")))
+
+	marshaler := jsonpb.Marshaler{OrigName: true}
+	for _, row := range rows {
+		s, err := marshaler.MarshalToString(row)
+		if err != nil {
+			return err
+		}
+
+		var values map[string]interface{}
+		if err := json.Unmarshal([]byte(s), &values); err != nil {
+			return err
+		}
+
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			if value, ok := values[strings.TrimSpace(column)]; ok {
+				cells[i] = fmt.Sprint(value)
+			}
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,18 @@
+package profile
+
+import (
+	"context"
+
+	"github.com/volatiletech/null"
+
+	"github.com/reviz0r/golang-layout/pkg/auth"
+)
+
+// actor gives subject of the authenticated caller, it is null for anonymous calls
+func actor(ctx context.Context) null.String {
+	p, ok := auth.FromContext(ctx)
+	if !ok || p.Subject == "" {
+		return null.String{}
+	}
+	return null.StringFrom(p.Subject)
+}
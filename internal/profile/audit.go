@@ -0,0 +1,143 @@
+package profile
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/golang/protobuf/jsonpb"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"github.com/opentracing/basictracer-go"
+	"github.com/opentracing/opentracing-go"
+	"github.com/volatiletech/null"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/reviz0r/golang-layout/internal/profile/models"
+	"github.com/reviz0r/golang-layout/pkg/profile"
+)
+
+// userAuditColumns are columns of created user recorded in audit log
+var userAuditColumns = []string{models.UserColumns.Name, models.UserColumns.Email}
+
+// userEventsTopic is outbox topic of user changes, message payload is UserAuditEvent
+const userEventsTopic = "users"
+
+// requestIDMetadata is x-request-id header forwarded by grpc-gateway or sent by grpc client
+const requestIDMetadata = "x-request-id"
+
+// writeAuditEvent records change of user, it must be called in transaction of the change.
+// before and after hold changed columns only, nil means there was no user before or after.
+func writeAuditEvent(ctx context.Context, users UserRepository, method string, userID int64, before, after models.M) error {
+	event := &models.UserAuditEvent{
+		UserID:    userID,
+		Method:    method,
+		Actor:     actor(ctx),
+		RequestID: requestID(ctx),
+		TraceID:   traceID(ctx),
+	}
+
+	var err error
+	if event.Before, err = auditValues(before); err != nil {
+		return err
+	}
+	if event.After, err = auditValues(after); err != nil {
+		return err
+	}
+
+	return users.CreateAuditEvent(ctx, event)
+}
+
+// auditEventPayload gives outbox payload of the recorded event
+func auditEventPayload(event *models.UserAuditEvent) ([]byte, error) {
+	pbEvent, err := auditEventToProto(event)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := new(jsonpb.Marshaler).MarshalToString(pbEvent)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(payload), nil
+}
+
+func auditValues(values models.M) (null.JSON, error) {
+	if values == nil {
+		return null.JSON{}, nil
+	}
+
+	b, err := json.Marshal(values)
+	if err != nil {
+		return null.JSON{}, err
+	}
+
+	return null.JSONFrom(b), nil
+}
+
+// requestID gives x-request-id of the request, it is null if there is no one
+func requestID(ctx context.Context) null.String {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return null.String{}
+	}
+
+	values := md.Get(requestIDMetadata)
+	if len(values) == 0 || values[0] == "" {
+		return null.String{}
+	}
+
+	return null.StringFrom(values[0])
+}
+
+// traceID gives id of the trace of the request, it is null if tracing interceptor is not installed
+func traceID(ctx context.Context) null.String {
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return null.String{}
+	}
+
+	sc, ok := span.Context().(basictracer.SpanContext)
+	if !ok {
+		return null.String{}
+	}
+
+	return null.StringFrom(strconv.FormatUint(sc.TraceID, 16))
+}
+
+func auditEventToProto(in *models.UserAuditEvent) (*profile.UserAuditEvent, error) {
+	before, err := auditValuesToProto(in.Before)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := auditValuesToProto(in.After)
+	if err != nil {
+		return nil, err
+	}
+
+	return &profile.UserAuditEvent{
+		Id:         in.ID,
+		UserId:     in.UserID,
+		Method:     in.Method,
+		Actor:      in.Actor.String,
+		Before:     before,
+		After:      after,
+		RequestId:  in.RequestID.String,
+		TraceId:    in.TraceID.String,
+		CreateTime: timeToProto(in.CreatedAt),
+	}, nil
+}
+
+func auditValuesToProto(in null.JSON) (*structpb.Struct, error) {
+	if !in.Valid {
+		return nil, nil
+	}
+
+	out := new(structpb.Struct)
+	if err := jsonpb.UnmarshalString(string(in.JSON), out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
@@ -0,0 +1,60 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// userError gives status for error of repository or of transaction function.
+// Errors which are already statuses are given as is, unknown errors are Internal.
+func userError(method string, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		return status.Error(codes.NotFound, codes.NotFound.String())
+	case errors.Is(err, ErrUserVersionMismatch):
+		return status.Errorf(codes.Aborted, "%s: %s", method, err.Error())
+	case errors.Is(err, ErrUserNotDeleted):
+		return status.Errorf(codes.FailedPrecondition, "%s: %s", method, err.Error())
+	}
+
+	if err := alreadyExistsError(method, "", err); err != nil {
+		return err
+	}
+
+	return status.Errorf(codes.Internal, "%s: %s", method, err.Error())
+}
+
+// alreadyExistsError gives AlreadyExists status with conflicting field in google.rpc.BadRequest details,
+// or nil if err is not UserExistsError. item is path of user in request, e.g. "users[1]" for batches.
+func alreadyExistsError(method, item string, err error) error {
+	var existsErr *UserExistsError
+	if !errors.As(err, &existsErr) {
+		return nil
+	}
+
+	field := existsErr.Field
+	description := existsErr.Error()
+	message := fmt.Sprintf("%s: %s", method, description)
+	if item != "" {
+		field = item + "." + field
+		message = fmt.Sprintf("%s: %s: %s", method, item, description)
+	}
+
+	st := status.New(codes.AlreadyExists, message)
+	detailed, err := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: field, Description: description}},
+	})
+	if err != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
@@ -0,0 +1,48 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// ifMatchMetadata is If-Match header forwarded by grpc-gateway
+const ifMatchMetadata = "grpcgateway-if-match"
+
+// formatETag gives etag of user version, version is 0 if it was not selected
+func formatETag(version int64) string {
+	if version == 0 {
+		return ""
+	}
+	return strconv.FormatInt(version, 10)
+}
+
+// requestVersion gives user version expected by request: from etag field or If-Match header.
+// It gives 0 if request has no precondition.
+func requestVersion(ctx context.Context, etag string) (int64, error) {
+	if etag == "" {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(ifMatchMetadata); len(values) != 0 {
+				etag = values[0]
+			}
+		}
+	}
+
+	// accept HTTP forms too: "3", W/"3"
+	s := strings.TrimSpace(etag)
+	s = strings.TrimPrefix(s, "W/")
+	s = strings.Trim(s, `"`)
+	if s == "" || s == "*" {
+		return 0, nil
+	}
+
+	version, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("invalid etag %q", etag)
+	}
+
+	return version, nil
+}
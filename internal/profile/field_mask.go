@@ -0,0 +1,25 @@
+package profile
+
+import (
+	"github.com/reviz0r/golang-layout/internal/profile/models"
+	"github.com/reviz0r/golang-layout/pkg/fieldmask"
+)
+
+// userReadMask maps proto fields of User which can be read to their columns
+var userReadMask = fieldmask.Mapping{
+	"id":          models.UserColumns.ID,
+	"name":        models.UserColumns.Name,
+	"email":       models.UserColumns.Email,
+	"etag":        models.UserColumns.Version,
+	"delete_time": models.UserColumns.DeletedAt,
+	"create_time": models.UserColumns.CreatedAt,
+	"update_time": models.UserColumns.UpdatedAt,
+	"created_by":  models.UserColumns.CreatedBy,
+	"updated_by":  models.UserColumns.UpdatedBy,
+}
+
+// userUpdateMask maps proto fields of User which can be updated to their columns
+var userUpdateMask = fieldmask.Mapping{
+	"name":  models.UserColumns.Name,
+	"email": models.UserColumns.Email,
+}
@@ -0,0 +1,487 @@
+package profile
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/volatiletech/sqlboiler/queries/qm"
+
+	"github.com/reviz0r/golang-layout/internal/profile/models"
+)
+
+// Filter expressions follow AIP-160 (https://google.aip.dev/160):
+//
+//	email:"*@corp.com" AND name!="bot"
+//	id>10 OR (name="alice" AND NOT email:"*@example.com")
+//
+// Supported comparators are =, !=, <, <=, >, >= and : (match with * wildcards).
+// As in AIP-160, OR binds tighter than AND.
+
+type int64Where interface {
+	EQ(x int64) qm.QueryMod
+	NEQ(x int64) qm.QueryMod
+	LT(x int64) qm.QueryMod
+	LTE(x int64) qm.QueryMod
+	GT(x int64) qm.QueryMod
+	GTE(x int64) qm.QueryMod
+}
+
+type stringWhere interface {
+	EQ(x string) qm.QueryMod
+	NEQ(x string) qm.QueryMod
+	LT(x string) qm.QueryMod
+	LTE(x string) qm.QueryMod
+	GT(x string) qm.QueryMod
+	GTE(x string) qm.QueryMod
+}
+
+// userFilter is compiled filter, it is applied by postgres repository as where clause
+// and by in-memory repository as predicate
+type userFilter struct {
+	mod   qm.QueryMod
+	match func(u *models.User) bool
+}
+
+// filterField builds filter for restriction on one field
+type filterField func(op, value string) (*userFilter, error)
+
+// userFilterFields maps filterable proto fields of User to their columns
+var userFilterFields = map[string]filterField{
+	"id":    int64Filter(models.UserWhere.ID, func(u *models.User) int64 { return u.ID }),
+	"name":  stringFilter(models.UserWhere.Name, `"users"."name"`, func(u *models.User) string { return u.Name }),
+	"email": stringFilter(models.UserWhere.Email, `"users"."email"`, func(u *models.User) string { return u.Email }),
+}
+
+func int64Filter(w int64Where, field func(u *models.User) int64) filterField {
+	return func(op, value string) (*userFilter, error) {
+		if op == ":" || op == "!:" {
+			return nil, fmt.Errorf("operator %q is not supported", ":")
+		}
+
+		x, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected integer value, got %q", value)
+		}
+
+		switch op {
+		case "=":
+			return &userFilter{w.EQ(x), func(u *models.User) bool { return field(u) == x }}, nil
+		case "!=":
+			return &userFilter{w.NEQ(x), func(u *models.User) bool { return field(u) != x }}, nil
+		case "<":
+			return &userFilter{w.LT(x), func(u *models.User) bool { return field(u) < x }}, nil
+		case "<=":
+			return &userFilter{w.LTE(x), func(u *models.User) bool { return field(u) <= x }}, nil
+		case ">":
+			return &userFilter{w.GT(x), func(u *models.User) bool { return field(u) > x }}, nil
+		case ">=":
+			return &userFilter{w.GTE(x), func(u *models.User) bool { return field(u) >= x }}, nil
+		}
+
+		return nil, fmt.Errorf("operator %q is not supported", op)
+	}
+}
+
+func stringFilter(w stringWhere, column string, field func(u *models.User) string) filterField {
+	return func(op, value string) (*userFilter, error) {
+		switch op {
+		case "=":
+			return &userFilter{w.EQ(value), func(u *models.User) bool { return field(u) == value }}, nil
+		case "!=":
+			return &userFilter{w.NEQ(value), func(u *models.User) bool { return field(u) != value }}, nil
+		case "<":
+			return &userFilter{w.LT(value), func(u *models.User) bool { return field(u) < value }}, nil
+		case "<=":
+			return &userFilter{w.LTE(value), func(u *models.User) bool { return field(u) <= value }}, nil
+		case ">":
+			return &userFilter{w.GT(value), func(u *models.User) bool { return field(u) > value }}, nil
+		case ">=":
+			return &userFilter{w.GTE(value), func(u *models.User) bool { return field(u) >= value }}, nil
+		case ":":
+			re := wildcardRegexp(value)
+			return &userFilter{
+				qm.Where(column+" LIKE ?", likePattern(value)),
+				func(u *models.User) bool { return re.MatchString(field(u)) },
+			}, nil
+		case "!:":
+			re := wildcardRegexp(value)
+			return &userFilter{
+				qm.Where(column+" NOT LIKE ?", likePattern(value)),
+				func(u *models.User) bool { return !re.MatchString(field(u)) },
+			}, nil
+		}
+
+		return nil, fmt.Errorf("operator %q is not supported", op)
+	}
+}
+
+// likePattern converts * wildcards to LIKE pattern
+func likePattern(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+	return strings.ReplaceAll(value, "*", "%")
+}
+
+// wildcardRegexp gives regexp matching the same strings as likePattern
+func wildcardRegexp(value string) *regexp.Regexp {
+	parts := strings.Split(value, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^(?s:" + strings.Join(parts, ".*") + ")$")
+}
+
+// parseFilter parses filter expression into compiled filter (nil for empty filter)
+func parseFilter(filter string, fields map[string]filterField) (*userFilter, error) {
+	if strings.TrimSpace(filter) == "" {
+		return nil, nil
+	}
+
+	tokens, err := lexFilter(filter)
+	if err != nil {
+		return nil, fmt.Errorf("filter: %v", err)
+	}
+
+	p := &filterParser{tokens: tokens}
+	node, err := p.expression()
+	if err != nil {
+		return nil, fmt.Errorf("filter: %v", err)
+	}
+	if t := p.peek(); t.kind != filterEOF {
+		return nil, fmt.Errorf("filter: unexpected %q at position %d", t.text, t.pos)
+	}
+
+	compiled, err := node.compile(fields)
+	if err != nil {
+		return nil, fmt.Errorf("filter: %v", err)
+	}
+
+	return compiled, nil
+}
+
+type filterTokenKind int
+
+const (
+	filterEOF filterTokenKind = iota
+	filterText
+	filterString
+	filterComparator
+	filterLParen
+	filterRParen
+	filterMinus
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string
+	pos  int
+}
+
+func lexFilter(s string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(s)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, filterToken{kind: filterLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{kind: filterRParen, text: ")", pos: i})
+			i++
+		case r == '"' || r == '\'':
+			start := i
+			var sb strings.Builder
+			for i++; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			i++
+			tokens = append(tokens, filterToken{kind: filterString, text: sb.String(), pos: start})
+		case strings.ContainsRune("=!<>:", r):
+			start := i
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' && r != '=' && r != ':' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("unexpected %q at position %d", op, start)
+			}
+			i += len(op)
+			tokens = append(tokens, filterToken{kind: filterComparator, text: op, pos: start})
+		case r == '-' && (i+1 >= len(runes) || !unicode.IsDigit(runes[i+1])):
+			tokens = append(tokens, filterToken{kind: filterMinus, text: "-", pos: i})
+			i++
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(`()"'=!<>:`, runes[i]) {
+				i++
+			}
+			tokens = append(tokens, filterToken{kind: filterText, text: string(runes[start:i]), pos: start})
+		}
+	}
+
+	return append(tokens, filterToken{kind: filterEOF, pos: len(runes)}), nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() filterToken {
+	t := p.tokens[p.pos]
+	if t.kind != filterEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *filterParser) isKeyword(word string) bool {
+	t := p.peek()
+	return t.kind == filterText && t.text == word
+}
+
+// expression : sequence {"AND" sequence}
+func (p *filterParser) expression() (filterNode, error) {
+	var nodes filterAnd
+	for {
+		node, err := p.sequence()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+
+		if !p.isKeyword("AND") {
+			break
+		}
+		p.next()
+	}
+
+	return nodes.simplify(), nil
+}
+
+// sequence : factor {factor}
+func (p *filterParser) sequence() (filterNode, error) {
+	var nodes filterAnd
+	for {
+		node, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+
+		t := p.peek()
+		if t.kind == filterEOF || t.kind == filterRParen || p.isKeyword("AND") {
+			break
+		}
+	}
+
+	return nodes.simplify(), nil
+}
+
+// factor : term {"OR" term}
+func (p *filterParser) factor() (filterNode, error) {
+	var nodes filterOr
+	for {
+		node, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+
+		if !p.isKeyword("OR") {
+			break
+		}
+		p.next()
+	}
+
+	return nodes.simplify(), nil
+}
+
+// term : ["NOT" | "-"] simple
+func (p *filterParser) term() (filterNode, error) {
+	if p.isKeyword("NOT") || p.peek().kind == filterMinus {
+		p.next()
+		node, err := p.simple()
+		if err != nil {
+			return nil, err
+		}
+		return node.negate(), nil
+	}
+
+	return p.simple()
+}
+
+// simple : restriction | "(" expression ")"
+func (p *filterParser) simple() (filterNode, error) {
+	t := p.next()
+	switch t.kind {
+	case filterLParen:
+		node, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		if r := p.next(); r.kind != filterRParen {
+			return nil, fmt.Errorf("expected \")\" at position %d", r.pos)
+		}
+		return node, nil
+
+	case filterText:
+		if t.text == "AND" || t.text == "OR" || t.text == "NOT" {
+			return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+		}
+
+		op := p.next()
+		if op.kind != filterComparator {
+			return nil, fmt.Errorf("expected comparator after %q at position %d", t.text, op.pos)
+		}
+
+		value := p.next()
+		if value.kind != filterText && value.kind != filterString {
+			return nil, fmt.Errorf("expected value after %q at position %d", op.text, value.pos)
+		}
+
+		return &filterRestriction{field: t.text, op: op.text, value: value.text}, nil
+
+	case filterEOF:
+		return nil, fmt.Errorf("unexpected end of filter")
+	}
+
+	return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+}
+
+type filterNode interface {
+	negate() filterNode
+	compile(fields map[string]filterField) (*userFilter, error)
+}
+
+type filterAnd []filterNode
+
+func (n filterAnd) simplify() filterNode {
+	if len(n) == 1 {
+		return n[0]
+	}
+	return n
+}
+
+func (n filterAnd) negate() filterNode {
+	negated := make(filterOr, len(n))
+	for i, node := range n {
+		negated[i] = node.negate()
+	}
+	return negated
+}
+
+func (n filterAnd) compile(fields map[string]filterField) (*userFilter, error) {
+	mods := make([]qm.QueryMod, len(n))
+	filters := make([]*userFilter, len(n))
+	for i, node := range n {
+		f, err := node.compile(fields)
+		if err != nil {
+			return nil, err
+		}
+		mods[i] = f.mod
+		filters[i] = f
+	}
+
+	match := func(u *models.User) bool {
+		for _, f := range filters {
+			if !f.match(u) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return &userFilter{qm.Expr(mods...), match}, nil
+}
+
+type filterOr []filterNode
+
+func (n filterOr) simplify() filterNode {
+	if len(n) == 1 {
+		return n[0]
+	}
+	return n
+}
+
+func (n filterOr) negate() filterNode {
+	negated := make(filterAnd, len(n))
+	for i, node := range n {
+		negated[i] = node.negate()
+	}
+	return negated
+}
+
+func (n filterOr) compile(fields map[string]filterField) (*userFilter, error) {
+	mods := make([]qm.QueryMod, len(n))
+	filters := make([]*userFilter, len(n))
+	for i, node := range n {
+		f, err := node.compile(fields)
+		if err != nil {
+			return nil, err
+		}
+		mods[i] = f.mod
+		if i > 0 {
+			mods[i] = qm.Or2(f.mod)
+		}
+		filters[i] = f
+	}
+
+	match := func(u *models.User) bool {
+		for _, f := range filters {
+			if f.match(u) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return &userFilter{qm.Expr(mods...), match}, nil
+}
+
+type filterRestriction struct {
+	field string
+	op    string
+	value string
+}
+
+var negatedComparators = map[string]string{
+	"=": "!=", "!=": "=",
+	"<": ">=", ">=": "<",
+	">": "<=", "<=": ">",
+	":": "!:", "!:": ":",
+}
+
+func (n *filterRestriction) negate() filterNode {
+	return &filterRestriction{field: n.field, op: negatedComparators[n.op], value: n.value}
+}
+
+func (n *filterRestriction) compile(fields map[string]filterField) (*userFilter, error) {
+	field, ok := fields[n.field]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q", n.field)
+	}
+
+	f, err := field(n.op, n.value)
+	if err != nil {
+		return nil, fmt.Errorf("field %q: %v", n.field, err)
+	}
+
+	return f, nil
+}
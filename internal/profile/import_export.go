@@ -0,0 +1,226 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/reviz0r/golang-layout/pkg/profile"
+)
+
+// maxImportProblems limits number of problems reported by import, counters are not limited
+const maxImportProblems = 1000
+
+// exportBatchSize limits number of users selected at once by export
+const exportBatchSize = 1000
+
+// UserImport is state of import which gets rows in batches
+type UserImport struct {
+	res *profile.ImportUsersResponse
+	// rows is number of rows received so far
+	rows int32
+	// seen holds lower case emails of previous valid rows
+	seen map[string]bool
+}
+
+// NewUserImport starts import, users are only checked in dry run
+func NewUserImport(dryRun bool) *UserImport {
+	return &UserImport{
+		res:  &profile.ImportUsersResponse{DryRun: dryRun},
+		seen: make(map[string]bool),
+	}
+}
+
+// Result gives summary of rows imported so far
+func (i *UserImport) Result() *profile.ImportUsersResponse {
+	return i.res
+}
+
+// importCandidate is valid row which is not duplicated by previous rows
+type importCandidate struct {
+	row   int32
+	email string
+	user  *profile.User
+}
+
+// ImportUsers .
+func (s *UserService) ImportUsers(stream profile.UserService_ImportUsersServer) error {
+	var imp *UserImport
+	for {
+		in, err := stream.Recv()
+		if err == io.EOF {
+			if imp == nil {
+				imp = NewUserImport(false)
+			}
+			return stream.SendAndClose(imp.Result())
+		}
+		if err != nil {
+			return err
+		}
+
+		if imp == nil {
+			imp = NewUserImport(in.GetDryRun())
+		}
+
+		if err := s.ImportBatch(stream.Context(), imp, in.GetRows()); err != nil {
+			return err
+		}
+	}
+}
+
+// ImportBatch validates rows and creates users of valid rows with new emails in one transaction
+func (s *UserService) ImportBatch(ctx context.Context, imp *UserImport, rows []*profile.ImportUsersRequest_Row) error {
+	var problems []*profile.ImportUsersResponse_Problem
+	var candidates []importCandidate
+	var emails []string
+
+	for _, row := range rows {
+		imp.rows++
+
+		user := &profile.User{Name: row.GetName(), Email: row.GetEmail()}
+		if err := user.Validate(); err != nil {
+			problems = append(problems, importProblem(imp.rows, profile.ImportUsersResponse_Problem_FAILED, err.Error()))
+			continue
+		}
+
+		email := strings.ToLower(user.GetEmail())
+		if imp.seen[email] {
+			problems = append(problems, importProblem(imp.rows, profile.ImportUsersResponse_Problem_SKIPPED,
+				"email is duplicated by previous row"))
+			continue
+		}
+		imp.seen[email] = true
+
+		candidates = append(candidates, importCandidate{row: imp.rows, email: email, user: user})
+		emails = append(emails, email)
+	}
+
+	var inserted int32
+	var skipped []*profile.ImportUsersResponse_Problem
+	if len(candidates) != 0 {
+		err := s.Users.WithinTx(ctx, func(ctx context.Context) error {
+			// transaction may be retried, so results are collected from scratch
+			inserted, skipped = 0, nil
+
+			existing, err := s.Users.ExistingEmails(ctx, emails)
+			if err != nil {
+				return err
+			}
+
+			for _, c := range candidates {
+				if existing[c.email] {
+					skipped = append(skipped, importProblem(c.row, profile.ImportUsersResponse_Problem_SKIPPED,
+						"user with this email already exists"))
+					continue
+				}
+
+				if !imp.res.GetDryRun() {
+					// user may be created concurrently after the check, savepoint keeps other rows of batch
+					err := s.Users.WithinTx(ctx, func(ctx context.Context) error {
+						return s.importUser(ctx, c.user)
+					})
+					var existsErr *UserExistsError
+					if errors.As(err, &existsErr) {
+						skipped = append(skipped, importProblem(c.row, profile.ImportUsersResponse_Problem_SKIPPED,
+							existsErr.Error()))
+						continue
+					}
+					if err != nil {
+						return err
+					}
+				}
+
+				inserted++
+			}
+
+			return nil
+		})
+		if err != nil {
+			return userError("UserService.ImportUsers", err)
+		}
+	}
+
+	problems = append(problems, skipped...)
+	sort.Slice(problems, func(i, j int) bool { return problems[i].GetRow() < problems[j].GetRow() })
+
+	imp.res.Inserted += inserted
+	for _, problem := range problems {
+		if problem.GetKind() == profile.ImportUsersResponse_Problem_SKIPPED {
+			imp.res.Skipped++
+		} else {
+			imp.res.Failed++
+		}
+
+		if len(imp.res.Problems) < maxImportProblems {
+			imp.res.Problems = append(imp.res.Problems, problem)
+		}
+	}
+
+	return nil
+}
+
+// importUser creates user of valid row and records its audit event
+func (s *UserService) importUser(ctx context.Context, in *profile.User) error {
+	user := userFromProto(in)
+	user.ID = 0
+	user.CreatedBy = actor(ctx)
+	user.UpdatedBy = user.CreatedBy
+
+	if err := s.Users.Create(ctx, user); err != nil {
+		return err
+	}
+
+	return writeAuditEvent(ctx, s.Users, "UserService.ImportUsers", user.ID, nil,
+		userColumnValues(user, userAuditColumns))
+}
+
+func importProblem(row int32, kind profile.ImportUsersResponse_Problem_Kind, message string) *profile.ImportUsersResponse_Problem {
+	return &profile.ImportUsersResponse_Problem{Row: row, Kind: kind, Message: message}
+}
+
+// ExportUsers .
+func (s *UserService) ExportUsers(in *profile.ExportUsersRequest, stream profile.UserService_ExportUsersServer) error {
+	return s.Export(stream.Context(), in, stream.Send)
+}
+
+// Export gives users selected by request to send ordered by id, page by page
+func (s *UserService) Export(ctx context.Context, in *profile.ExportUsersRequest, send func(*profile.User) error) error {
+	filter, err := parseFilter(in.GetFilter(), userFilterFields)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "UserService.ExportUsers: %s", err.Error())
+	}
+
+	order, err := parseOrderBy("")
+	if err != nil {
+		return status.Errorf(codes.Internal, "UserService.ExportUsers: %s", err.Error())
+	}
+
+	q := UserQuery{Filter: filter, ShowDeleted: in.GetShowDeleted(), OrderBy: order, Limit: exportBatchSize}
+
+	// export may be stale, so it can be read from replica
+	ctx = readContext(ctx)
+
+	for {
+		users, err := s.Users.List(ctx, q)
+		if err != nil {
+			return status.Errorf(codes.Internal, "UserService.ExportUsers: %s", err.Error())
+		}
+
+		for _, user := range users {
+			if err := send(userToProto(user)); err != nil {
+				return err
+			}
+		}
+
+		if len(users) < exportBatchSize {
+			return nil
+		}
+		q.After = []string{strconv.FormatInt(users[len(users)-1].ID, 10)}
+	}
+}
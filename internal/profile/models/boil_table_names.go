@@ -4,7 +4,9 @@
 package models
 
 var TableNames = struct {
-	Users string
+	UserAuditEvents string
+	Users           string
 }{
-	Users: "users",
+	UserAuditEvents: "user_audit_events",
+	Users:           "users",
 }
@@ -0,0 +1,179 @@
+package profile
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/reviz0r/golang-layout/internal/profile/models"
+)
+
+// sortField describes column which users can be ordered by
+type sortField struct {
+	column string
+	// value gives column value of user, it is stored in page token
+	value func(u *models.User) string
+	// compare compares column value of user with the value given by value func
+	compare func(u *models.User, key string) int
+}
+
+// userSortFields maps sortable proto fields of User to their columns
+var userSortFields = map[string]sortField{
+	"id": {
+		column: models.UserColumns.ID,
+		value:  func(u *models.User) string { return strconv.FormatInt(u.ID, 10) },
+		compare: func(u *models.User, key string) int {
+			id, _ := strconv.ParseInt(key, 10, 64)
+			switch {
+			case u.ID < id:
+				return -1
+			case u.ID > id:
+				return 1
+			}
+			return 0
+		},
+	},
+	"name": {
+		column: models.UserColumns.Name,
+		value:  func(u *models.User) string { return u.Name },
+		compare: func(u *models.User, key string) int {
+			return strings.Compare(u.Name, key)
+		},
+	},
+	"email": {
+		column: models.UserColumns.Email,
+		value:  func(u *models.User) string { return u.Email },
+		compare: func(u *models.User, key string) int {
+			return strings.Compare(u.Email, key)
+		},
+	},
+}
+
+type orderByField struct {
+	sortField
+	desc bool
+}
+
+// orderBy is ordered list of sort fields, always ended by unique id
+type orderBy []orderByField
+
+// parseOrderBy parses order_by expression like "name desc, id"
+func parseOrderBy(s string) (orderBy, error) {
+	var order orderBy
+	seen := make(map[string]bool)
+
+	if strings.TrimSpace(s) != "" {
+		for _, part := range strings.Split(s, ",") {
+			words := strings.Fields(part)
+			if len(words) == 0 || len(words) > 2 {
+				return nil, fmt.Errorf("order_by: invalid expression %q", strings.TrimSpace(part))
+			}
+
+			field, ok := userSortFields[words[0]]
+			if !ok {
+				return nil, fmt.Errorf("order_by: unknown field %q", words[0])
+			}
+			if seen[words[0]] {
+				return nil, fmt.Errorf("order_by: duplicate field %q", words[0])
+			}
+			seen[words[0]] = true
+
+			var desc bool
+			if len(words) == 2 {
+				switch strings.ToLower(words[1]) {
+				case "asc":
+				case "desc":
+					desc = true
+				default:
+					return nil, fmt.Errorf("order_by: unknown direction %q of field %q", words[1], words[0])
+				}
+			}
+
+			order = append(order, orderByField{sortField: field, desc: desc})
+		}
+	}
+
+	// id makes order stable for keyset pagination
+	if !seen["id"] {
+		order = append(order, orderByField{sortField: userSortFields["id"]})
+	}
+
+	return order, nil
+}
+
+// columns gives list of columns needed to build page token
+func (o orderBy) columns() []string {
+	columns := make([]string, len(o))
+	for i, f := range o {
+		columns[i] = f.column
+	}
+	return columns
+}
+
+// clause gives ORDER BY clause
+func (o orderBy) clause() string {
+	parts := make([]string, len(o))
+	for i, f := range o {
+		parts[i] = f.column
+		if f.desc {
+			parts[i] += " DESC"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// after gives where clause selecting rows placed after the row with given keys:
+// (a > ?) OR (a = ? AND b > ?) OR ...
+func (o orderBy) after(keys []string) (string, []interface{}) {
+	var (
+		terms []string
+		args  []interface{}
+	)
+
+	for i, f := range o {
+		var conds []string
+		var condArgs []interface{}
+
+		for _, prev := range o[:i] {
+			conds = append(conds, fmt.Sprintf(`"users".%q = ?`, prev.column))
+		}
+		for _, key := range keys[:i] {
+			condArgs = append(condArgs, key)
+		}
+
+		op := ">"
+		if f.desc {
+			op = "<"
+		}
+		conds = append(conds, fmt.Sprintf(`"users".%q %s ?`, f.column, op))
+		condArgs = append(condArgs, keys[i])
+
+		terms = append(terms, "("+strings.Join(conds, " AND ")+")")
+		args = append(args, condArgs...)
+	}
+
+	return "(" + strings.Join(terms, " OR ") + ")", args
+}
+
+// keys gives values of order fields of user, as they are stored in page token
+func (o orderBy) keys(u *models.User) []string {
+	keys := make([]string, len(o))
+	for i, f := range o {
+		keys[i] = f.value(u)
+	}
+	return keys
+}
+
+// compare gives sign of position of user relative to the row with given keys, it is used in memory
+func (o orderBy) compare(u *models.User, keys []string) int {
+	for i, f := range o {
+		c := f.compare(u, keys[i])
+		if f.desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
@@ -0,0 +1,91 @@
+package profile
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"strconv"
+
+	"github.com/reviz0r/golang-layout/internal/profile/models"
+)
+
+// pageToken is a keyset cursor which points right after the last returned user
+type pageToken struct {
+	ID int64 `json:"id"`
+	// Keys are values of order_by fields (except id) of the last returned user
+	Keys []string `json:"keys,omitempty"`
+	// Query is fingerprint of filter and order_by the token was issued for
+	Query uint32 `json:"query,omitempty"`
+}
+
+// newPageToken gives page token pointing after the given user
+func newPageToken(last *models.User, order orderBy, query uint32) pageToken {
+	t := pageToken{ID: last.ID, Query: query}
+	for _, f := range order {
+		if f.column != models.UserColumns.ID {
+			t.Keys = append(t.Keys, f.value(last))
+		}
+	}
+	return t
+}
+
+// keys gives values of all order fields (including id) stored in token
+func (t pageToken) keys(order orderBy) ([]string, error) {
+	keys := make([]string, 0, len(order))
+	rest := t.Keys
+	for _, f := range order {
+		if f.column == models.UserColumns.ID {
+			keys = append(keys, strconv.FormatInt(t.ID, 10))
+			continue
+		}
+		if len(rest) == 0 {
+			return nil, errors.New("invalid page token")
+		}
+		keys = append(keys, rest[0])
+		rest = rest[1:]
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("invalid page token")
+	}
+	return keys, nil
+}
+
+// queryFingerprint identifies filter and order_by of ReadAll request
+func queryFingerprint(filter, orderBy string) uint32 {
+	if filter == "" && orderBy == "" {
+		return 0
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(filter))
+	h.Write([]byte{0})
+	h.Write([]byte(orderBy))
+	return h.Sum32()
+}
+
+// encodePageToken gives opaque string representation of page token
+func encodePageToken(t pageToken) (string, error) {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodePageToken parses page token given by encodePageToken
+func decodePageToken(s string) (pageToken, error) {
+	var t pageToken
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return t, errors.New("invalid page token")
+	}
+
+	if err := json.Unmarshal(b, &t); err != nil {
+		return t, errors.New("invalid page token")
+	}
+
+	return t, nil
+}
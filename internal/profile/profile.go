@@ -2,46 +2,59 @@ package profile
 
 import (
 	"context"
-	"database/sql"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus/ctxlogrus"
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/log"
 	"github.com/sirupsen/logrus"
-	"github.com/volatiletech/sqlboiler/boil"
-	"github.com/volatiletech/sqlboiler/queries/qm"
 	"go.uber.org/fx"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	"github.com/reviz0r/golang-layout/internal/profile/models"
+	"github.com/reviz0r/golang-layout/pkg/db"
+	"github.com/reviz0r/golang-layout/pkg/fieldmask"
 	"github.com/reviz0r/golang-layout/pkg/profile"
 )
 
-// Module register user service in DI container
-var Module = fx.Invoke(RegisterUserService)
+// Module register user service and its postgres repository in DI container
+var Module = fx.Options(
+	fx.Provide(NewPostgresUserRepository),
+	fx.Invoke(RegisterUserService),
+)
 
 // UserService .
 type UserService struct {
-	*sql.DB
+	Users    UserRepository
+	Notifier db.Notifier
 }
 
 // RegisterUserService .
-func RegisterUserService(s *grpc.Server, db *sql.DB) {
-	profile.RegisterUserServiceServer(s, &UserService{DB: db})
+func RegisterUserService(s *grpc.Server, users UserRepository, notifier db.Notifier) {
+	profile.RegisterUserServiceServer(s, &UserService{Users: users, Notifier: notifier})
 }
 
 // Create .
 func (s *UserService) Create(ctx context.Context, in *profile.CreateRequest) (*profile.CreateResponse, error) {
 	user := userFromProto(in.GetUser())
 	user.ID = 0
+	user.CreatedBy = actor(ctx)
+	user.UpdatedBy = user.CreatedBy
+
+	err := s.Users.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.Users.Create(ctx, user); err != nil {
+			return err
+		}
 
-	err := user.Insert(ctx, s.DB, boil.Infer())
+		return writeAuditEvent(ctx, s.Users, "UserService.Create", user.ID, nil, userColumnValues(user, userAuditColumns))
+	})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "UserService.Create: %s", err.Error())
+		return nil, userError("UserService.Create", err)
 	}
 
 	return &profile.CreateResponse{Id: user.ID}, nil
@@ -49,15 +62,17 @@ func (s *UserService) Create(ctx context.Context, in *profile.CreateRequest) (*p
 
 // ReadAll .
 func (s *UserService) ReadAll(ctx context.Context, in *profile.ReadAllRequest) (*profile.ReadAllResponse, error) {
-	// add fields to span
-	span := opentracing.SpanFromContext(ctx).
-		// SetOperationName("operation ReadAll"). // it's not needed - grpc already set operation name
-		SetTag("blablabla", 123).      // I don't see it in span log
-		SetBaggageItem("ping", "pong") // this works fine
+	// add fields to span (there is no span if tracing interceptor is not installed)
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span.
+			// SetOperationName("operation ReadAll"). // it's not needed - grpc already set operation name
+			SetTag("blablabla", 123).      // I don't see it in span log
+			SetBaggageItem("ping", "pong") // this works fine
 
-	// just add some logging to tracer
-	span.LogFields(log.String("field", "some_value"), log.Int("some_int", 1))
-	span.LogKV("field_1", "value_1", "field_2", "value_2")
+		// just add some logging to tracer
+		span.LogFields(log.String("field", "some_value"), log.Int("some_int", 1))
+		span.LogKV("field_1", "value_1", "field_2", "value_2")
+	}
 
 	// add fields to request logger
 	ctxlogrus.AddFields(ctx, logrus.Fields{"my.custom.field": "some_value"})
@@ -65,6 +80,10 @@ func (s *UserService) ReadAll(ctx context.Context, in *profile.ReadAllRequest) (
 	// Extract a single request-scoped logrus.Logger and log messages.
 	l := ctxlogrus.Extract(ctx)
 
+	if in.GetPageToken() != "" && in.GetOffset() != 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "UserService.ReadAll: page_token can not be combined with offset")
+	}
+
 	var offset = in.GetOffset()
 	var limit = in.GetLimit()
 	if in.GetLimit() == 0 {
@@ -74,44 +93,124 @@ func (s *UserService) ReadAll(ctx context.Context, in *profile.ReadAllRequest) (
 		limit = 1000
 	}
 
-	l.Trace("selecting users")
-	users, err := models.Users(
-		qm.Select(in.GetFields().GetPaths()...),
-		qm.Limit(int(limit)),
-		qm.Offset(int(offset)),
-	).All(ctx, s.DB)
+	filter, err := parseFilter(in.GetFilter(), userFilterFields)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "UserService.ReadAll: %s", err.Error())
+		return nil, status.Errorf(codes.InvalidArgument, "UserService.ReadAll: %s", err.Error())
 	}
 
-	l.Trace("selecting total count")
-	total, err := models.Users().Count(ctx, s.DB)
+	order, err := parseOrderBy(in.GetOrderBy())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "UserService.ReadAll: %s", err.Error())
+	}
+
+	fields, err := userReadMask.Columns(in.GetFields().GetPaths())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "UserService.ReadAll: %s", err.Error())
+	}
+
+	query := queryFingerprint(in.GetFilter(), in.GetOrderBy())
+
+	// sort columns are always needed to build the next page token
+	if len(fields) != 0 {
+		for _, column := range order.columns() {
+			if !containsString(fields, column) {
+				fields = append(fields, column)
+			}
+		}
+	}
+
+	q := UserQuery{
+		Filter:      filter,
+		ShowDeleted: in.GetShowDeleted(),
+		Columns:     fields,
+		OrderBy:     order,
+		Offset:      int(offset),
+		// select one extra row to know if there is a next page
+		Limit: int(limit) + 1,
+	}
+
+	if in.GetPageToken() != "" {
+		token, err := decodePageToken(in.GetPageToken())
+		if err == nil && token.Query != query {
+			err = errors.New("page token does not match filter or order_by")
+		}
+		if err == nil {
+			q.After, err = token.keys(order)
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "UserService.ReadAll: %s", err.Error())
+		}
+	}
+
+	// page may be stale, so it can be read from replica
+	ctx = readContext(ctx)
+
+	l.Trace("selecting users")
+	users, err := s.Users.List(ctx, q)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "UserService.ReadAll: %s", err.Error())
 	}
 
+	var nextPageToken string
+	if len(users) > int(limit) {
+		users = users[:limit]
+
+		nextPageToken, err = encodePageToken(newPageToken(users[len(users)-1], order, query))
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "UserService.ReadAll: %s", err.Error())
+		}
+	}
+
+	var total int64
+	if in.GetIncludeTotal() {
+		l.Trace("selecting total count")
+		total, err = s.Users.Count(ctx, q)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "UserService.ReadAll: %s", err.Error())
+		}
+	}
+
 	l.Trace("marshal users to protobuf")
 	pbUsers := make([]*profile.User, len(users))
 	for i, user := range users {
 		pbUsers[i] = userToProto(user)
+		// drop sort columns which were selected only for the page token
+		fieldmask.Trim(pbUsers[i], in.GetFields().GetPaths())
 	}
 
 	l.Trace("return response")
-	return &profile.ReadAllResponse{Users: pbUsers, Limit: limit, Offset: offset, Total: int32(total)}, nil
+	return &profile.ReadAllResponse{
+		Users:         pbUsers,
+		Limit:         limit,
+		Offset:        offset,
+		Total:         int32(total),
+		NextPageToken: nextPageToken,
+	}, nil
 }
 
 // Read .
 func (s *UserService) Read(ctx context.Context, in *profile.ReadRequest) (*profile.ReadResponse, error) {
-	user, err := models.FindUser(ctx, s.DB, in.GetId(), in.GetFields().GetPaths()...)
-	if errors.Is(err, sql.ErrNoRows) {
-		return nil, status.Error(codes.NotFound, codes.NotFound.String())
+	fields, err := userReadMask.Columns(in.GetFields().GetPaths())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "UserService.Read: %s", err.Error())
+	}
+
+	// deleted_at is needed to hide deleted user
+	if len(fields) != 0 && !in.GetShowDeleted() && !containsString(fields, models.UserColumns.DeletedAt) {
+		fields = append(fields, models.UserColumns.DeletedAt)
 	}
 
+	user, err := s.Users.Get(readContext(ctx), in.GetId(), fields)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "UserService.Read: %s", err.Error())
+		return nil, userError("UserService.Read", err)
+	}
+
+	if user.DeletedAt.Valid && !in.GetShowDeleted() {
+		return nil, status.Error(codes.NotFound, codes.NotFound.String())
 	}
 
 	pbUser := userToProto(user)
+	fieldmask.Trim(pbUser, in.GetFields().GetPaths())
 
 	return &profile.ReadResponse{User: pbUser}, nil
 }
@@ -122,18 +221,32 @@ func (s *UserService) Update(ctx context.Context, in *profile.UpdateRequest) (*e
 		return nil, status.Errorf(codes.InvalidArgument, "UserService.Update: fields must be specified")
 	}
 
+	fields, err := userUpdateMask.Columns(in.GetFields().GetPaths())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "UserService.Update: %s", err.Error())
+	}
+
+	version, err := requestVersion(ctx, in.GetEtag())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "UserService.Update: %s", err.Error())
+	}
+
 	user := userFromProto(in.GetUser())
 	user.ID = in.GetId()
+	user.UpdatedAt = time.Now()
+	user.UpdatedBy = actor(ctx)
+
+	err = s.Users.WithinTx(ctx, func(ctx context.Context) error {
+		old, err := s.Users.Update(ctx, user, version, fields)
+		if err != nil {
+			return err
+		}
 
-	rows, err := user.Update(ctx, s.DB, boil.Whitelist(in.GetFields().GetPaths()...))
+		return writeAuditEvent(ctx, s.Users, "UserService.Update", user.ID,
+			userColumnValues(old, fields), userColumnValues(user, fields))
+	})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "UserService.Update: %s", err.Error())
-	}
-	if rows == 0 {
-		return nil, status.Error(codes.NotFound, codes.NotFound.String())
-	}
-	if rows > 1 {
-		return nil, status.Errorf(codes.Internal, "UserService.Update: expect updating 1 row, but updated %d rows", rows)
+		return nil, userError("UserService.Update", err)
 	}
 
 	return new(empty.Empty), nil
@@ -141,18 +254,213 @@ func (s *UserService) Update(ctx context.Context, in *profile.UpdateRequest) (*e
 
 // Delete .
 func (s *UserService) Delete(ctx context.Context, in *profile.DeleteRequest) (*empty.Empty, error) {
-	user := &models.User{ID: in.GetId()}
+	version, err := requestVersion(ctx, in.GetEtag())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "UserService.Delete: %s", err.Error())
+	}
+
+	err = s.Users.WithinTx(ctx, func(ctx context.Context) error {
+		// user is only marked as deleted, it is purged later
+		deletedAt := time.Now()
+		if err := s.Users.Delete(ctx, in.GetId(), version, deletedAt); err != nil {
+			return err
+		}
 
-	rows, err := user.Delete(ctx, s.DB)
+		return writeAuditEvent(ctx, s.Users, "UserService.Delete", in.GetId(),
+			models.M{models.UserColumns.DeletedAt: nil}, models.M{models.UserColumns.DeletedAt: deletedAt})
+	})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "UserService.Delete: %s", err.Error())
+		return nil, userError("UserService.Delete", err)
 	}
-	if rows == 0 {
-		return nil, status.Error(codes.NotFound, codes.NotFound.String())
+
+	return new(empty.Empty), nil
+}
+
+// UndeleteUser .
+func (s *UserService) UndeleteUser(ctx context.Context, in *profile.UndeleteUserRequest) (*empty.Empty, error) {
+	err := s.Users.WithinTx(ctx, func(ctx context.Context) error {
+		deletedAt, err := s.Users.Undelete(ctx, in.GetId())
+		if err != nil {
+			return err
+		}
+
+		return writeAuditEvent(ctx, s.Users, "UserService.UndeleteUser", in.GetId(),
+			models.M{models.UserColumns.DeletedAt: deletedAt}, models.M{models.UserColumns.DeletedAt: nil})
+	})
+	if err != nil {
+		return nil, userError("UserService.UndeleteUser", err)
 	}
-	if rows > 1 {
-		return nil, status.Errorf(codes.Internal, "UserService.Delete: expect deleting 1 row, but deleted %d rows", rows)
+
+	return new(empty.Empty), nil
+}
+
+// BatchCreateUsers .
+func (s *UserService) BatchCreateUsers(ctx context.Context, in *profile.BatchCreateUsersRequest) (*profile.BatchCreateUsersResponse, error) {
+	createdBy := actor(ctx)
+	ids := make([]int64, len(in.GetUsers()))
+
+	err := s.Users.WithinTx(ctx, func(ctx context.Context) error {
+		for i, pbUser := range in.GetUsers() {
+			user := userFromProto(pbUser)
+			user.ID = 0
+			user.CreatedBy = createdBy
+			user.UpdatedBy = createdBy
+
+			err := s.Users.Create(ctx, user)
+			if err := alreadyExistsError("UserService.BatchCreateUsers", fmt.Sprintf("users[%d]", i), err); err != nil {
+				return err
+			}
+			if err != nil {
+				return status.Errorf(codes.Internal, "UserService.BatchCreateUsers: users[%d]: %s", i, err.Error())
+			}
+			ids[i] = user.ID
+
+			err = writeAuditEvent(ctx, s.Users, "UserService.BatchCreateUsers", user.ID, nil, userColumnValues(user, userAuditColumns))
+			if err != nil {
+				return status.Errorf(codes.Internal, "UserService.BatchCreateUsers: users[%d]: %s", i, err.Error())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, userError("UserService.BatchCreateUsers", err)
+	}
+
+	return &profile.BatchCreateUsersResponse{Ids: ids}, nil
+}
+
+// BatchGetUsers .
+func (s *UserService) BatchGetUsers(ctx context.Context, in *profile.BatchGetUsersRequest) (*profile.BatchGetUsersResponse, error) {
+	if err := userReadMask.Validate(in.GetFields().GetPaths()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "UserService.BatchGetUsers: %s", err.Error())
+	}
+
+	users, err := s.Users.BatchGet(ctx, uniqueIDs(in.GetIds()))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "UserService.BatchGetUsers: %s", err.Error())
+	}
+
+	byID := make(map[int64]*models.User, len(users))
+	for _, user := range users {
+		if !user.DeletedAt.Valid {
+			byID[user.ID] = user
+		}
+	}
+
+	// users are returned in order of request
+	pbUsers := make([]*profile.User, len(in.GetIds()))
+	for i, id := range in.GetIds() {
+		user, ok := byID[id]
+		if !ok {
+			return nil, status.Error(codes.NotFound, codes.NotFound.String())
+		}
+
+		pbUsers[i] = userToProto(user)
+		fieldmask.Trim(pbUsers[i], in.GetFields().GetPaths())
+	}
+
+	return &profile.BatchGetUsersResponse{Users: pbUsers}, nil
+}
+
+// BatchDeleteUsers .
+func (s *UserService) BatchDeleteUsers(ctx context.Context, in *profile.BatchDeleteUsersRequest) (*empty.Empty, error) {
+	ids := uniqueIDs(in.GetIds())
+
+	err := s.Users.WithinTx(ctx, func(ctx context.Context) error {
+		// users are only marked as deleted, they are purged later
+		deletedAt := time.Now()
+		if err := s.Users.BatchDelete(ctx, ids, deletedAt); err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			err := writeAuditEvent(ctx, s.Users, "UserService.BatchDeleteUsers", id,
+				models.M{models.UserColumns.DeletedAt: nil}, models.M{models.UserColumns.DeletedAt: deletedAt})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, userError("UserService.BatchDeleteUsers", err)
 	}
 
 	return new(empty.Empty), nil
 }
+
+// ListUserAuditEvents .
+func (s *UserService) ListUserAuditEvents(ctx context.Context, in *profile.ListUserAuditEventsRequest) (*profile.ListUserAuditEventsResponse, error) {
+	var pageSize = in.GetPageSize()
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+	if pageSize > 1000 {
+		pageSize = 1000
+	}
+
+	q := AuditEventQuery{
+		UserID: in.GetId(),
+		// select one extra row to know if there is a next page
+		Limit: int(pageSize) + 1,
+	}
+
+	if in.GetPageToken() != "" {
+		token, err := decodePageToken(in.GetPageToken())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "UserService.ListUserAuditEvents: %s", err.Error())
+		}
+		q.AfterID = token.ID
+	}
+
+	events, err := s.Users.ListAuditEvents(ctx, q)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "UserService.ListUserAuditEvents: %s", err.Error())
+	}
+
+	var nextPageToken string
+	if len(events) > int(pageSize) {
+		events = events[:pageSize]
+
+		nextPageToken, err = encodePageToken(pageToken{ID: events[len(events)-1].ID})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "UserService.ListUserAuditEvents: %s", err.Error())
+		}
+	}
+
+	pbEvents := make([]*profile.UserAuditEvent, len(events))
+	for i, event := range events {
+		pbEvents[i], err = auditEventToProto(event)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "UserService.ListUserAuditEvents: %s", err.Error())
+		}
+	}
+
+	return &profile.ListUserAuditEventsResponse{
+		Events:        pbEvents,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	return unique
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
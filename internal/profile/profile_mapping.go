@@ -1,6 +1,11 @@
 package profile
 
 import (
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+
 	"github.com/reviz0r/golang-layout/internal/profile/models"
 	"github.com/reviz0r/golang-layout/pkg/profile"
 )
@@ -23,8 +28,43 @@ func userToProto(in *models.User) *profile.User {
 	}
 
 	return &profile.User{
-		Id:    in.ID,
-		Name:  in.Name,
-		Email: in.Email,
+		Id:         in.ID,
+		Name:       in.Name,
+		Email:      in.Email,
+		Etag:       formatETag(in.Version),
+		DeleteTime: timeToProto(in.DeletedAt.Time),
+		CreateTime: timeToProto(in.CreatedAt),
+		UpdateTime: timeToProto(in.UpdatedAt),
+		CreatedBy:  in.CreatedBy.String,
+		UpdatedBy:  in.UpdatedBy.String,
+	}
+}
+
+// timeToProto gives nil for zero time, e.g. for null or not selected column
+func timeToProto(in time.Time) *timestamp.Timestamp {
+	if in.IsZero() {
+		return nil
+	}
+
+	ts, err := ptypes.TimestampProto(in)
+	if err != nil {
+		return nil
 	}
+
+	return ts
+}
+
+// userColumnValues gives values of user columns for UpdateAll
+func userColumnValues(in *models.User, columns []string) models.M {
+	values := make(models.M, len(columns))
+	for _, column := range columns {
+		switch column {
+		case models.UserColumns.Name:
+			values[column] = in.Name
+		case models.UserColumns.Email:
+			values[column] = in.Email
+		}
+	}
+
+	return values
 }
@@ -3,22 +3,38 @@ package profile_test
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
+	"io"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
 	"go.uber.org/fx"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/genproto/protobuf/field_mask"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
+	grpcMiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
+
 	internal "github.com/reviz0r/golang-layout/internal/profile"
+	"github.com/reviz0r/golang-layout/pkg/auth"
+	pkgdb "github.com/reviz0r/golang-layout/pkg/db"
 	"github.com/reviz0r/golang-layout/pkg/mockdb"
 	"github.com/reviz0r/golang-layout/pkg/mockserver"
 	pkg "github.com/reviz0r/golang-layout/pkg/profile"
+	"github.com/reviz0r/golang-layout/pkg/server"
 
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 )
 
@@ -27,11 +43,73 @@ func TestProfile(t *testing.T) {
 	RunSpecs(t, "Profile Suite")
 }
 
+// testSubjectMetadata authenticates test client as the given subject
+const testSubjectMetadata = "x-test-subject"
+
+// jsonArg matches sql argument holding JSON equal to the given one
+type jsonArg string
+
+func (a jsonArg) Match(v driver.Value) bool {
+	var b []byte
+	switch v := v.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return false
+	}
+
+	var actual, expected interface{}
+	if json.Unmarshal(b, &actual) != nil || json.Unmarshal([]byte(a), &expected) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(actual, expected)
+}
+
+// eventArg matches outbox payload equal to the given one ignoring its createTime
+type eventArg string
+
+func (a eventArg) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	if !ok {
+		return false
+	}
+
+	var event map[string]interface{}
+	if json.Unmarshal([]byte(s), &event) != nil {
+		return false
+	}
+	delete(event, "createTime")
+
+	b, err := json.Marshal(event)
+	return err == nil && jsonArg(a).Match(b)
+}
+
+// testServerOption authenticates calls by subject metadata and reports all errors of invalid requests
+// as the server does
+func testServerOption() grpc.ServerOption {
+	authenticate := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get(testSubjectMetadata)) > 0 {
+			ctx = auth.NewContext(ctx, &auth.Principal{Subject: md.Get(testSubjectMetadata)[0]})
+		}
+		return handler(ctx, req)
+	}
+
+	return grpc.UnaryInterceptor(grpcMiddleware.ChainUnaryServer(
+		authenticate,
+		server.ValidateAllUnaryServerInterceptor(),
+	))
+}
+
 var _ = Describe("Profile", func() {
 	var (
 		// Mock DB
-		db   *sql.DB
-		mock sqlmock.Sqlmock
+		db       *sql.DB
+		mock     sqlmock.Sqlmock
+		notifier *mockdb.Notifier
+		users    internal.UserRepository
 
 		// Client fake connection
 		conn   *grpc.ClientConn
@@ -45,8 +123,12 @@ var _ = Describe("Profile", func() {
 		mockserver.Module,
 		internal.Module,
 
+		fx.Provide(fx.Annotated{Group: "grpc_server_options", Target: testServerOption}),
+
 		fx.Populate(&db),
 		fx.Populate(&mock),
+		fx.Populate(&notifier),
+		fx.Populate(&users),
 		fx.Populate(&conn),
 	)
 
@@ -57,12 +139,27 @@ var _ = Describe("Profile", func() {
 		Expect(mock.ExpectationsWereMet()).NotTo(HaveOccurred())
 	})
 
+	qAudit := `^INSERT INTO "user_audit_events" (.+) VALUES (.+) RETURNING "id"$`
+	auditRows := func(id int64) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id"}).AddRow(id)
+	}
+	qOutbox := `^INSERT INTO "outbox" \("topic", "key", "payload"\) VALUES \(\$1, \$2, \$3\)$`
+	outboxResult := sqlmock.NewResult(0, 1)
+
 	Describe("Create", func() {
-		q := `^INSERT INTO "users" (.+) VALUES (.+) RETURNING "id"$`
+		q := `^INSERT INTO "users" (.+) VALUES (.+) RETURNING "id","version"$`
 
 		It("can create user", func() {
-			rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
-			mock.ExpectQuery(q).WithArgs("user", "user@example.com").WillReturnRows(rows)
+			rows := sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 1)
+			mock.ExpectBegin()
+			mock.ExpectQuery(q).WithArgs("user", "user@example.com", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil).WillReturnRows(rows)
+			mock.ExpectQuery(qAudit).
+				WithArgs(1, "UserService.Create", nil, nil, jsonArg(`{"name": "user", "email": "user@example.com"}`), nil, nil, sqlmock.AnyArg()).
+				WillReturnRows(auditRows(1))
+			mock.ExpectExec(qOutbox).
+				WithArgs("users", "1", eventArg(`{"id": "1", "userId": "1", "method": "UserService.Create", "after": {"name": "user", "email": "user@example.com"}}`)).
+				WillReturnResult(outboxResult)
+			mock.ExpectCommit()
 
 			res, err := client.Create(context.Background(),
 				&pkg.CreateRequest{User: &pkg.User{Name: "user", Email: "user@example.com"}})
@@ -72,8 +169,45 @@ var _ = Describe("Profile", func() {
 			Expect(res.GetId()).To(Equal(int64(1)))
 		})
 
+		It("records authenticated caller as creator", func() {
+			rows := sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 1)
+			mock.ExpectBegin()
+			mock.ExpectQuery(q).WithArgs("user", "user@example.com", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), "admin", "admin").WillReturnRows(rows)
+			mock.ExpectQuery(qAudit).
+				WithArgs(1, "UserService.Create", "admin", nil, sqlmock.AnyArg(), nil, nil, sqlmock.AnyArg()).
+				WillReturnRows(auditRows(1))
+			mock.ExpectExec(qOutbox).WithArgs("users", "1", sqlmock.AnyArg()).WillReturnResult(outboxResult)
+			mock.ExpectCommit()
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), testSubjectMetadata, "admin")
+			res, err := client.Create(ctx,
+				&pkg.CreateRequest{User: &pkg.User{Name: "user", Email: "user@example.com"}})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.GetId()).To(Equal(int64(1)))
+		})
+
+		It("records request id in audit log", func() {
+			rows := sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 1)
+			mock.ExpectBegin()
+			mock.ExpectQuery(q).WithArgs("user", "user@example.com", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil).WillReturnRows(rows)
+			mock.ExpectQuery(qAudit).
+				WithArgs(1, "UserService.Create", nil, nil, sqlmock.AnyArg(), "req-1", nil, sqlmock.AnyArg()).
+				WillReturnRows(auditRows(1))
+			mock.ExpectExec(qOutbox).WithArgs("users", "1", sqlmock.AnyArg()).WillReturnResult(outboxResult)
+			mock.ExpectCommit()
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-1")
+			_, err := client.Create(ctx,
+				&pkg.CreateRequest{User: &pkg.User{Name: "user", Email: "user@example.com"}})
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+
 		It("gives Internal error if cannot create user", func() {
-			mock.ExpectQuery(q).WithArgs("user", "user@example.com").WillReturnError(errors.New("some error"))
+			mock.ExpectBegin()
+			mock.ExpectQuery(q).WithArgs("user", "user@example.com", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil).WillReturnError(errors.New("some error"))
+			mock.ExpectRollback()
 
 			res, err := client.Create(context.Background(),
 				&pkg.CreateRequest{User: &pkg.User{Name: "user", Email: "user@example.com"}})
@@ -86,19 +220,83 @@ var _ = Describe("Profile", func() {
 			Expect(grpcStatus.Code()).To(Equal(codes.Internal))
 			Expect(grpcStatus.Message()).To(Equal("UserService.Create: models: unable to insert into users: some error"))
 		})
+
+		It("rolls back if cannot write outbox message", func() {
+			rows := sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 1)
+			mock.ExpectBegin()
+			mock.ExpectQuery(q).WithArgs("user", "user@example.com", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil).WillReturnRows(rows)
+			mock.ExpectQuery(qAudit).WillReturnRows(auditRows(1))
+			mock.ExpectExec(qOutbox).WillReturnError(errors.New("some error"))
+			mock.ExpectRollback()
+
+			res, err := client.Create(context.Background(),
+				&pkg.CreateRequest{User: &pkg.User{Name: "user", Email: "user@example.com"}})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.Internal))
+			Expect(grpcStatus.Message()).To(Equal("UserService.Create: some error"))
+		})
+
+		It("rolls back if cannot write audit event", func() {
+			rows := sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 1)
+			mock.ExpectBegin()
+			mock.ExpectQuery(q).WithArgs("user", "user@example.com", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil).WillReturnRows(rows)
+			mock.ExpectQuery(qAudit).WillReturnError(errors.New("some error"))
+			mock.ExpectRollback()
+
+			res, err := client.Create(context.Background(),
+				&pkg.CreateRequest{User: &pkg.User{Name: "user", Email: "user@example.com"}})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.Internal))
+			Expect(grpcStatus.Message()).To(Equal("UserService.Create: models: unable to insert into user_audit_events: some error"))
+		})
+
+		It("gives AlreadyExists error if email is taken", func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(q).WithArgs("user", "User@example.com", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil).
+				WillReturnError(&pq.Error{Code: "23505", Constraint: "users_email_lower_key"})
+			mock.ExpectRollback()
+
+			res, err := client.Create(context.Background(),
+				&pkg.CreateRequest{User: &pkg.User{Name: "user", Email: "User@example.com"}})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.AlreadyExists))
+			Expect(grpcStatus.Message()).To(Equal("UserService.Create: user with this email already exists"))
+			Expect(grpcStatus.Details()).To(HaveLen(1))
+
+			badRequest, ok := grpcStatus.Details()[0].(*errdetails.BadRequest)
+			Expect(ok).To(BeTrue())
+			Expect(badRequest.GetFieldViolations()).To(HaveLen(1))
+			Expect(badRequest.GetFieldViolations()[0].GetField()).To(Equal("email"))
+		})
 	})
 
 	Describe("ReadAll", func() {
-		qCount := `^SELECT COUNT(.+) FROM "users";$`
+		qCount := `^SELECT COUNT(.+) FROM "users" WHERE \("users"."deleted_at" is null\);$`
+		qNextPage := `^SELECT (.+) FROM "users" WHERE \("users"."deleted_at" is null\) AND \("users"."id" > \$1\) ORDER BY id LIMIT 101;$`
 
 		It("can get all users", func() {
 			rows := sqlmock.NewRows([]string{"id", "name", "email"}).
 				AddRow(1, "user", "user@example.com")
 			countRows := sqlmock.NewRows([]string{"count"}).AddRow(1)
-			mock.ExpectQuery(`^SELECT (.+) FROM "users" LIMIT 100;$`).WillReturnRows(rows)
+			mock.ExpectQuery(`^SELECT (.+) FROM "users" WHERE \("users"."deleted_at" is null\) ORDER BY id LIMIT 101;$`).WillReturnRows(rows)
 			mock.ExpectQuery(qCount).WillReturnRows(countRows)
 
-			res, err := client.ReadAll(context.Background(), &pkg.ReadAllRequest{})
+			res, err := client.ReadAll(context.Background(), &pkg.ReadAllRequest{IncludeTotal: true})
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(res).NotTo(BeNil())
@@ -110,10 +308,10 @@ var _ = Describe("Profile", func() {
 		})
 
 		It("gives Internal error if cannot get all users", func() {
-			mock.ExpectQuery(`^SELECT (.+) FROM "users" LIMIT 100;$`).
+			mock.ExpectQuery(`^SELECT (.+) FROM "users" WHERE \("users"."deleted_at" is null\) ORDER BY id LIMIT 101;$`).
 				WillReturnError(errors.New("some error"))
 
-			res, err := client.ReadAll(context.Background(), &pkg.ReadAllRequest{})
+			res, err := client.ReadAll(context.Background(), &pkg.ReadAllRequest{IncludeTotal: true})
 
 			Expect(err).To(HaveOccurred())
 			Expect(res).To(BeNil())
@@ -127,10 +325,10 @@ var _ = Describe("Profile", func() {
 		It("gives Internal error if cannot get users count", func() {
 			rows := sqlmock.NewRows([]string{"id", "name", "email"}).
 				AddRow(1, "user", "user@example.com")
-			mock.ExpectQuery(`^SELECT (.+) FROM "users" LIMIT 100;$`).WillReturnRows(rows)
+			mock.ExpectQuery(`^SELECT (.+) FROM "users" WHERE \("users"."deleted_at" is null\) ORDER BY id LIMIT 101;$`).WillReturnRows(rows)
 			mock.ExpectQuery(qCount).WillReturnError(errors.New("some error"))
 
-			res, err := client.ReadAll(context.Background(), &pkg.ReadAllRequest{})
+			res, err := client.ReadAll(context.Background(), &pkg.ReadAllRequest{IncludeTotal: true})
 
 			Expect(err).To(HaveOccurred())
 			Expect(res).To(BeNil())
@@ -145,10 +343,10 @@ var _ = Describe("Profile", func() {
 			rows := sqlmock.NewRows([]string{"id", "name", "email"}).
 				AddRow(1, "user", "user@example.com")
 			countRows := sqlmock.NewRows([]string{"count"}).AddRow(1)
-			mock.ExpectQuery(`^SELECT (.+) FROM "users" LIMIT 10;$`).WillReturnRows(rows)
+			mock.ExpectQuery(`^SELECT (.+) FROM "users" WHERE \("users"."deleted_at" is null\) ORDER BY id LIMIT 11;$`).WillReturnRows(rows)
 			mock.ExpectQuery(qCount).WillReturnRows(countRows)
 
-			res, err := client.ReadAll(context.Background(), &pkg.ReadAllRequest{Limit: 10})
+			res, err := client.ReadAll(context.Background(), &pkg.ReadAllRequest{Limit: 10, IncludeTotal: true})
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(res).NotTo(BeNil())
@@ -163,10 +361,10 @@ var _ = Describe("Profile", func() {
 			rows := sqlmock.NewRows([]string{"id", "name", "email"}).
 				AddRow(1, "user", "user@example.com")
 			countRows := sqlmock.NewRows([]string{"count"}).AddRow(1)
-			mock.ExpectQuery(`^SELECT (.+) FROM "users" LIMIT 1000;$`).WillReturnRows(rows)
+			mock.ExpectQuery(`^SELECT (.+) FROM "users" WHERE \("users"."deleted_at" is null\) ORDER BY id LIMIT 1001;$`).WillReturnRows(rows)
 			mock.ExpectQuery(qCount).WillReturnRows(countRows)
 
-			res, err := client.ReadAll(context.Background(), &pkg.ReadAllRequest{Limit: 1000})
+			res, err := client.ReadAll(context.Background(), &pkg.ReadAllRequest{Limit: 1000, IncludeTotal: true})
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(res).NotTo(BeNil())
@@ -181,10 +379,10 @@ var _ = Describe("Profile", func() {
 			rows := sqlmock.NewRows([]string{"id", "name", "email"}).
 				AddRow(1, "user", "user@example.com")
 			countRows := sqlmock.NewRows([]string{"count"}).AddRow(1)
-			mock.ExpectQuery(`^SELECT (.+) FROM "users" LIMIT 1000;$`).WillReturnRows(rows)
+			mock.ExpectQuery(`^SELECT (.+) FROM "users" WHERE \("users"."deleted_at" is null\) ORDER BY id LIMIT 1001;$`).WillReturnRows(rows)
 			mock.ExpectQuery(qCount).WillReturnRows(countRows)
 
-			res, err := client.ReadAll(context.Background(), &pkg.ReadAllRequest{Limit: 10000})
+			res, err := client.ReadAll(context.Background(), &pkg.ReadAllRequest{Limit: 10000, IncludeTotal: true})
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(res).NotTo(BeNil())
@@ -194,6 +392,253 @@ var _ = Describe("Profile", func() {
 			Expect(res.GetOffset()).To(Equal(int32(0)))
 			Expect(res.GetTotal()).To(Equal(int32(1)))
 		})
+
+		It("does not count users if total is not requested", func() {
+			rows := sqlmock.NewRows([]string{"id", "name", "email"}).
+				AddRow(1, "user", "user@example.com")
+			mock.ExpectQuery(`^SELECT (.+) FROM "users" WHERE \("users"."deleted_at" is null\) ORDER BY id LIMIT 101;$`).WillReturnRows(rows)
+
+			res, err := client.ReadAll(context.Background(), &pkg.ReadAllRequest{})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).NotTo(BeNil())
+			Expect(res.GetUsers()).To(HaveLen(1))
+			Expect(res.GetTotal()).To(Equal(int32(0)))
+			Expect(res.GetNextPageToken()).To(BeEmpty())
+		})
+
+		It("gives next page token if there are more users", func() {
+			rows := sqlmock.NewRows([]string{"id", "name", "email"}).
+				AddRow(1, "user1", "user1@example.com").
+				AddRow(2, "user2", "user2@example.com").
+				AddRow(3, "user3", "user3@example.com")
+			mock.ExpectQuery(`^SELECT (.+) FROM "users" WHERE \("users"."deleted_at" is null\) ORDER BY id LIMIT 3;$`).WillReturnRows(rows)
+
+			res, err := client.ReadAll(context.Background(), &pkg.ReadAllRequest{Limit: 2})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).NotTo(BeNil())
+			Expect(res.GetUsers()).To(Equal([]*pkg.User{
+				{Id: 1, Name: "user1", Email: "user1@example.com"},
+				{Id: 2, Name: "user2", Email: "user2@example.com"},
+			}))
+			Expect(res.GetNextPageToken()).NotTo(BeEmpty())
+
+			rows = sqlmock.NewRows([]string{"id", "name", "email"}).
+				AddRow(3, "user3", "user3@example.com")
+			mock.ExpectQuery(`^SELECT (.+) FROM "users" WHERE \("users"."deleted_at" is null\) AND \("users"."id" > \$1\) ORDER BY id LIMIT 3;$`).
+				WithArgs(2).WillReturnRows(rows)
+
+			res, err = client.ReadAll(context.Background(),
+				&pkg.ReadAllRequest{Limit: 2, PageToken: res.GetNextPageToken()})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).NotTo(BeNil())
+			Expect(res.GetUsers()).To(Equal([]*pkg.User{{Id: 3, Name: "user3", Email: "user3@example.com"}}))
+			Expect(res.GetNextPageToken()).To(BeEmpty())
+		})
+
+		It("gives Internal error if cannot get next page", func() {
+			mock.ExpectQuery(qNextPage).WithArgs(2).WillReturnError(errors.New("some error"))
+
+			res, err := client.ReadAll(context.Background(), &pkg.ReadAllRequest{PageToken: "eyJpZCI6Mn0"})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.Internal))
+		})
+
+		It("gives InvalidArgument error if page token is malformed", func() {
+			res, err := client.ReadAll(context.Background(), &pkg.ReadAllRequest{PageToken: "not a token"})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.InvalidArgument))
+			Expect(grpcStatus.Message()).To(Equal("UserService.ReadAll: invalid page token"))
+		})
+
+		It("gives InvalidArgument error if page token is combined with offset", func() {
+			res, err := client.ReadAll(context.Background(), &pkg.ReadAllRequest{PageToken: "eyJpZCI6Mn0", Offset: 10})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.InvalidArgument))
+			Expect(grpcStatus.Message()).To(Equal("UserService.ReadAll: page_token can not be combined with offset"))
+		})
+
+		It("can get deleted users too", func() {
+			rows := sqlmock.NewRows([]string{"id", "name", "email", "deleted_at"}).
+				AddRow(1, "user", "user@example.com", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+			mock.ExpectQuery(`^SELECT (.+) FROM "users" ORDER BY id LIMIT 101;$`).WillReturnRows(rows)
+
+			res, err := client.ReadAll(context.Background(), &pkg.ReadAllRequest{ShowDeleted: true})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).NotTo(BeNil())
+			Expect(res.GetUsers()).To(Equal([]*pkg.User{{Id: 1, Name: "user", Email: "user@example.com",
+				DeleteTime: &timestamp.Timestamp{Seconds: 1577934245}}}))
+		})
+
+		It("gives only masked fields", func() {
+			rows := sqlmock.NewRows([]string{"email", "id"}).
+				AddRow("user@example.com", 1)
+			mock.ExpectQuery(`^SELECT "email", "id" FROM "users" WHERE \("users"."deleted_at" is null\) ORDER BY id LIMIT 101;$`).WillReturnRows(rows)
+
+			res, err := client.ReadAll(context.Background(),
+				&pkg.ReadAllRequest{Fields: &field_mask.FieldMask{Paths: []string{"email"}}})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).NotTo(BeNil())
+			Expect(res.GetUsers()).To(Equal([]*pkg.User{{Email: "user@example.com"}}))
+		})
+
+		It("gives InvalidArgument error if field mask has unknown path", func() {
+			res, err := client.ReadAll(context.Background(),
+				&pkg.ReadAllRequest{Fields: &field_mask.FieldMask{Paths: []string{"name", "password"}}})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.InvalidArgument))
+			Expect(grpcStatus.Message()).To(Equal(`UserService.ReadAll: unknown field mask path "password"`))
+		})
+	})
+
+	Describe("ReadAll with filter and order_by", func() {
+		It("can filter users", func() {
+			rows := sqlmock.NewRows([]string{"id", "name", "email"}).
+				AddRow(1, "user", "user@corp.com")
+			mock.ExpectQuery(`^SELECT (.+) FROM "users" WHERE "users"."deleted_at" is null AND \("users"."email" LIKE \$1 AND "users"."name" != \$2\) ORDER BY id LIMIT 101;$`).
+				WithArgs("%@corp.com", "bot").WillReturnRows(rows)
+
+			res, err := client.ReadAll(context.Background(),
+				&pkg.ReadAllRequest{Filter: `email:"*@corp.com" AND name!="bot"`})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).NotTo(BeNil())
+			Expect(res.GetUsers()).To(Equal([]*pkg.User{{Id: 1, Name: "user", Email: "user@corp.com"}}))
+		})
+
+		It("binds OR tighter than AND", func() {
+			rows := sqlmock.NewRows([]string{"id", "name", "email"})
+			mock.ExpectQuery(`^SELECT (.+) FROM "users" WHERE "users"."deleted_at" is null AND \("users"."id" > \$1 AND \("users"."name" = \$2 OR "users"."name" = \$3\)\) ORDER BY id LIMIT 101;$`).
+				WithArgs(10, "alice", "bob").WillReturnRows(rows)
+
+			res, err := client.ReadAll(context.Background(),
+				&pkg.ReadAllRequest{Filter: `id>10 AND name="alice" OR name="bob"`})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).NotTo(BeNil())
+		})
+
+		It("can negate expressions", func() {
+			rows := sqlmock.NewRows([]string{"id", "name", "email"})
+			mock.ExpectQuery(`^SELECT (.+) FROM "users" WHERE "users"."deleted_at" is null AND \("users"."name" != \$1 AND "users"."email" NOT LIKE \$2\) ORDER BY id LIMIT 101;$`).
+				WithArgs("alice", "%@example.com").WillReturnRows(rows)
+
+			res, err := client.ReadAll(context.Background(),
+				&pkg.ReadAllRequest{Filter: `NOT (name="alice" OR email:"*@example.com")`})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).NotTo(BeNil())
+		})
+
+		It("counts filtered users", func() {
+			rows := sqlmock.NewRows([]string{"id", "name", "email"})
+			countRows := sqlmock.NewRows([]string{"count"}).AddRow(0)
+			mock.ExpectQuery(`^SELECT (.+) FROM "users" WHERE \("users"."deleted_at" is null\) AND \("users"."name" = \$1\) ORDER BY id LIMIT 101;$`).
+				WithArgs("bot").WillReturnRows(rows)
+			mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM "users" WHERE \("users"."deleted_at" is null\) AND \("users"."name" = \$1\);$`).
+				WithArgs("bot").WillReturnRows(countRows)
+
+			res, err := client.ReadAll(context.Background(),
+				&pkg.ReadAllRequest{Filter: `name="bot"`, IncludeTotal: true})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).NotTo(BeNil())
+			Expect(res.GetTotal()).To(Equal(int32(0)))
+		})
+
+		It("can order users", func() {
+			rows := sqlmock.NewRows([]string{"id", "name", "email"}).
+				AddRow(2, "bob", "bob@example.com").
+				AddRow(1, "alice", "alice@example.com")
+			mock.ExpectQuery(`^SELECT (.+) FROM "users" WHERE \("users"."deleted_at" is null\) ORDER BY name DESC, id LIMIT 2;$`).WillReturnRows(rows)
+
+			res, err := client.ReadAll(context.Background(), &pkg.ReadAllRequest{OrderBy: "name desc", Limit: 1})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).NotTo(BeNil())
+			Expect(res.GetUsers()).To(Equal([]*pkg.User{{Id: 2, Name: "bob", Email: "bob@example.com"}}))
+			Expect(res.GetNextPageToken()).NotTo(BeEmpty())
+
+			rows = sqlmock.NewRows([]string{"id", "name", "email"}).
+				AddRow(1, "alice", "alice@example.com")
+			mock.ExpectQuery(`^SELECT (.+) FROM "users" WHERE \("users"."deleted_at" is null\) AND \(\(\("users"."name" < \$1\) OR \("users"."name" = \$2 AND "users"."id" > \$3\)\)\) ORDER BY name DESC, id LIMIT 2;$`).
+				WithArgs("bob", "bob", "2").WillReturnRows(rows)
+
+			res, err = client.ReadAll(context.Background(),
+				&pkg.ReadAllRequest{OrderBy: "name desc", Limit: 1, PageToken: res.GetNextPageToken()})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).NotTo(BeNil())
+			Expect(res.GetUsers()).To(Equal([]*pkg.User{{Id: 1, Name: "alice", Email: "alice@example.com"}}))
+			Expect(res.GetNextPageToken()).To(BeEmpty())
+		})
+
+		It("gives InvalidArgument error if page token was issued for another query", func() {
+			res, err := client.ReadAll(context.Background(),
+				&pkg.ReadAllRequest{OrderBy: "name", PageToken: "eyJpZCI6Mn0"})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.InvalidArgument))
+			Expect(grpcStatus.Message()).To(Equal("UserService.ReadAll: page token does not match filter or order_by"))
+		})
+
+		DescribeTable("gives InvalidArgument error for bad expressions",
+			func(in *pkg.ReadAllRequest, message string) {
+				res, err := client.ReadAll(context.Background(), in)
+
+				Expect(err).To(HaveOccurred())
+				Expect(res).To(BeNil())
+
+				grpcStatus, ok := status.FromError(err)
+				Expect(ok).To(BeTrue())
+				Expect(grpcStatus.Code()).To(Equal(codes.InvalidArgument))
+				Expect(grpcStatus.Message()).To(Equal(message))
+			},
+			Entry("unknown filter field", &pkg.ReadAllRequest{Filter: `password="secret"`},
+				`UserService.ReadAll: filter: unknown field "password"`),
+			Entry("unsupported operator", &pkg.ReadAllRequest{Filter: `id:"1*"`},
+				`UserService.ReadAll: filter: field "id": operator ":" is not supported`),
+			Entry("bad integer", &pkg.ReadAllRequest{Filter: `id=abc`},
+				`UserService.ReadAll: filter: field "id": expected integer value, got "abc"`),
+			Entry("missing value", &pkg.ReadAllRequest{Filter: `name=`},
+				`UserService.ReadAll: filter: expected value after "=" at position 5`),
+			Entry("unbalanced parens", &pkg.ReadAllRequest{Filter: `(name="a"`},
+				`UserService.ReadAll: filter: expected ")" at position 9`),
+			Entry("unknown order_by field", &pkg.ReadAllRequest{OrderBy: "password"},
+				`UserService.ReadAll: order_by: unknown field "password"`),
+			Entry("unknown order_by direction", &pkg.ReadAllRequest{OrderBy: "name up"},
+				`UserService.ReadAll: order_by: unknown direction "up" of field "name"`),
+			Entry("duplicate order_by field", &pkg.ReadAllRequest{OrderBy: "name, name desc"},
+				`UserService.ReadAll: order_by: duplicate field "name"`),
+		)
 	})
 
 	Describe("Read", func() {
@@ -211,6 +656,22 @@ var _ = Describe("Profile", func() {
 			Expect(res.GetUser()).To(Equal(&pkg.User{Id: 1, Name: "user", Email: "user@example.com"}))
 		})
 
+		It("gives audit fields of user", func() {
+			created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+			updated := created.Add(time.Hour)
+			rows := sqlmock.NewRows([]string{"id", "name", "email", "created_at", "updated_at", "created_by", "updated_by"}).
+				AddRow(1, "user", "user@example.com", created, updated, "admin", nil)
+			mock.ExpectQuery(q).WithArgs(1).WillReturnRows(rows)
+
+			res, err := client.Read(context.Background(), &pkg.ReadRequest{Id: 1})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.GetUser().GetCreateTime()).To(Equal(&timestamp.Timestamp{Seconds: created.Unix()}))
+			Expect(res.GetUser().GetUpdateTime()).To(Equal(&timestamp.Timestamp{Seconds: updated.Unix()}))
+			Expect(res.GetUser().GetCreatedBy()).To(Equal("admin"))
+			Expect(res.GetUser().GetUpdatedBy()).To(BeEmpty())
+		})
+
 		It("gives Internal error if cannot get user", func() {
 			mock.ExpectQuery(q).WithArgs(1).WillReturnError(errors.New("some error"))
 
@@ -238,13 +699,91 @@ var _ = Describe("Profile", func() {
 			Expect(grpcStatus.Code()).To(Equal(codes.NotFound))
 			Expect(grpcStatus.Message()).To(Equal(codes.NotFound.String()))
 		})
+
+		It("gives NotFound error if user is deleted", func() {
+			rows := sqlmock.NewRows([]string{"id", "name", "email", "deleted_at"}).
+				AddRow(1, "user", "user@example.com", time.Now())
+			mock.ExpectQuery(q).WithArgs(1).WillReturnRows(rows)
+
+			res, err := client.Read(context.Background(), &pkg.ReadRequest{Id: 1})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.NotFound))
+		})
+
+		It("can get deleted user if asked", func() {
+			rows := sqlmock.NewRows([]string{"id", "name", "email", "deleted_at"}).
+				AddRow(1, "user", "user@example.com", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+			mock.ExpectQuery(q).WithArgs(1).WillReturnRows(rows)
+
+			res, err := client.Read(context.Background(), &pkg.ReadRequest{Id: 1, ShowDeleted: true})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).NotTo(BeNil())
+			Expect(res.GetUser().GetDeleteTime()).To(Equal(&timestamp.Timestamp{Seconds: 1577934245}))
+		})
+
+		It("gives etag of user", func() {
+			rows := sqlmock.NewRows([]string{"id", "name", "email", "version"}).
+				AddRow(1, "user", "user@example.com", 3)
+			mock.ExpectQuery(q).WithArgs(1).WillReturnRows(rows)
+
+			res, err := client.Read(context.Background(), &pkg.ReadRequest{Id: 1})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).NotTo(BeNil())
+			Expect(res.GetUser()).To(Equal(&pkg.User{Id: 1, Name: "user", Email: "user@example.com", Etag: "3"}))
+		})
+
+		It("can get masked fields of user", func() {
+			rows := sqlmock.NewRows([]string{"name", "deleted_at"}).AddRow("user", nil)
+			mock.ExpectQuery(`^select "name","deleted_at" from "users" where "id"=\$1$`).WithArgs(1).WillReturnRows(rows)
+
+			res, err := client.Read(context.Background(),
+				&pkg.ReadRequest{Id: 1, Fields: &field_mask.FieldMask{Paths: []string{"name"}}})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).NotTo(BeNil())
+			Expect(res.GetUser()).To(Equal(&pkg.User{Name: "user"}))
+		})
+
+		It("gives InvalidArgument error if field mask has unknown path", func() {
+			res, err := client.Read(context.Background(),
+				&pkg.ReadRequest{Id: 1, Fields: &field_mask.FieldMask{Paths: []string{"name; drop table users"}}})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.InvalidArgument))
+			Expect(grpcStatus.Message()).To(Equal(`UserService.Read: unknown field mask path "name; drop table users"`))
+		})
 	})
 
 	Describe("Update", func() {
-		q := `^UPDATE "users" SET "name"=\$1,"email"=\$2 WHERE "id"=\$3$`
+		qLock := `^SELECT "name", "email" FROM "users" WHERE \("users"."id" = \$1\) AND \("users"."deleted_at" is null\) LIMIT 1 FOR UPDATE;$`
+		q := `^UPDATE "users" SET "email" = \$1, "name" = \$2, "updated_at" = \$3, "updated_by" = \$4 WHERE \("users"."id" = \$5\);$`
+		oldRows := func() *sqlmock.Rows {
+			return sqlmock.NewRows([]string{"name", "email"}).AddRow("user0", "user0@example.com")
+		}
 
 		It("can update user by id", func() {
-			mock.ExpectExec(q).WithArgs("user1", "user1@example.com", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectBegin()
+			mock.ExpectQuery(qLock).WithArgs(1).WillReturnRows(oldRows())
+			mock.ExpectExec(q).WithArgs("user1@example.com", "user1", sqlmock.AnyArg(), nil, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(qAudit).
+				WithArgs(1, "UserService.Update", nil,
+					jsonArg(`{"name": "user0", "email": "user0@example.com"}`),
+					jsonArg(`{"name": "user1", "email": "user1@example.com"}`),
+					nil, nil, sqlmock.AnyArg()).
+				WillReturnRows(auditRows(1))
+			mock.ExpectExec(qOutbox).WithArgs("users", "1", sqlmock.AnyArg()).WillReturnResult(outboxResult)
+			mock.ExpectCommit()
 
 			res, err := client.Update(context.Background(), &pkg.UpdateRequest{
 				Id:     1,
@@ -256,6 +795,48 @@ var _ = Describe("Profile", func() {
 			Expect(res).NotTo(BeNil())
 		})
 
+		It("records only changed fields in audit log", func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(`^SELECT "name" FROM "users" WHERE (.+) FOR UPDATE;$`).WithArgs(1).
+				WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("user0"))
+			mock.ExpectExec(`^UPDATE "users" SET "name" = \$1, "updated_at" = \$2, "updated_by" = \$3 WHERE \("users"."id" = \$4\);$`).
+				WithArgs("user1", sqlmock.AnyArg(), nil, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(qAudit).
+				WithArgs(1, "UserService.Update", nil, jsonArg(`{"name": "user0"}`), jsonArg(`{"name": "user1"}`), nil, nil, sqlmock.AnyArg()).
+				WillReturnRows(auditRows(1))
+			mock.ExpectExec(qOutbox).WithArgs("users", "1", sqlmock.AnyArg()).WillReturnResult(outboxResult)
+			mock.ExpectCommit()
+
+			_, err := client.Update(context.Background(), &pkg.UpdateRequest{
+				Id:     1,
+				User:   &pkg.User{Name: "user1", Email: "user1@example.com"},
+				Fields: &field_mask.FieldMask{Paths: []string{"name"}},
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("records authenticated caller as updater", func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(qLock).WithArgs(1).WillReturnRows(oldRows())
+			mock.ExpectExec(q).WithArgs("user1@example.com", "user1", sqlmock.AnyArg(), "admin", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(qAudit).
+				WithArgs(1, "UserService.Update", "admin", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil, sqlmock.AnyArg()).
+				WillReturnRows(auditRows(1))
+			mock.ExpectExec(qOutbox).WithArgs("users", "1", sqlmock.AnyArg()).WillReturnResult(outboxResult)
+			mock.ExpectCommit()
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), testSubjectMetadata, "admin")
+			res, err := client.Update(ctx, &pkg.UpdateRequest{
+				Id:     1,
+				User:   &pkg.User{Name: "user1", Email: "user1@example.com"},
+				Fields: &field_mask.FieldMask{Paths: []string{"name", "email"}},
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).NotTo(BeNil())
+		})
+
 		It("gives InvalidArgument error if fields not specified", func() {
 			res, err := client.Update(context.Background(), &pkg.UpdateRequest{
 				Id:   1,
@@ -271,13 +852,11 @@ var _ = Describe("Profile", func() {
 			Expect(grpcStatus.Message()).To(Equal("UserService.Update: fields must be specified"))
 		})
 
-		It("gives Internal error if cannot update user", func() {
-			mock.ExpectExec(q).WithArgs("user1", "user1@example.com", 1).WillReturnError(errors.New("some error"))
-
+		It("gives InvalidArgument error if field can not be updated", func() {
 			res, err := client.Update(context.Background(), &pkg.UpdateRequest{
 				Id:     1,
-				User:   &pkg.User{Name: "user1", Email: "user1@example.com"},
-				Fields: &field_mask.FieldMask{Paths: []string{"name", "email"}},
+				User:   &pkg.User{Id: 2, Name: "user1"},
+				Fields: &field_mask.FieldMask{Paths: []string{"name", "id"}},
 			})
 
 			Expect(err).To(HaveOccurred())
@@ -285,12 +864,16 @@ var _ = Describe("Profile", func() {
 
 			grpcStatus, ok := status.FromError(err)
 			Expect(ok).To(BeTrue())
-			Expect(grpcStatus.Code()).To(Equal(codes.Internal))
-			Expect(grpcStatus.Message()).To(Equal("UserService.Update: models: unable to update users row: some error"))
+			Expect(grpcStatus.Code()).To(Equal(codes.InvalidArgument))
+			Expect(grpcStatus.Message()).To(Equal(`UserService.Update: unknown field mask path "id"`))
 		})
 
-		It("gives NotFound error if updated 0 rows", func() {
-			mock.ExpectExec(q).WithArgs("user1", "user1@example.com", 1).WillReturnResult(sqlmock.NewResult(0, 0))
+		It("gives AlreadyExists error if email is taken", func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(qLock).WithArgs(1).WillReturnRows(oldRows())
+			mock.ExpectExec(q).WithArgs("user1@example.com", "user1", sqlmock.AnyArg(), nil, 1).
+				WillReturnError(&pq.Error{Code: "23505", Constraint: "users_email_lower_key"})
+			mock.ExpectRollback()
 
 			res, err := client.Update(context.Background(), &pkg.UpdateRequest{
 				Id:     1,
@@ -303,12 +886,159 @@ var _ = Describe("Profile", func() {
 
 			grpcStatus, ok := status.FromError(err)
 			Expect(ok).To(BeTrue())
-			Expect(grpcStatus.Code()).To(Equal(codes.NotFound))
-			Expect(grpcStatus.Message()).To(Equal(codes.NotFound.String()))
+			Expect(grpcStatus.Code()).To(Equal(codes.AlreadyExists))
+			Expect(grpcStatus.Message()).To(Equal("UserService.Update: user with this email already exists"))
 		})
 
-		It("gives Internal error if updated more than 1 row", func() {
-			mock.ExpectExec(q).WithArgs("user1", "user1@example.com", 1).WillReturnResult(sqlmock.NewResult(0, 2))
+		Context("with etag", func() {
+			qLockVersion := `^SELECT "name", "email" FROM "users" WHERE \("users"."id" = \$1\) AND \("users"."deleted_at" is null\) AND \("users"."version" = \$2\) LIMIT 1 FOR UPDATE;$`
+			qExists := `^SELECT COUNT\(\*\) FROM "users" WHERE \("users"."id" = \$1\) AND \("users"."deleted_at" is null\) LIMIT 1;$`
+
+			It("can update user if etag matches", func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(qLockVersion).WithArgs(1, 3).WillReturnRows(oldRows())
+				mock.ExpectExec(q).WithArgs("user1@example.com", "user1", sqlmock.AnyArg(), nil, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(qAudit).WillReturnRows(auditRows(1))
+				mock.ExpectExec(qOutbox).WithArgs("users", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(outboxResult)
+				mock.ExpectCommit()
+
+				res, err := client.Update(context.Background(), &pkg.UpdateRequest{
+					Id:     1,
+					User:   &pkg.User{Name: "user1", Email: "user1@example.com"},
+					Fields: &field_mask.FieldMask{Paths: []string{"name", "email"}},
+					Etag:   "3",
+				})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res).NotTo(BeNil())
+			})
+
+			It("takes etag from If-Match header", func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(qLockVersion).WithArgs(1, 3).WillReturnRows(oldRows())
+				mock.ExpectExec(q).WithArgs("user1@example.com", "user1", sqlmock.AnyArg(), nil, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(qAudit).WillReturnRows(auditRows(1))
+				mock.ExpectExec(qOutbox).WithArgs("users", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(outboxResult)
+				mock.ExpectCommit()
+
+				ctx := metadata.AppendToOutgoingContext(context.Background(), "grpcgateway-if-match", `W/"3"`)
+				res, err := client.Update(ctx, &pkg.UpdateRequest{
+					Id:     1,
+					User:   &pkg.User{Name: "user1", Email: "user1@example.com"},
+					Fields: &field_mask.FieldMask{Paths: []string{"name", "email"}},
+				})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res).NotTo(BeNil())
+			})
+
+			It("gives Aborted error if user was changed", func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(qLockVersion).WithArgs(1, 3).WillReturnRows(sqlmock.NewRows([]string{"name", "email"}))
+				mock.ExpectQuery(qExists).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+
+				res, err := client.Update(context.Background(), &pkg.UpdateRequest{
+					Id:     1,
+					User:   &pkg.User{Name: "user1", Email: "user1@example.com"},
+					Fields: &field_mask.FieldMask{Paths: []string{"name", "email"}},
+					Etag:   "3",
+				})
+
+				Expect(err).To(HaveOccurred())
+				Expect(res).To(BeNil())
+
+				grpcStatus, ok := status.FromError(err)
+				Expect(ok).To(BeTrue())
+				Expect(grpcStatus.Code()).To(Equal(codes.Aborted))
+				Expect(grpcStatus.Message()).To(Equal("UserService.Update: etag does not match, user was changed"))
+			})
+
+			It("gives NotFound error if user does not exist", func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(qLockVersion).WithArgs(1, 3).WillReturnRows(sqlmock.NewRows([]string{"name", "email"}))
+				mock.ExpectQuery(qExists).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectRollback()
+
+				res, err := client.Update(context.Background(), &pkg.UpdateRequest{
+					Id:     1,
+					User:   &pkg.User{Name: "user1", Email: "user1@example.com"},
+					Fields: &field_mask.FieldMask{Paths: []string{"name", "email"}},
+					Etag:   "3",
+				})
+
+				Expect(err).To(HaveOccurred())
+				Expect(res).To(BeNil())
+
+				grpcStatus, ok := status.FromError(err)
+				Expect(ok).To(BeTrue())
+				Expect(grpcStatus.Code()).To(Equal(codes.NotFound))
+			})
+
+			It("gives InvalidArgument error if etag is malformed", func() {
+				res, err := client.Update(context.Background(), &pkg.UpdateRequest{
+					Id:     1,
+					User:   &pkg.User{Name: "user1", Email: "user1@example.com"},
+					Fields: &field_mask.FieldMask{Paths: []string{"name", "email"}},
+					Etag:   "abc",
+				})
+
+				Expect(err).To(HaveOccurred())
+				Expect(res).To(BeNil())
+
+				grpcStatus, ok := status.FromError(err)
+				Expect(ok).To(BeTrue())
+				Expect(grpcStatus.Code()).To(Equal(codes.InvalidArgument))
+				Expect(grpcStatus.Message()).To(Equal(`UserService.Update: invalid etag "abc"`))
+			})
+		})
+
+		It("gives Internal error if cannot update user", func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(qLock).WithArgs(1).WillReturnRows(oldRows())
+			mock.ExpectExec(q).WithArgs("user1@example.com", "user1", sqlmock.AnyArg(), nil, 1).WillReturnError(errors.New("some error"))
+			mock.ExpectRollback()
+
+			res, err := client.Update(context.Background(), &pkg.UpdateRequest{
+				Id:     1,
+				User:   &pkg.User{Name: "user1", Email: "user1@example.com"},
+				Fields: &field_mask.FieldMask{Paths: []string{"name", "email"}},
+			})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.Internal))
+			Expect(grpcStatus.Message()).To(Equal("UserService.Update: models: unable to update all for users: some error"))
+		})
+
+		It("gives NotFound error if user does not exist", func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(qLock).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"name", "email"}))
+			mock.ExpectRollback()
+
+			res, err := client.Update(context.Background(), &pkg.UpdateRequest{
+				Id:     1,
+				User:   &pkg.User{Name: "user1", Email: "user1@example.com"},
+				Fields: &field_mask.FieldMask{Paths: []string{"name", "email"}},
+			})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.NotFound))
+			Expect(grpcStatus.Message()).To(Equal(codes.NotFound.String()))
+		})
+
+		It("gives Internal error if updated more than 1 row", func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(qLock).WithArgs(1).WillReturnRows(oldRows())
+			mock.ExpectExec(q).WithArgs("user1@example.com", "user1", sqlmock.AnyArg(), nil, 1).WillReturnResult(sqlmock.NewResult(0, 2))
+			mock.ExpectRollback()
 
 			res, err := client.Update(context.Background(), &pkg.UpdateRequest{
 				Id:     1,
@@ -327,10 +1057,16 @@ var _ = Describe("Profile", func() {
 	})
 
 	Describe("Delete", func() {
-		q := `^DELETE FROM "users" WHERE "id"=\$1$`
+		q := `^UPDATE "users" SET "deleted_at" = \$1 WHERE \("users"."id" = \$2\) AND \("users"."deleted_at" is null\);$`
 
 		It("can delete user by id", func() {
-			mock.ExpectExec(q).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectBegin()
+			mock.ExpectExec(q).WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(qAudit).
+				WithArgs(1, "UserService.Delete", nil, jsonArg(`{"deleted_at": null}`), sqlmock.AnyArg(), nil, nil, sqlmock.AnyArg()).
+				WillReturnRows(auditRows(1))
+			mock.ExpectExec(qOutbox).WithArgs("users", "1", sqlmock.AnyArg()).WillReturnResult(outboxResult)
+			mock.ExpectCommit()
 
 			res, err := client.Delete(context.Background(), &pkg.DeleteRequest{Id: 1})
 
@@ -339,7 +1075,9 @@ var _ = Describe("Profile", func() {
 		})
 
 		It("gives Internal error if cannot delete user", func() {
-			mock.ExpectExec(q).WithArgs(1).WillReturnError(errors.New("some error"))
+			mock.ExpectBegin()
+			mock.ExpectExec(q).WithArgs(sqlmock.AnyArg(), 1).WillReturnError(errors.New("some error"))
+			mock.ExpectRollback()
 
 			res, err := client.Delete(context.Background(), &pkg.DeleteRequest{Id: 1})
 
@@ -349,11 +1087,13 @@ var _ = Describe("Profile", func() {
 			grpcStatus, ok := status.FromError(err)
 			Expect(ok).To(BeTrue())
 			Expect(grpcStatus.Code()).To(Equal(codes.Internal))
-			Expect(grpcStatus.Message()).To(Equal("UserService.Delete: models: unable to delete from users: some error"))
+			Expect(grpcStatus.Message()).To(Equal("UserService.Delete: models: unable to update all for users: some error"))
 		})
 
 		It("gives NotFound error if updated 0 rows", func() {
-			mock.ExpectExec(q).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectBegin()
+			mock.ExpectExec(q).WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectRollback()
 
 			res, err := client.Delete(context.Background(), &pkg.DeleteRequest{Id: 1})
 
@@ -367,7 +1107,9 @@ var _ = Describe("Profile", func() {
 		})
 
 		It("gives Internal error if updated more than 1 row", func() {
-			mock.ExpectExec(q).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 2))
+			mock.ExpectBegin()
+			mock.ExpectExec(q).WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 2))
+			mock.ExpectRollback()
 
 			res, err := client.Delete(context.Background(), &pkg.DeleteRequest{Id: 1})
 
@@ -379,5 +1121,974 @@ var _ = Describe("Profile", func() {
 			Expect(grpcStatus.Code()).To(Equal(codes.Internal))
 			Expect(grpcStatus.Message()).To(Equal("UserService.Delete: expect deleting 1 row, but deleted 2 rows"))
 		})
+
+		It("gives Aborted error if etag does not match", func() {
+			mock.ExpectBegin()
+			mock.ExpectExec(`^UPDATE "users" SET "deleted_at" = \$1 WHERE \("users"."id" = \$2\) AND \("users"."deleted_at" is null\) AND \("users"."version" = \$3\);$`).
+				WithArgs(sqlmock.AnyArg(), 1, 3).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM "users" WHERE \("users"."id" = \$1\) AND \("users"."deleted_at" is null\) LIMIT 1;$`).
+				WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			mock.ExpectRollback()
+
+			res, err := client.Delete(context.Background(), &pkg.DeleteRequest{Id: 1, Etag: `"3"`})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.Aborted))
+			Expect(grpcStatus.Message()).To(Equal("UserService.Delete: etag does not match, user was changed"))
+		})
+	})
+
+	Describe("UndeleteUser", func() {
+		qLock := `^SELECT "deleted_at" FROM "users" WHERE \("users"."id" = \$1\) AND \("users"."deleted_at" is not null\) LIMIT 1 FOR UPDATE;$`
+		q := `^UPDATE "users" SET "deleted_at" = \$1 WHERE \("users"."id" = \$2\);$`
+		qExists := `^select exists\(select 1 from "users" where "id"=\$1 limit 1\)$`
+		deletedRows := func() *sqlmock.Rows {
+			return sqlmock.NewRows([]string{"deleted_at"}).AddRow(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+		}
+
+		It("can undelete user by id", func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(qLock).WithArgs(1).WillReturnRows(deletedRows())
+			mock.ExpectExec(q).WithArgs(nil, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(qAudit).
+				WithArgs(1, "UserService.UndeleteUser", nil,
+					jsonArg(`{"deleted_at": "2020-01-01T00:00:00Z"}`), jsonArg(`{"deleted_at": null}`),
+					nil, nil, sqlmock.AnyArg()).
+				WillReturnRows(auditRows(1))
+			mock.ExpectExec(qOutbox).WithArgs("users", "1", sqlmock.AnyArg()).WillReturnResult(outboxResult)
+			mock.ExpectCommit()
+
+			res, err := client.UndeleteUser(context.Background(), &pkg.UndeleteUserRequest{Id: 1})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).NotTo(BeNil())
+		})
+
+		It("gives FailedPrecondition error if user is not deleted", func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(qLock).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"deleted_at"}))
+			mock.ExpectQuery(qExists).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			mock.ExpectRollback()
+
+			res, err := client.UndeleteUser(context.Background(), &pkg.UndeleteUserRequest{Id: 1})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.FailedPrecondition))
+			Expect(grpcStatus.Message()).To(Equal("UserService.UndeleteUser: user is not deleted"))
+		})
+
+		It("gives NotFound error if user does not exist", func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(qLock).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"deleted_at"}))
+			mock.ExpectQuery(qExists).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			mock.ExpectRollback()
+
+			res, err := client.UndeleteUser(context.Background(), &pkg.UndeleteUserRequest{Id: 1})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.NotFound))
+		})
+
+		It("gives AlreadyExists error if email was taken while user was deleted", func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(qLock).WithArgs(1).WillReturnRows(deletedRows())
+			mock.ExpectExec(q).WithArgs(nil, 1).
+				WillReturnError(&pq.Error{Code: "23505", Constraint: "users_email_lower_key"})
+			mock.ExpectRollback()
+
+			res, err := client.UndeleteUser(context.Background(), &pkg.UndeleteUserRequest{Id: 1})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.AlreadyExists))
+		})
+	})
+
+	Describe("Purge", func() {
+		It("deletes users which were deleted before given time", func() {
+			before := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			mock.ExpectExec(`^DELETE FROM "users" WHERE \("users"."deleted_at" < \$1\);$`).
+				WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 3))
+
+			rows, err := users.Purge(context.Background(), before)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rows).To(Equal(int64(3)))
+		})
+	})
+
+	Describe("transactions", func() {
+		q := `^INSERT INTO "users" (.+) VALUES (.+) RETURNING "id","version"$`
+
+		It("retries transaction on serialization failure", func() {
+			policy := pkgdb.RetryPolicy{MaxRetries: 1, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+			service := &internal.UserService{Users: internal.NewPostgresUserRepository(pkgdb.NewTxManagerWithPolicy(db, policy), pkgdb.NewReplicaRouter(db))}
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(q).WillReturnError(&pq.Error{Code: "40001"})
+			mock.ExpectRollback()
+			mock.ExpectBegin()
+			mock.ExpectQuery(q).WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 1))
+			mock.ExpectQuery(qAudit).WillReturnRows(auditRows(1))
+			mock.ExpectExec(qOutbox).WillReturnResult(outboxResult)
+			mock.ExpectCommit()
+
+			res, err := service.Create(context.Background(),
+				&pkg.CreateRequest{User: &pkg.User{Name: "user", Email: "user@example.com"}})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.GetId()).To(Equal(int64(1)))
+		})
+
+		It("runs nested transaction in savepoint", func() {
+			mock.ExpectBegin()
+			mock.ExpectExec(`^SAVEPOINT sp_1$`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(`^ROLLBACK TO SAVEPOINT sp_1$`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(`^SAVEPOINT sp_2$`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(`^RELEASE SAVEPOINT sp_2$`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectCommit()
+
+			err := users.WithinTx(context.Background(), func(ctx context.Context) error {
+				nestedErr := users.WithinTx(ctx, func(ctx context.Context) error {
+					return errors.New("some error")
+				})
+				Expect(nestedErr).To(MatchError("some error"))
+
+				return users.WithinTx(ctx, func(ctx context.Context) error { return nil })
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("read replicas", func() {
+		q := `^select (.+) from "users" where "id"=\$1$`
+
+		var (
+			replica     *sql.DB
+			replicaMock sqlmock.Sqlmock
+			router      *pkgdb.ReplicaRouter
+			service     *internal.UserService
+		)
+
+		BeforeEach(func() {
+			var err error
+			replica, replicaMock, err = sqlmock.New()
+			Expect(err).NotTo(HaveOccurred())
+
+			router = pkgdb.NewReplicaRouter(db, replica)
+			router.MaxLag = time.Second
+			service = &internal.UserService{
+				Users: internal.NewPostgresUserRepository(pkgdb.NewTxManagerWithPolicy(db, pkgdb.RetryPolicy{}), router),
+			}
+		})
+
+		AfterEach(func() {
+			Expect(replicaMock.ExpectationsWereMet()).NotTo(HaveOccurred())
+
+			replicaMock.ExpectClose()
+			Expect(replica.Close()).To(Succeed())
+		})
+
+		It("reads user from replica", func() {
+			replicaMock.ExpectQuery(q).WithArgs(1).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "user"))
+
+			res, err := service.Read(context.Background(), &pkg.ReadRequest{Id: 1})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.GetUser().GetName()).To(Equal("user"))
+		})
+
+		It("reads user from primary if request forces it", func() {
+			mock.ExpectQuery(q).WithArgs(1).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "user"))
+
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-read-primary", "true"))
+			res, err := service.Read(ctx, &pkg.ReadRequest{Id: 1})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.GetUser().GetName()).To(Equal("user"))
+		})
+
+		It("reads user from primary if replica lags", func() {
+			replicaMock.ExpectQuery(`^SELECT COALESCE`).WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(5.0))
+			router.Check(context.Background(), logrus.NewEntry(logrus.New()))
+			Expect(router.Replicas[0].Healthy()).To(BeFalse())
+
+			mock.ExpectQuery(q).WithArgs(1).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "user"))
+
+			res, err := service.Read(context.Background(), &pkg.ReadRequest{Id: 1})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.GetUser().GetName()).To(Equal("user"))
+		})
+	})
+
+	Describe("BatchCreateUsers", func() {
+		q := `^INSERT INTO "users" (.+) VALUES (.+) RETURNING "id","version"$`
+
+		It("can create users in one transaction", func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(q).WithArgs("user1", "user1@example.com", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil).WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 1))
+			mock.ExpectQuery(qAudit).
+				WithArgs(1, "UserService.BatchCreateUsers", nil, nil, jsonArg(`{"name": "user1", "email": "user1@example.com"}`), nil, nil, sqlmock.AnyArg()).
+				WillReturnRows(auditRows(1))
+			mock.ExpectExec(qOutbox).WithArgs("users", "1", sqlmock.AnyArg()).WillReturnResult(outboxResult)
+			mock.ExpectQuery(q).WithArgs("user2", "user2@example.com", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil).WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(2, 1))
+			mock.ExpectQuery(qAudit).
+				WithArgs(2, "UserService.BatchCreateUsers", nil, nil, jsonArg(`{"name": "user2", "email": "user2@example.com"}`), nil, nil, sqlmock.AnyArg()).
+				WillReturnRows(auditRows(2))
+			mock.ExpectExec(qOutbox).WithArgs("users", "2", sqlmock.AnyArg()).WillReturnResult(outboxResult)
+			mock.ExpectCommit()
+
+			res, err := client.BatchCreateUsers(context.Background(), &pkg.BatchCreateUsersRequest{Users: []*pkg.User{
+				{Name: "user1", Email: "user1@example.com"},
+				{Name: "user2", Email: "user2@example.com"},
+			}})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).NotTo(BeNil())
+			Expect(res.GetIds()).To(Equal([]int64{1, 2}))
+		})
+
+		It("gives InvalidArgument error with all invalid users", func() {
+			res, err := client.BatchCreateUsers(context.Background(), &pkg.BatchCreateUsersRequest{Users: []*pkg.User{
+				{Name: "user1"},
+				{Name: "user2", Email: "user2@example.com"},
+				{Email: "user3@example.com"},
+			}})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.InvalidArgument))
+			Expect(grpcStatus.Message()).To(Equal("2 of 3 users are invalid"))
+			Expect(grpcStatus.Details()).To(HaveLen(1))
+
+			badRequest, ok := grpcStatus.Details()[0].(*errdetails.BadRequest)
+			Expect(ok).To(BeTrue())
+			Expect(badRequest.GetFieldViolations()).To(HaveLen(2))
+			Expect(badRequest.GetFieldViolations()[0].GetField()).To(Equal("users[0]"))
+			Expect(badRequest.GetFieldViolations()[0].GetDescription()).To(Equal("invalid field Email: value '' must not be an empty string"))
+			Expect(badRequest.GetFieldViolations()[1].GetField()).To(Equal("users[2]"))
+		})
+
+		It("gives AlreadyExists error with index of user if email is taken", func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(q).WithArgs("user1", "user1@example.com", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil).WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 1))
+			mock.ExpectQuery(qAudit).WillReturnRows(auditRows(1))
+			mock.ExpectExec(qOutbox).WithArgs("users", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(outboxResult)
+			mock.ExpectQuery(q).WithArgs("user2", "USER1@example.com", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil).
+				WillReturnError(&pq.Error{Code: "23505", Constraint: "users_email_lower_key"})
+			mock.ExpectRollback()
+
+			res, err := client.BatchCreateUsers(context.Background(), &pkg.BatchCreateUsersRequest{Users: []*pkg.User{
+				{Name: "user1", Email: "user1@example.com"},
+				{Name: "user2", Email: "USER1@example.com"},
+			}})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.AlreadyExists))
+			Expect(grpcStatus.Message()).To(Equal("UserService.BatchCreateUsers: users[1]: user with this email already exists"))
+
+			badRequest, ok := grpcStatus.Details()[0].(*errdetails.BadRequest)
+			Expect(ok).To(BeTrue())
+			Expect(badRequest.GetFieldViolations()[0].GetField()).To(Equal("users[1].email"))
+		})
+
+		It("rolls back if cannot create some user", func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(q).WithArgs("user1", "user1@example.com", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil).WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 1))
+			mock.ExpectQuery(qAudit).WillReturnRows(auditRows(1))
+			mock.ExpectExec(qOutbox).WithArgs("users", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(outboxResult)
+			mock.ExpectQuery(q).WithArgs("user2", "user2@example.com", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil).WillReturnError(errors.New("some error"))
+			mock.ExpectRollback()
+
+			res, err := client.BatchCreateUsers(context.Background(), &pkg.BatchCreateUsersRequest{Users: []*pkg.User{
+				{Name: "user1", Email: "user1@example.com"},
+				{Name: "user2", Email: "user2@example.com"},
+			}})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.Internal))
+			Expect(grpcStatus.Message()).To(Equal("UserService.BatchCreateUsers: users[1]: models: unable to insert into users: some error"))
+		})
+	})
+
+	Describe("BatchGetUsers", func() {
+		q := `^SELECT "users".\* FROM "users" WHERE (.+)$`
+
+		It("can get users in order of request", func() {
+			rows := sqlmock.NewRows([]string{"id", "name", "email"}).
+				AddRow(1, "user1", "user1@example.com").
+				AddRow(2, "user2", "user2@example.com")
+			mock.ExpectQuery(q).WithArgs(2, 1).WillReturnRows(rows)
+
+			res, err := client.BatchGetUsers(context.Background(), &pkg.BatchGetUsersRequest{
+				Ids:    []int64{2, 1, 2},
+				Fields: &field_mask.FieldMask{Paths: []string{"id", "name"}},
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).NotTo(BeNil())
+			Expect(res.GetUsers()).To(Equal([]*pkg.User{
+				{Id: 2, Name: "user2"},
+				{Id: 1, Name: "user1"},
+				{Id: 2, Name: "user2"},
+			}))
+		})
+
+		It("gives NotFound error if some user does not exist", func() {
+			rows := sqlmock.NewRows([]string{"id", "name", "email"}).
+				AddRow(1, "user1", "user1@example.com")
+			mock.ExpectQuery(q).WithArgs(1, 2).WillReturnRows(rows)
+
+			res, err := client.BatchGetUsers(context.Background(), &pkg.BatchGetUsersRequest{Ids: []int64{1, 2}})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.NotFound))
+			Expect(grpcStatus.Message()).To(Equal(codes.NotFound.String()))
+		})
+
+		It("gives NotFound error if some user is deleted", func() {
+			rows := sqlmock.NewRows([]string{"id", "name", "email", "deleted_at"}).
+				AddRow(1, "user1", "user1@example.com", time.Now())
+			mock.ExpectQuery(q).WithArgs(1).WillReturnRows(rows)
+
+			res, err := client.BatchGetUsers(context.Background(), &pkg.BatchGetUsersRequest{Ids: []int64{1}})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.NotFound))
+		})
+
+		It("gives Internal error if cannot get users", func() {
+			mock.ExpectQuery(q).WithArgs(1).WillReturnError(errors.New("some error"))
+
+			res, err := client.BatchGetUsers(context.Background(), &pkg.BatchGetUsersRequest{Ids: []int64{1}})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.Internal))
+			Expect(grpcStatus.Message()).To(Equal("UserService.BatchGetUsers: models: unable to reload all in UserSlice: bind failed to execute query: some error"))
+		})
+	})
+
+	Describe("BatchDeleteUsers", func() {
+		q := `^UPDATE "users" SET "deleted_at" = \$1 WHERE \("users"."id" IN (.+)\) AND \("users"."deleted_at" is null\);$`
+
+		It("can delete users in one transaction", func() {
+			mock.ExpectBegin()
+			mock.ExpectExec(q).WithArgs(sqlmock.AnyArg(), 1, 2).WillReturnResult(sqlmock.NewResult(0, 2))
+			mock.ExpectQuery(qAudit).
+				WithArgs(1, "UserService.BatchDeleteUsers", nil, jsonArg(`{"deleted_at": null}`), sqlmock.AnyArg(), nil, nil, sqlmock.AnyArg()).
+				WillReturnRows(auditRows(1))
+			mock.ExpectExec(qOutbox).WithArgs("users", "1", sqlmock.AnyArg()).WillReturnResult(outboxResult)
+			mock.ExpectQuery(qAudit).
+				WithArgs(2, "UserService.BatchDeleteUsers", nil, jsonArg(`{"deleted_at": null}`), sqlmock.AnyArg(), nil, nil, sqlmock.AnyArg()).
+				WillReturnRows(auditRows(2))
+			mock.ExpectExec(qOutbox).WithArgs("users", "2", sqlmock.AnyArg()).WillReturnResult(outboxResult)
+			mock.ExpectCommit()
+
+			res, err := client.BatchDeleteUsers(context.Background(), &pkg.BatchDeleteUsersRequest{Ids: []int64{1, 2, 1}})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).NotTo(BeNil())
+		})
+
+		It("gives NotFound error and rolls back if some user does not exist", func() {
+			mock.ExpectBegin()
+			mock.ExpectExec(q).WithArgs(sqlmock.AnyArg(), 1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectRollback()
+
+			res, err := client.BatchDeleteUsers(context.Background(), &pkg.BatchDeleteUsersRequest{Ids: []int64{1, 2}})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.NotFound))
+			Expect(grpcStatus.Message()).To(Equal(codes.NotFound.String()))
+		})
+
+		It("gives Internal error if cannot delete users", func() {
+			mock.ExpectBegin()
+			mock.ExpectExec(q).WithArgs(sqlmock.AnyArg(), 1).WillReturnError(errors.New("some error"))
+			mock.ExpectRollback()
+
+			res, err := client.BatchDeleteUsers(context.Background(), &pkg.BatchDeleteUsersRequest{Ids: []int64{1}})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.Internal))
+			Expect(grpcStatus.Message()).To(Equal("UserService.BatchDeleteUsers: models: unable to update all for users: some error"))
+		})
+	})
+
+	Describe("ImportUsers", func() {
+		qExisting := `^SELECT lower\("email"\) AS "email" FROM "users" WHERE \(lower\("email"\) = ANY\(\$1\)\) AND \("users"."deleted_at" is null\);$`
+		q := `^INSERT INTO "users" (.+) VALUES (.+) RETURNING "id","version"$`
+
+		rows := []*pkg.ImportUsersRequest_Row{
+			{Name: "user1", Email: "user1@example.com"},
+			{Name: "user2"},
+			{Name: "user3", Email: "USER1@example.com"},
+			{Name: "user4", Email: "existing@example.com"},
+		}
+
+		importUsers := func(requests ...*pkg.ImportUsersRequest) (*pkg.ImportUsersResponse, error) {
+			stream, err := client.ImportUsers(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, req := range requests {
+				Expect(stream.Send(req)).To(Succeed())
+			}
+
+			return stream.CloseAndRecv()
+		}
+
+		It("creates valid users with new emails and reports other rows", func() {
+			// every message is imported in its own transaction
+			mock.ExpectBegin()
+			mock.ExpectQuery(qExisting).WillReturnRows(sqlmock.NewRows([]string{"email"}))
+			mock.ExpectExec(`^SAVEPOINT sp_1$`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q).WithArgs("user1", "user1@example.com", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 1))
+			mock.ExpectQuery(qAudit).
+				WithArgs(1, "UserService.ImportUsers", nil, nil, jsonArg(`{"name": "user1", "email": "user1@example.com"}`), nil, nil, sqlmock.AnyArg()).
+				WillReturnRows(auditRows(1))
+			mock.ExpectExec(qOutbox).WithArgs("users", "1", sqlmock.AnyArg()).WillReturnResult(outboxResult)
+			mock.ExpectExec(`^RELEASE SAVEPOINT sp_1$`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectCommit()
+			mock.ExpectBegin()
+			mock.ExpectQuery(qExisting).
+				WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("existing@example.com"))
+			mock.ExpectCommit()
+
+			res, err := importUsers(&pkg.ImportUsersRequest{Rows: rows[:2]}, &pkg.ImportUsersRequest{Rows: rows[2:]})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.GetInserted()).To(Equal(int32(1)))
+			Expect(res.GetSkipped()).To(Equal(int32(2)))
+			Expect(res.GetFailed()).To(Equal(int32(1)))
+			Expect(res.GetProblems()).To(HaveLen(3))
+			Expect(res.GetProblems()[0].GetRow()).To(Equal(int32(2)))
+			Expect(res.GetProblems()[0].GetKind()).To(Equal(pkg.ImportUsersResponse_Problem_FAILED))
+			Expect(res.GetProblems()[1].GetRow()).To(Equal(int32(3)))
+			Expect(res.GetProblems()[1].GetMessage()).To(Equal("email is duplicated by previous row"))
+			Expect(res.GetProblems()[2].GetRow()).To(Equal(int32(4)))
+			Expect(res.GetProblems()[2].GetMessage()).To(Equal("user with this email already exists"))
+		})
+
+		It("creates nothing in dry run", func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(qExisting).
+				WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("existing@example.com"))
+			mock.ExpectCommit()
+
+			res, err := importUsers(&pkg.ImportUsersRequest{DryRun: true, Rows: rows})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.GetDryRun()).To(BeTrue())
+			Expect(res.GetInserted()).To(Equal(int32(1)))
+			Expect(res.GetSkipped()).To(Equal(int32(2)))
+			Expect(res.GetFailed()).To(Equal(int32(1)))
+		})
+
+		It("skips row if user with its email is created after the check", func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(qExisting).WillReturnRows(sqlmock.NewRows([]string{"email"}))
+			mock.ExpectExec(`^SAVEPOINT sp_1$`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q).WithArgs("user1", "user1@example.com", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil).
+				WillReturnError(&pq.Error{Code: "23505", Constraint: "users_email_lower_key"})
+			mock.ExpectExec(`^ROLLBACK TO SAVEPOINT sp_1$`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(`^SAVEPOINT sp_2$`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q).WithArgs("user4", "existing@example.com", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(2, 1))
+			mock.ExpectQuery(qAudit).WillReturnRows(auditRows(1))
+			mock.ExpectExec(qOutbox).WillReturnResult(outboxResult)
+			mock.ExpectExec(`^RELEASE SAVEPOINT sp_2$`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectCommit()
+
+			res, err := importUsers(&pkg.ImportUsersRequest{Rows: []*pkg.ImportUsersRequest_Row{rows[0], rows[3]}})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.GetInserted()).To(Equal(int32(1)))
+			Expect(res.GetSkipped()).To(Equal(int32(1)))
+			Expect(res.GetProblems()).To(HaveLen(1))
+			Expect(res.GetProblems()[0].GetRow()).To(Equal(int32(1)))
+			Expect(res.GetProblems()[0].GetKind()).To(Equal(pkg.ImportUsersResponse_Problem_SKIPPED))
+			Expect(res.GetProblems()[0].GetMessage()).To(Equal("user with this email already exists"))
+		})
+
+		It("gives Internal error and rolls back message if cannot create user", func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(qExisting).WillReturnRows(sqlmock.NewRows([]string{"email"}))
+			mock.ExpectExec(`^SAVEPOINT sp_1$`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(q).WillReturnError(errors.New("some error"))
+			mock.ExpectExec(`^ROLLBACK TO SAVEPOINT sp_1$`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectRollback()
+
+			res, err := importUsers(&pkg.ImportUsersRequest{Rows: rows[:1]})
+
+			Expect(res).To(BeNil())
+			Expect(status.Code(err)).To(Equal(codes.Internal))
+		})
+	})
+
+	Describe("ExportUsers", func() {
+		exportUsers := func(req *pkg.ExportUsersRequest) ([]*pkg.User, error) {
+			stream, err := client.ExportUsers(context.Background(), req)
+			Expect(err).NotTo(HaveOccurred())
+
+			var users []*pkg.User
+			for {
+				user, err := stream.Recv()
+				if err == io.EOF {
+					return users, nil
+				}
+				if err != nil {
+					return users, err
+				}
+				users = append(users, user)
+			}
+		}
+
+		It("streams users ordered by id", func() {
+			mock.ExpectQuery(`^SELECT \* FROM "users" WHERE \("users"."deleted_at" is null\) ORDER BY id LIMIT 1000;$`).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).
+					AddRow(1, "user1", "user1@example.com").
+					AddRow(2, "user2", "user2@example.com"))
+
+			users, err := exportUsers(&pkg.ExportUsersRequest{})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(Equal([]*pkg.User{
+				{Id: 1, Name: "user1", Email: "user1@example.com"},
+				{Id: 2, Name: "user2", Email: "user2@example.com"},
+			}))
+		})
+
+		It("gives InvalidArgument error if filter is invalid", func() {
+			_, err := exportUsers(&pkg.ExportUsersRequest{Filter: "unknown = 1"})
+
+			Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+		})
+	})
+
+	Describe("ListUserAuditEvents", func() {
+		q := `^SELECT \* FROM "user_audit_events" WHERE \("user_audit_events"."user_id" = \$1\) ORDER BY id LIMIT 101;$`
+		columns := []string{"id", "user_id", "method", "actor", "before", "after", "request_id", "trace_id", "created_at"}
+		created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		It("can get history of user", func() {
+			rows := sqlmock.NewRows(columns).
+				AddRow(1, 1, "UserService.Create", "admin", nil, []byte(`{"name": "user0", "email": "user@example.com"}`), "req-1", "1f", created).
+				AddRow(2, 1, "UserService.Update", nil, []byte(`{"name": "user0"}`), []byte(`{"name": "user1"}`), nil, nil, created)
+			mock.ExpectQuery(q).WithArgs(1).WillReturnRows(rows)
+
+			res, err := client.ListUserAuditEvents(context.Background(), &pkg.ListUserAuditEventsRequest{Id: 1})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.GetNextPageToken()).To(BeEmpty())
+			Expect(res.GetEvents()).To(HaveLen(2))
+
+			create := res.GetEvents()[0]
+			Expect(create.GetId()).To(Equal(int64(1)))
+			Expect(create.GetUserId()).To(Equal(int64(1)))
+			Expect(create.GetMethod()).To(Equal("UserService.Create"))
+			Expect(create.GetActor()).To(Equal("admin"))
+			Expect(create.GetBefore()).To(BeNil())
+			Expect(create.GetAfter().GetFields()["email"].GetStringValue()).To(Equal("user@example.com"))
+			Expect(create.GetRequestId()).To(Equal("req-1"))
+			Expect(create.GetTraceId()).To(Equal("1f"))
+			Expect(create.GetCreateTime()).To(Equal(&timestamp.Timestamp{Seconds: created.Unix()}))
+
+			update := res.GetEvents()[1]
+			Expect(update.GetActor()).To(BeEmpty())
+			Expect(update.GetBefore().GetFields()["name"].GetStringValue()).To(Equal("user0"))
+			Expect(update.GetAfter().GetFields()["name"].GetStringValue()).To(Equal("user1"))
+		})
+
+		It("gives next page token if there are more events", func() {
+			rows := sqlmock.NewRows(columns).
+				AddRow(1, 1, "UserService.Create", nil, nil, nil, nil, nil, created).
+				AddRow(2, 1, "UserService.Delete", nil, nil, nil, nil, nil, created)
+			mock.ExpectQuery(`^SELECT \* FROM "user_audit_events" WHERE \("user_audit_events"."user_id" = \$1\) ORDER BY id LIMIT 2;$`).
+				WithArgs(1).WillReturnRows(rows)
+
+			res, err := client.ListUserAuditEvents(context.Background(), &pkg.ListUserAuditEventsRequest{Id: 1, PageSize: 1})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.GetEvents()).To(HaveLen(1))
+			Expect(res.GetNextPageToken()).NotTo(BeEmpty())
+
+			mock.ExpectQuery(`^SELECT \* FROM "user_audit_events" WHERE \("user_audit_events"."user_id" = \$1\) AND \("user_audit_events"."id" > \$2\) ORDER BY id LIMIT 2;$`).
+				WithArgs(1, 1).WillReturnRows(sqlmock.NewRows(columns).AddRow(2, 1, "UserService.Delete", nil, nil, nil, nil, nil, created))
+
+			res, err = client.ListUserAuditEvents(context.Background(),
+				&pkg.ListUserAuditEventsRequest{Id: 1, PageSize: 1, PageToken: res.GetNextPageToken()})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.GetEvents()).To(HaveLen(1))
+			Expect(res.GetEvents()[0].GetId()).To(Equal(int64(2)))
+			Expect(res.GetNextPageToken()).To(BeEmpty())
+		})
+
+		It("gives InvalidArgument error if page token is malformed", func() {
+			res, err := client.ListUserAuditEvents(context.Background(), &pkg.ListUserAuditEventsRequest{Id: 1, PageToken: "???"})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.InvalidArgument))
+			Expect(grpcStatus.Message()).To(Equal("UserService.ListUserAuditEvents: invalid page token"))
+		})
+
+		It("gives Internal error if cannot get events", func() {
+			mock.ExpectQuery(q).WithArgs(1).WillReturnError(errors.New("some error"))
+
+			res, err := client.ListUserAuditEvents(context.Background(), &pkg.ListUserAuditEventsRequest{Id: 1})
+
+			Expect(err).To(HaveOccurred())
+			Expect(res).To(BeNil())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.Internal))
+			Expect(grpcStatus.Message()).To(Equal("UserService.ListUserAuditEvents: models: failed to assign all query results to UserAuditEvent slice: bind failed to execute query: some error"))
+		})
+	})
+
+	Describe("WatchUsers", func() {
+		qEvents := `^SELECT "e".\*, "r"."revision" FROM "user_audit_events" AS "e" JOIN "user_event_revisions" AS "r" ON "r"."event_id" = "e"."id" WHERE "r"."revision" > \$1 ORDER BY "r"."revision" LIMIT \$2$`
+		columns := []string{"id", "user_id", "method", "actor", "before", "after", "request_id", "trace_id", "created_at", "revision"}
+		created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		// expectAssign expects revisions to be given to committed events
+		expectAssign := func() {
+			mock.ExpectBegin()
+			mock.ExpectExec(`^SELECT pg_advisory_xact_lock\(\$1\)$`).WithArgs(7264018395).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(`^UPDATE "user_event_revisions" AS r SET "revision" = p."revision" FROM \(SELECT "event_id", row_number\(\) OVER \(ORDER BY "event_id"\) \+ \(SELECT coalesce\(max\("revision"\), 0\) FROM "user_event_revisions"\) AS "revision" FROM "user_event_revisions" WHERE "revision" IS NULL\) AS p WHERE r."event_id" = p."event_id"$`).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+		}
+
+		It("sends events after given revision and then new ones", func() {
+			expectAssign()
+			mock.ExpectQuery(qEvents).WithArgs(5, 1000).
+				WillReturnRows(sqlmock.NewRows(columns).AddRow(6, 1, "UserService.Create", "admin", nil,
+					[]byte(`{"name":"user","email":"user@example.com"}`), nil, nil, created, 6))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			stream, err := client.WatchUsers(ctx, &pkg.WatchUsersRequest{Revision: 5})
+			Expect(err).NotTo(HaveOccurred())
+
+			event, err := stream.Recv()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(event).To(Equal(&pkg.UserEvent{
+				Type: pkg.UserEvent_CREATED,
+				User: &pkg.User{Id: 1, Name: "user", Email: "user@example.com",
+					CreateTime: &timestamp.Timestamp{Seconds: created.Unix()}, UpdateTime: &timestamp.Timestamp{Seconds: created.Unix()},
+					CreatedBy: "admin", UpdatedBy: "admin"},
+				Revision: 6,
+			}))
+
+			expectAssign()
+			mock.ExpectQuery(qEvents).WithArgs(6, 1000).
+				WillReturnRows(sqlmock.NewRows(columns).AddRow(7, 1, "UserService.Update", nil,
+					[]byte(`{"name":"user"}`), []byte(`{"name":"new name"}`), nil, nil, created, 7))
+
+			notifier.Notify("user_events")
+
+			event, err = stream.Recv()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(event).To(Equal(&pkg.UserEvent{
+				Type:     pkg.UserEvent_UPDATED,
+				User:     &pkg.User{Id: 1, Name: "new name", UpdateTime: &timestamp.Timestamp{Seconds: created.Unix()}},
+				Revision: 7,
+			}))
+
+			Eventually(mock.ExpectationsWereMet).Should(Succeed())
+		})
+
+		It("sends deleted user from audit log even if user is purged", func() {
+			expectAssign()
+			mock.ExpectQuery(qEvents).WithArgs(5, 1000).
+				WillReturnRows(sqlmock.NewRows(columns).AddRow(6, 1, "UserService.Delete", nil,
+					[]byte(`{"deleted_at":null}`), []byte(`{"deleted_at":"2020-01-02T03:04:05Z"}`), nil, nil, created, 6))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			stream, err := client.WatchUsers(ctx, &pkg.WatchUsersRequest{Revision: 5})
+			Expect(err).NotTo(HaveOccurred())
+
+			event, err := stream.Recv()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(event).To(Equal(&pkg.UserEvent{
+				Type:     pkg.UserEvent_DELETED,
+				User:     &pkg.User{Id: 1, DeleteTime: &timestamp.Timestamp{Seconds: created.Unix()}, UpdateTime: &timestamp.Timestamp{Seconds: created.Unix()}},
+				Revision: 6,
+			}))
+		})
+
+		It("sends events in order of revisions given after commit", func() {
+			// event 8 is committed before event 7, so it gets lower revision
+			expectAssign()
+			mock.ExpectQuery(qEvents).WithArgs(5, 1000).
+				WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(8, 2, "UserService.Update", nil, nil, nil, nil, nil, created, 6).
+					AddRow(7, 1, "UserService.Update", nil, nil, nil, nil, nil, created, 7))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			stream, err := client.WatchUsers(ctx, &pkg.WatchUsersRequest{Revision: 5})
+			Expect(err).NotTo(HaveOccurred())
+
+			var ids, revisions []int64
+			for len(revisions) < 2 {
+				event, err := stream.Recv()
+				Expect(err).NotTo(HaveOccurred())
+				ids, revisions = append(ids, event.GetUser().GetId()), append(revisions, event.GetRevision())
+			}
+
+			Expect(ids).To(Equal([]int64{2, 1}))
+			Expect(revisions).To(Equal([]int64{6, 7}))
+		})
+
+		It("sends only new events if revision is not given", func() {
+			mock.ExpectQuery(`^SELECT coalesce\(max\("revision"\), 0\) FROM "user_event_revisions"$`).
+				WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(9))
+			expectAssign()
+			mock.ExpectQuery(qEvents).WithArgs(9, 1000).WillReturnRows(sqlmock.NewRows(columns))
+
+			ctx, cancel := context.WithCancel(context.Background())
+
+			stream, err := client.WatchUsers(ctx, &pkg.WatchUsersRequest{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(mock.ExpectationsWereMet).Should(Succeed())
+			cancel()
+
+			_, err = stream.Recv()
+			Expect(status.Code(err)).To(Equal(codes.Canceled))
+		})
+
+		It("gives Internal error if cannot get events", func() {
+			expectAssign()
+			mock.ExpectQuery(qEvents).WithArgs(5, 1000).WillReturnError(errors.New("some error"))
+
+			stream, err := client.WatchUsers(context.Background(), &pkg.WatchUsersRequest{Revision: 5})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = stream.Recv()
+			Expect(err).To(HaveOccurred())
+
+			grpcStatus, ok := status.FromError(err)
+			Expect(ok).To(BeTrue())
+			Expect(grpcStatus.Code()).To(Equal(codes.Internal))
+			Expect(grpcStatus.Message()).To(Equal("UserService.WatchUsers: bind failed to execute query: some error"))
+		})
+
+		It("gives Internal error if cannot give revisions to events", func() {
+			mock.ExpectBegin()
+			mock.ExpectExec(`^SELECT pg_advisory_xact_lock\(\$1\)$`).WillReturnError(errors.New("some error"))
+			mock.ExpectRollback()
+
+			stream, err := client.WatchUsers(context.Background(), &pkg.WatchUsersRequest{Revision: 5})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = stream.Recv()
+			Expect(status.Code(err)).To(Equal(codes.Internal))
+			Expect(status.Convert(err).Message()).To(Equal("UserService.WatchUsers: some error"))
+		})
+	})
+
+	Describe("UserService on in-memory repository", func() {
+		var service *internal.UserService
+		ctx := context.Background()
+
+		BeforeEach(func() {
+			service = &internal.UserService{Users: internal.NewMemoryUserRepository()}
+		})
+
+		create := func(name, email string) int64 {
+			res, err := service.Create(ctx, &pkg.CreateRequest{User: &pkg.User{Name: name, Email: email}})
+			Expect(err).NotTo(HaveOccurred())
+			return res.GetId()
+		}
+
+		It("can create and read user", func() {
+			id := create("user", "user@example.com")
+
+			res, err := service.Read(ctx, &pkg.ReadRequest{Id: id})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.GetUser().GetName()).To(Equal("user"))
+			Expect(res.GetUser().GetEmail()).To(Equal("user@example.com"))
+			Expect(res.GetUser().GetEtag()).To(Equal("1"))
+			Expect(res.GetUser().GetCreateTime()).NotTo(BeNil())
+		})
+
+		It("gives AlreadyExists error if email is taken in other case", func() {
+			create("user", "user@example.com")
+
+			_, err := service.Create(ctx, &pkg.CreateRequest{User: &pkg.User{Name: "other", Email: "USER@example.com"}})
+
+			Expect(status.Code(err)).To(Equal(codes.AlreadyExists))
+		})
+
+		It("rolls back batch if one of users cannot be created", func() {
+			create("user", "user@example.com")
+
+			_, err := service.BatchCreateUsers(ctx, &pkg.BatchCreateUsersRequest{Users: []*pkg.User{
+				{Name: "user1", Email: "user1@example.com"},
+				{Name: "user2", Email: "user@example.com"},
+			}})
+			Expect(status.Code(err)).To(Equal(codes.AlreadyExists))
+
+			res, err := service.ReadAll(ctx, &pkg.ReadAllRequest{IncludeTotal: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.GetTotal()).To(Equal(int32(1)))
+
+			events, err := service.ListUserAuditEvents(ctx, &pkg.ListUserAuditEventsRequest{Id: 2})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events.GetEvents()).To(BeEmpty())
+		})
+
+		It("imports users in batches skipping duplicates by email", func() {
+			create("user", "user@example.com")
+
+			imp := internal.NewUserImport(false)
+			Expect(service.ImportBatch(ctx, imp, []*pkg.ImportUsersRequest_Row{
+				{Name: "user1", Email: "user1@example.com"},
+				{Name: "user2", Email: "User@example.com"},
+			})).To(Succeed())
+			Expect(service.ImportBatch(ctx, imp, []*pkg.ImportUsersRequest_Row{
+				{Name: "user3", Email: "user1@EXAMPLE.com"},
+				{Name: "user4", Email: "user4@example.com"},
+			})).To(Succeed())
+
+			Expect(imp.Result().GetInserted()).To(Equal(int32(2)))
+			Expect(imp.Result().GetSkipped()).To(Equal(int32(2)))
+
+			var exported []string
+			Expect(service.Export(ctx, &pkg.ExportUsersRequest{}, func(user *pkg.User) error {
+				exported = append(exported, user.GetEmail())
+				return nil
+			})).To(Succeed())
+			Expect(exported).To(Equal([]string{"user@example.com", "user1@example.com", "user4@example.com"}))
+		})
+
+		It("updates user with matching etag only", func() {
+			id := create("user", "user@example.com")
+			fields := &field_mask.FieldMask{Paths: []string{"name"}}
+
+			_, err := service.Update(ctx, &pkg.UpdateRequest{Id: id, User: &pkg.User{Name: "new"}, Fields: fields, Etag: "1"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = service.Update(ctx, &pkg.UpdateRequest{Id: id, User: &pkg.User{Name: "newer"}, Fields: fields, Etag: "1"})
+			Expect(status.Code(err)).To(Equal(codes.Aborted))
+
+			res, err := service.Read(ctx, &pkg.ReadRequest{Id: id})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.GetUser().GetName()).To(Equal("new"))
+			Expect(res.GetUser().GetEmail()).To(Equal("user@example.com"))
+			Expect(res.GetUser().GetEtag()).To(Equal("2"))
+		})
+
+		It("deletes and undeletes user", func() {
+			id := create("user", "user@example.com")
+
+			_, err := service.Delete(ctx, &pkg.DeleteRequest{Id: id})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = service.Read(ctx, &pkg.ReadRequest{Id: id})
+			Expect(status.Code(err)).To(Equal(codes.NotFound))
+
+			res, err := service.Read(ctx, &pkg.ReadRequest{Id: id, ShowDeleted: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.GetUser().GetDeleteTime()).NotTo(BeNil())
+
+			_, err = service.UndeleteUser(ctx, &pkg.UndeleteUserRequest{Id: id})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = service.UndeleteUser(ctx, &pkg.UndeleteUserRequest{Id: id})
+			Expect(status.Code(err)).To(Equal(codes.FailedPrecondition))
+
+			events, err := service.ListUserAuditEvents(ctx, &pkg.ListUserAuditEventsRequest{Id: id})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events.GetEvents()).To(HaveLen(3))
+			Expect(events.GetEvents()[2].GetMethod()).To(Equal("UserService.UndeleteUser"))
+		})
+
+		It("filters, orders and pages users", func() {
+			create("bob", "bob@corp.com")
+			create("alice", "alice@corp.com")
+			create("carol", "carol@example.com")
+			create("dave", "dave@corp.com")
+
+			req := &pkg.ReadAllRequest{Filter: `email:"*@corp.com"`, OrderBy: "name desc", Limit: 2}
+			res, err := service.ReadAll(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.GetUsers()).To(HaveLen(2))
+			Expect(res.GetUsers()[0].GetName()).To(Equal("dave"))
+			Expect(res.GetUsers()[1].GetName()).To(Equal("bob"))
+			Expect(res.GetNextPageToken()).NotTo(BeEmpty())
+
+			req.PageToken = res.GetNextPageToken()
+			res, err = service.ReadAll(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.GetUsers()).To(HaveLen(1))
+			Expect(res.GetUsers()[0].GetName()).To(Equal("alice"))
+			Expect(res.GetNextPageToken()).To(BeEmpty())
+		})
 	})
 })
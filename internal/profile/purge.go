@@ -0,0 +1,67 @@
+package profile
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.uber.org/fx"
+
+	"github.com/reviz0r/golang-layout/pkg/config"
+)
+
+// PurgeModule register background job which purges deleted users
+var PurgeModule = fx.Invoke(RunPurgeJob)
+
+// RunPurgeJob permanently deletes users which were deleted more than profile.purge_after ago.
+// Job runs every profile.purge_interval, it is disabled if profile.purge_after is 0.
+func RunPurgeJob(lc fx.Lifecycle, config config.ProfileConfig, users UserRepository, logger *logrus.Entry) {
+	purgeAfter := config.PurgeAfter
+	interval := config.PurgeInterval
+	logger = logger.WithField("job", "purge_users")
+
+	if purgeAfter <= 0 || interval <= 0 {
+		logger.Info("purge of deleted users is disabled")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+
+				for {
+					rows, err := users.Purge(ctx, time.Now().Add(-purgeAfter))
+					if err != nil {
+						logger.WithError(err).Error("cannot purge deleted users")
+					} else if rows != 0 {
+						logger.WithField("users", rows).Info("deleted users are purged")
+					}
+
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+					}
+				}
+			}()
+			return nil
+		},
+
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-stopCtx.Done():
+				return stopCtx.Err()
+			}
+		},
+	})
+}
@@ -0,0 +1,27 @@
+package profile
+
+import (
+	"context"
+	"strconv"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/reviz0r/golang-layout/pkg/db"
+)
+
+// readPrimaryMetadata is request metadata forcing reads from primary database, e.g. to read own writes.
+// grpc-gateway forwards it from X-Read-Primary header.
+const readPrimaryMetadata = "x-read-primary"
+
+// readContext allows reads of ctx to go to replica unless request forces primary
+func readContext(ctx context.Context) context.Context {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(readPrimaryMetadata); len(values) != 0 {
+			if primary, err := strconv.ParseBool(values[0]); err == nil && primary {
+				return ctx
+			}
+		}
+	}
+
+	return db.WithReplicaRead(ctx)
+}
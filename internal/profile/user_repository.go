@@ -0,0 +1,108 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/reviz0r/golang-layout/internal/profile/models"
+)
+
+var (
+	// ErrUserNotFound is given when user does not exist or it is deleted
+	ErrUserNotFound = errors.New("user not found")
+	// ErrUserVersionMismatch is given when user exists but its version differs from expected one
+	ErrUserVersionMismatch = errors.New("etag does not match, user was changed")
+	// ErrUserNotDeleted is given by Undelete when user exists but it is not deleted
+	ErrUserNotDeleted = errors.New("user is not deleted")
+)
+
+// UserExistsError is given when user conflicts with another one by unique field
+type UserExistsError struct {
+	// Field is proto field of User, e.g. "email"
+	Field string
+	Err   error
+}
+
+func (e *UserExistsError) Error() string {
+	return "user with this " + e.Field + " already exists"
+}
+
+func (e *UserExistsError) Unwrap() error {
+	return e.Err
+}
+
+// UserRepository stores users and their audit log.
+// Calls made with context given to WithinTx function are executed in that transaction.
+// Get, BatchGet, List and Count may read from replica if context is marked by db.WithReplicaRead.
+type UserRepository interface {
+	// WithinTx runs fn in transaction, it is committed if fn returns nil and rolled back otherwise.
+	// Nested calls join the outer transaction.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+
+	// Create inserts user and sets its id, version and timestamps
+	Create(ctx context.Context, user *models.User) error
+	// Get gives user with given id including deleted one, columns limit loaded columns (nil means all)
+	Get(ctx context.Context, id int64, columns []string) (*models.User, error)
+	// BatchGet gives existing users with given ids in any order including deleted ones
+	BatchGet(ctx context.Context, ids []int64) ([]*models.User, error)
+	// List gives users selected by query
+	List(ctx context.Context, q UserQuery) ([]*models.User, error)
+	// Count gives number of users matching filter of query, paging of query is ignored
+	Count(ctx context.Context, q UserQuery) (int64, error)
+	// ExistingEmails gives which of lower case emails are taken by not deleted users
+	ExistingEmails(ctx context.Context, emails []string) (map[string]bool, error)
+	// Update sets columns of not deleted user to values of user with UpdatedAt and UpdatedBy,
+	// version 0 matches any version. It gives old values of the columns.
+	Update(ctx context.Context, user *models.User, version int64, columns []string) (*models.User, error)
+	// Delete marks not deleted user as deleted at given time, version 0 matches any version
+	Delete(ctx context.Context, id, version int64, at time.Time) error
+	// BatchDelete marks all users as deleted at given time, it fails if some of them is not found
+	BatchDelete(ctx context.Context, ids []int64, at time.Time) error
+	// Undelete restores deleted user, it gives time the user was deleted at
+	Undelete(ctx context.Context, id int64) (time.Time, error)
+	// Purge permanently deletes users which were deleted before the given time
+	Purge(ctx context.Context, before time.Time) (int64, error)
+
+	// CreateAuditEvent records change of user, it sets id and creation time of the event
+	CreateAuditEvent(ctx context.Context, event *models.UserAuditEvent) error
+	// ListAuditEvents gives audit events selected by query ordered by id
+	ListAuditEvents(ctx context.Context, q AuditEventQuery) ([]*models.UserAuditEvent, error)
+	// AssignEventRevisions gives next revisions to committed audit events in order of ids, so an event
+	// never gets revision lower than revisions already given
+	AssignEventRevisions(ctx context.Context) error
+	// ListRevisedAuditEvents gives audit events with revision greater than after ordered by revision
+	ListRevisedAuditEvents(ctx context.Context, after int64, limit int) ([]*RevisedAuditEvent, error)
+	// LastEventRevision gives the greatest given revision or 0 if there are no revisions
+	LastEventRevision(ctx context.Context) (int64, error)
+}
+
+// UserQuery selects users for List and Count
+type UserQuery struct {
+	// Filter is nil for all users
+	Filter      *userFilter
+	ShowDeleted bool
+
+	// Columns limit loaded columns, nil means all
+	Columns []string
+	OrderBy orderBy
+	// After holds values of OrderBy fields of the row users are selected after (keyset paging)
+	After  []string
+	Offset int
+	Limit  int
+}
+
+// RevisedAuditEvent is audit event with its revision of WatchUsers stream
+type RevisedAuditEvent struct {
+	models.UserAuditEvent `boil:",bind"`
+	Revision              int64 `boil:"revision"`
+}
+
+// AuditEventQuery selects audit events for ListAuditEvents
+type AuditEventQuery struct {
+	// UserID is 0 for events of all users
+	UserID int64
+	// AfterID selects events with greater id
+	AfterID int64
+	Limit   int
+}
@@ -0,0 +1,371 @@
+package profile
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/volatiletech/null"
+
+	"github.com/reviz0r/golang-layout/internal/profile/models"
+)
+
+// memoryUserRepository is UserRepository keeping users in memory, it is intended for tests.
+// Transactions are serialized: WithinTx holds the lock until fn returns and restores data on error.
+type memoryUserRepository struct {
+	mu sync.Mutex
+
+	users  map[int64]*models.User
+	events []*models.UserAuditEvent
+
+	lastUserID   int64
+	lastRevision int64
+}
+
+// NewMemoryUserRepository gives empty in-memory repository
+func NewMemoryUserRepository() UserRepository {
+	return &memoryUserRepository{users: make(map[int64]*models.User)}
+}
+
+// memoryTxKey is context key of transaction started by WithinTx, its value is the repository
+type memoryTxKey struct{}
+
+func (r *memoryUserRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(memoryTxKey{}) == r {
+		return fn(ctx)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	users := make(map[int64]*models.User, len(r.users))
+	for id, user := range r.users {
+		users[id] = user
+	}
+	events, lastUserID, lastRevision := r.events, r.lastUserID, r.lastRevision
+
+	if err := fn(context.WithValue(ctx, memoryTxKey{}, r)); err != nil {
+		// stored users are never modified in place, so shallow copy is enough to roll back
+		r.users, r.events, r.lastUserID, r.lastRevision = users, events[:len(events):len(events)], lastUserID, lastRevision
+		return err
+	}
+
+	return nil
+}
+
+// lock locks repository unless ctx is in transaction which already holds the lock
+func (r *memoryUserRepository) lock(ctx context.Context) func() {
+	if ctx.Value(memoryTxKey{}) == r {
+		return func() {}
+	}
+
+	r.mu.Lock()
+	return r.mu.Unlock
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user *models.User) error {
+	defer r.lock(ctx)()
+
+	if err := r.checkUnique(user); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	r.lastUserID++
+	user.ID = r.lastUserID
+	user.Version = 1
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	r.users[user.ID] = copyUser(user)
+	return nil
+}
+
+func (r *memoryUserRepository) Get(ctx context.Context, id int64, columns []string) (*models.User, error) {
+	defer r.lock(ctx)()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+
+	return copyUser(user), nil
+}
+
+func (r *memoryUserRepository) BatchGet(ctx context.Context, ids []int64) ([]*models.User, error) {
+	defer r.lock(ctx)()
+
+	var users []*models.User
+	for _, id := range ids {
+		if user, ok := r.users[id]; ok {
+			users = append(users, copyUser(user))
+		}
+	}
+
+	return users, nil
+}
+
+func (r *memoryUserRepository) List(ctx context.Context, q UserQuery) ([]*models.User, error) {
+	defer r.lock(ctx)()
+
+	users := r.match(q)
+	sort.Slice(users, func(i, j int) bool {
+		return q.OrderBy.compare(users[i], q.OrderBy.keys(users[j])) < 0
+	})
+
+	if q.After != nil {
+		start := sort.Search(len(users), func(i int) bool {
+			return q.OrderBy.compare(users[i], q.After) > 0
+		})
+		users = users[start:]
+	} else if q.Offset < len(users) {
+		users = users[q.Offset:]
+	} else {
+		users = nil
+	}
+
+	if q.Limit < len(users) {
+		users = users[:q.Limit]
+	}
+
+	for i, user := range users {
+		users[i] = copyUser(user)
+	}
+
+	return users, nil
+}
+
+func (r *memoryUserRepository) Count(ctx context.Context, q UserQuery) (int64, error) {
+	defer r.lock(ctx)()
+
+	return int64(len(r.match(q))), nil
+}
+
+func (r *memoryUserRepository) ExistingEmails(ctx context.Context, emails []string) (map[string]bool, error) {
+	defer r.lock(ctx)()
+
+	existing := make(map[string]bool)
+	for _, email := range emails {
+		for _, user := range r.users {
+			if !user.DeletedAt.Valid && strings.ToLower(user.Email) == email {
+				existing[email] = true
+			}
+		}
+	}
+
+	return existing, nil
+}
+
+// match gives stored users matching filter of the query
+func (r *memoryUserRepository) match(q UserQuery) []*models.User {
+	var users []*models.User
+	for _, user := range r.users {
+		if user.DeletedAt.Valid && !q.ShowDeleted {
+			continue
+		}
+		if q.Filter != nil && !q.Filter.match(user) {
+			continue
+		}
+		users = append(users, user)
+	}
+
+	return users
+}
+
+func (r *memoryUserRepository) Update(ctx context.Context, user *models.User, version int64, columns []string) (*models.User, error) {
+	defer r.lock(ctx)()
+
+	stored, err := r.active(user.ID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := copyUser(stored)
+	for _, column := range columns {
+		switch column {
+		case models.UserColumns.Name:
+			updated.Name = user.Name
+		case models.UserColumns.Email:
+			updated.Email = user.Email
+		}
+	}
+	updated.UpdatedAt = user.UpdatedAt
+	updated.UpdatedBy = user.UpdatedBy
+
+	if err := r.checkUnique(updated); err != nil {
+		return nil, err
+	}
+
+	r.save(updated)
+	return copyUser(stored), nil
+}
+
+func (r *memoryUserRepository) Delete(ctx context.Context, id, version int64, at time.Time) error {
+	defer r.lock(ctx)()
+
+	stored, err := r.active(id, version)
+	if err != nil {
+		return err
+	}
+
+	deleted := copyUser(stored)
+	deleted.DeletedAt = null.TimeFrom(at)
+	r.save(deleted)
+	return nil
+}
+
+func (r *memoryUserRepository) BatchDelete(ctx context.Context, ids []int64, at time.Time) error {
+	defer r.lock(ctx)()
+
+	for _, id := range ids {
+		if _, err := r.active(id, 0); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range ids {
+		deleted := copyUser(r.users[id])
+		deleted.DeletedAt = null.TimeFrom(at)
+		r.save(deleted)
+	}
+
+	return nil
+}
+
+func (r *memoryUserRepository) Undelete(ctx context.Context, id int64) (time.Time, error) {
+	defer r.lock(ctx)()
+
+	stored, ok := r.users[id]
+	if !ok {
+		return time.Time{}, ErrUserNotFound
+	}
+	if !stored.DeletedAt.Valid {
+		return time.Time{}, ErrUserNotDeleted
+	}
+
+	restored := copyUser(stored)
+	restored.DeletedAt = null.Time{}
+	if err := r.checkUnique(restored); err != nil {
+		return time.Time{}, err
+	}
+
+	r.save(restored)
+	return stored.DeletedAt.Time, nil
+}
+
+func (r *memoryUserRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	defer r.lock(ctx)()
+
+	var purged int64
+	for id, user := range r.users {
+		if user.DeletedAt.Valid && user.DeletedAt.Time.Before(before) {
+			delete(r.users, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+func (r *memoryUserRepository) CreateAuditEvent(ctx context.Context, event *models.UserAuditEvent) error {
+	defer r.lock(ctx)()
+
+	event.ID = int64(len(r.events)) + 1
+	event.CreatedAt = time.Now()
+
+	copied := *event
+	r.events = append(r.events, &copied)
+	return nil
+}
+
+func (r *memoryUserRepository) ListAuditEvents(ctx context.Context, q AuditEventQuery) ([]*models.UserAuditEvent, error) {
+	defer r.lock(ctx)()
+
+	var events []*models.UserAuditEvent
+	for _, event := range r.events {
+		if len(events) == q.Limit {
+			break
+		}
+		if event.ID <= q.AfterID || (q.UserID != 0 && event.UserID != q.UserID) {
+			continue
+		}
+
+		copied := *event
+		events = append(events, &copied)
+	}
+
+	return events, nil
+}
+
+// AssignEventRevisions gives ids as revisions, events are committed in order of ids as transactions are serialized
+func (r *memoryUserRepository) AssignEventRevisions(ctx context.Context) error {
+	defer r.lock(ctx)()
+
+	r.lastRevision = int64(len(r.events))
+	return nil
+}
+
+func (r *memoryUserRepository) ListRevisedAuditEvents(ctx context.Context, after int64, limit int) ([]*RevisedAuditEvent, error) {
+	defer r.lock(ctx)()
+
+	var events []*RevisedAuditEvent
+	for _, event := range r.events {
+		if len(events) == limit || event.ID > r.lastRevision {
+			break
+		}
+		if event.ID <= after {
+			continue
+		}
+
+		events = append(events, &RevisedAuditEvent{UserAuditEvent: *event, Revision: event.ID})
+	}
+
+	return events, nil
+}
+
+func (r *memoryUserRepository) LastEventRevision(ctx context.Context) (int64, error) {
+	defer r.lock(ctx)()
+
+	return r.lastRevision, nil
+}
+
+// active gives not deleted user with given id and version (0 matches any version)
+func (r *memoryUserRepository) active(id, version int64) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return nil, ErrUserNotFound
+	}
+	if version != 0 && user.Version != version {
+		return nil, ErrUserVersionMismatch
+	}
+
+	return user, nil
+}
+
+// save replaces stored user incrementing its version as the postgres trigger does
+func (r *memoryUserRepository) save(user *models.User) {
+	user.Version++
+	r.users[user.ID] = user
+}
+
+// checkUnique mirrors unique index on lower(email) of not deleted users
+func (r *memoryUserRepository) checkUnique(user *models.User) error {
+	if user.DeletedAt.Valid {
+		return nil
+	}
+
+	for _, other := range r.users {
+		if other.ID != user.ID && !other.DeletedAt.Valid && strings.EqualFold(other.Email, user.Email) {
+			return &UserExistsError{Field: "email"}
+		}
+	}
+
+	return nil
+}
+
+func copyUser(user *models.User) *models.User {
+	copied := *user
+	return &copied
+}
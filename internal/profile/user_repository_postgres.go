@@ -0,0 +1,370 @@
+package profile
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/volatiletech/null"
+	"github.com/volatiletech/sqlboiler/boil"
+	"github.com/volatiletech/sqlboiler/queries"
+	"github.com/volatiletech/sqlboiler/queries/qm"
+
+	"github.com/reviz0r/golang-layout/internal/profile/models"
+	"github.com/reviz0r/golang-layout/pkg/db"
+	"github.com/reviz0r/golang-layout/pkg/outbox"
+)
+
+// pgUniqueViolation is postgres code of unique_violation error
+const pgUniqueViolation = "23505"
+
+// eventRevisionsLockKey is key of advisory lock held while revisions are given to audit events
+const eventRevisionsLockKey int64 = 7264018395
+
+// userUniqueFields maps unique constraints of users table to proto fields of User
+var userUniqueFields = map[string]string{
+	"users_email_lower_key": "email",
+}
+
+// postgresUserRepository is UserRepository on generated sqlboiler models
+type postgresUserRepository struct {
+	tx     db.TxManager
+	router db.Router
+}
+
+// NewPostgresUserRepository gives repository storing users in postgres, reads go through router
+func NewPostgresUserRepository(tx db.TxManager, router db.Router) UserRepository {
+	return &postgresUserRepository{tx: tx, router: router}
+}
+
+func (r *postgresUserRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.tx.WithinTx(ctx, nil, fn)
+}
+
+// exec gives transaction of ctx or the database if there is no one
+func (r *postgresUserRepository) exec(ctx context.Context) boil.ContextExecutor {
+	return r.tx.Executor(ctx)
+}
+
+// read gives executor for read-only query, it is replica if ctx allows it
+func (r *postgresUserRepository) read(ctx context.Context) boil.ContextExecutor {
+	return r.router.Reader(ctx)
+}
+
+func (r *postgresUserRepository) Create(ctx context.Context, user *models.User) error {
+	return uniqueViolation(user.Insert(ctx, r.exec(ctx), boil.Infer()))
+}
+
+func (r *postgresUserRepository) Get(ctx context.Context, id int64, columns []string) (*models.User, error) {
+	user, err := models.FindUser(ctx, r.read(ctx), id, columns...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+
+	return user, err
+}
+
+func (r *postgresUserRepository) BatchGet(ctx context.Context, ids []int64) ([]*models.User, error) {
+	users := userSliceOf(ids)
+	if err := users.ReloadAll(ctx, r.read(ctx)); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *postgresUserRepository) List(ctx context.Context, q UserQuery) ([]*models.User, error) {
+	mods := append([]qm.QueryMod{
+		qm.Select(q.Columns...),
+		qm.OrderBy(q.OrderBy.clause()),
+		qm.Limit(q.Limit),
+	}, userFilterMods(q)...)
+
+	switch {
+	case q.After == nil:
+		mods = append(mods, qm.Offset(q.Offset))
+
+	case len(q.OrderBy) == 1:
+		// ordered by id only
+		id, err := strconv.ParseInt(q.After[0], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if q.OrderBy[0].desc {
+			mods = append(mods, models.UserWhere.ID.LT(id))
+		} else {
+			mods = append(mods, models.UserWhere.ID.GT(id))
+		}
+
+	default:
+		clause, args := q.OrderBy.after(q.After)
+		mods = append(mods, qm.Where(clause, args...))
+	}
+
+	return models.Users(mods...).All(ctx, r.read(ctx))
+}
+
+func (r *postgresUserRepository) Count(ctx context.Context, q UserQuery) (int64, error) {
+	return models.Users(userFilterMods(q)...).Count(ctx, r.read(ctx))
+}
+
+func (r *postgresUserRepository) ExistingEmails(ctx context.Context, emails []string) (map[string]bool, error) {
+	users, err := models.Users(
+		qm.Select(`lower("email") AS "email"`),
+		qm.Where(`lower("email") = ANY(?)`, pq.Array(emails)),
+		models.UserWhere.DeletedAt.IsNull(),
+	).All(ctx, r.exec(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(users))
+	for _, user := range users {
+		existing[user.Email] = true
+	}
+
+	return existing, nil
+}
+
+func userFilterMods(q UserQuery) []qm.QueryMod {
+	var mods []qm.QueryMod
+	if !q.ShowDeleted {
+		mods = append(mods, models.UserWhere.DeletedAt.IsNull())
+	}
+	if q.Filter != nil {
+		mods = append(mods, q.Filter.mod)
+	}
+
+	return mods
+}
+
+func (r *postgresUserRepository) Update(ctx context.Context, user *models.User, version int64, columns []string) (*models.User, error) {
+	exec := r.exec(ctx)
+
+	// lock user to keep its old values
+	old, err := models.Users(append(userWhereActive(user.ID, version), qm.Select(columns...), qm.For("UPDATE"))...).
+		One(ctx, exec)
+	if err == sql.ErrNoRows {
+		return nil, notFoundOrVersionMismatch(ctx, exec, user.ID, version)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values := userColumnValues(user, columns)
+	values[models.UserColumns.UpdatedAt] = user.UpdatedAt
+	values[models.UserColumns.UpdatedBy] = user.UpdatedBy
+
+	// version is incremented by trigger
+	rows, err := models.Users(models.UserWhere.ID.EQ(user.ID)).UpdateAll(ctx, exec, values)
+	if err != nil {
+		return nil, uniqueViolation(err)
+	}
+	if rows != 1 {
+		return nil, fmt.Errorf("expect updating 1 row, but updated %d rows", rows)
+	}
+
+	return old, nil
+}
+
+func (r *postgresUserRepository) Delete(ctx context.Context, id, version int64, at time.Time) error {
+	exec := r.exec(ctx)
+
+	deleted := models.M{models.UserColumns.DeletedAt: at}
+	rows, err := models.Users(userWhereActive(id, version)...).UpdateAll(ctx, exec, deleted)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return notFoundOrVersionMismatch(ctx, exec, id, version)
+	}
+	if rows > 1 {
+		return fmt.Errorf("expect deleting 1 row, but deleted %d rows", rows)
+	}
+
+	return nil
+}
+
+func (r *postgresUserRepository) BatchDelete(ctx context.Context, ids []int64, at time.Time) error {
+	deleted := models.M{models.UserColumns.DeletedAt: at}
+	rows, err := models.Users(models.UserWhere.ID.IN(ids), models.UserWhere.DeletedAt.IsNull()).
+		UpdateAll(ctx, r.exec(ctx), deleted)
+	if err != nil {
+		return err
+	}
+	if rows < int64(len(ids)) {
+		return ErrUserNotFound
+	}
+	if rows > int64(len(ids)) {
+		return fmt.Errorf("expect deleting %d rows, but deleted %d rows", len(ids), rows)
+	}
+
+	return nil
+}
+
+func (r *postgresUserRepository) Undelete(ctx context.Context, id int64) (time.Time, error) {
+	exec := r.exec(ctx)
+
+	// lock user to keep its deletion time
+	old, err := models.Users(
+		models.UserWhere.ID.EQ(id),
+		models.UserWhere.DeletedAt.IsNotNull(),
+		qm.Select(models.UserColumns.DeletedAt),
+		qm.For("UPDATE"),
+	).One(ctx, exec)
+	if err == sql.ErrNoRows {
+		return time.Time{}, notFoundOrNotDeleted(ctx, exec, id)
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	restored := models.M{models.UserColumns.DeletedAt: nil}
+	rows, err := models.Users(models.UserWhere.ID.EQ(id)).UpdateAll(ctx, exec, restored)
+	if err != nil {
+		return time.Time{}, uniqueViolation(err)
+	}
+	if rows != 1 {
+		return time.Time{}, fmt.Errorf("expect updating 1 row, but updated %d rows", rows)
+	}
+
+	return old.DeletedAt.Time, nil
+}
+
+func (r *postgresUserRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return models.Users(models.UserWhere.DeletedAt.LT(null.TimeFrom(before))).DeleteAll(ctx, r.exec(ctx))
+}
+
+// CreateAuditEvent puts the event to outbox too, so it is published if the change is committed
+func (r *postgresUserRepository) CreateAuditEvent(ctx context.Context, event *models.UserAuditEvent) error {
+	exec := r.exec(ctx)
+
+	if err := event.Insert(ctx, exec, boil.Infer()); err != nil {
+		return err
+	}
+
+	payload, err := auditEventPayload(event)
+	if err != nil {
+		return err
+	}
+
+	return outbox.Write(ctx, exec, userEventsTopic, strconv.FormatInt(event.UserID, 10), payload)
+}
+
+func (r *postgresUserRepository) ListAuditEvents(ctx context.Context, q AuditEventQuery) ([]*models.UserAuditEvent, error) {
+	var mods []qm.QueryMod
+	if q.UserID != 0 {
+		mods = append(mods, models.UserAuditEventWhere.UserID.EQ(q.UserID))
+	}
+	if q.AfterID != 0 {
+		mods = append(mods, models.UserAuditEventWhere.ID.GT(q.AfterID))
+	}
+	mods = append(mods, qm.OrderBy(models.UserAuditEventColumns.ID), qm.Limit(q.Limit))
+
+	return models.UserAuditEvents(mods...).All(ctx, r.exec(ctx))
+}
+
+// AssignEventRevisions takes advisory lock, so revisions are given by one watcher at a time
+// and the statement sees revisions given by others
+func (r *postgresUserRepository) AssignEventRevisions(ctx context.Context) error {
+	return r.tx.WithinTx(ctx, nil, func(ctx context.Context) error {
+		exec := r.exec(ctx)
+
+		if _, err := exec.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, eventRevisionsLockKey); err != nil {
+			return err
+		}
+
+		_, err := exec.ExecContext(ctx, `UPDATE "user_event_revisions" AS r SET "revision" = p."revision"
+		FROM (SELECT "event_id", row_number() OVER (ORDER BY "event_id") +
+			(SELECT coalesce(max("revision"), 0) FROM "user_event_revisions") AS "revision"
+			FROM "user_event_revisions" WHERE "revision" IS NULL) AS p
+		WHERE r."event_id" = p."event_id"`)
+		return err
+	})
+}
+
+func (r *postgresUserRepository) ListRevisedAuditEvents(ctx context.Context, after int64, limit int) ([]*RevisedAuditEvent, error) {
+	var events []*RevisedAuditEvent
+	err := queries.Raw(`SELECT "e".*, "r"."revision" FROM "user_audit_events" AS "e"
+		JOIN "user_event_revisions" AS "r" ON "r"."event_id" = "e"."id"
+		WHERE "r"."revision" > $1 ORDER BY "r"."revision" LIMIT $2`, after, limit).Bind(ctx, r.exec(ctx), &events)
+
+	return events, err
+}
+
+func (r *postgresUserRepository) LastEventRevision(ctx context.Context) (int64, error) {
+	var revision int64
+	err := r.exec(ctx).QueryRowContext(ctx, `SELECT coalesce(max("revision"), 0) FROM "user_event_revisions"`).Scan(&revision)
+
+	return revision, err
+}
+
+// userWhereActive selects not deleted user by id, and by version if it is not 0
+func userWhereActive(id, version int64) []qm.QueryMod {
+	mods := []qm.QueryMod{models.UserWhere.ID.EQ(id), models.UserWhere.DeletedAt.IsNull()}
+	if version != 0 {
+		mods = append(mods, models.UserWhere.Version.EQ(version))
+	}
+
+	return mods
+}
+
+// notFoundOrVersionMismatch gives error for the case when no rows were affected by query with expected version:
+// either user does not exist or it was changed after it was read
+func notFoundOrVersionMismatch(ctx context.Context, exec boil.ContextExecutor, id, version int64) error {
+	if version == 0 {
+		return ErrUserNotFound
+	}
+
+	exists, err := models.Users(models.UserWhere.ID.EQ(id), models.UserWhere.DeletedAt.IsNull()).Exists(ctx, exec)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrUserNotFound
+	}
+
+	return ErrUserVersionMismatch
+}
+
+// notFoundOrNotDeleted gives error for the case when deleted user with given id was not found
+func notFoundOrNotDeleted(ctx context.Context, exec boil.ContextExecutor, id int64) error {
+	exists, err := models.UserExists(ctx, exec, id)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrUserNotFound
+	}
+
+	return ErrUserNotDeleted
+}
+
+// uniqueViolation gives UserExistsError if err is unique violation, otherwise err as is
+func uniqueViolation(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != pgUniqueViolation {
+		return err
+	}
+
+	field, ok := userUniqueFields[pqErr.Constraint]
+	if !ok {
+		field = pqErr.Constraint
+	}
+
+	return &UserExistsError{Field: field, Err: err}
+}
+
+// userSliceOf gives users with given ids only, they are used as keys for UserSlice helpers
+func userSliceOf(ids []int64) models.UserSlice {
+	users := make(models.UserSlice, len(ids))
+	for i, id := range ids {
+		users[i] = &models.User{ID: id}
+	}
+
+	return users
+}
@@ -0,0 +1,120 @@
+package profile
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/reviz0r/golang-layout/internal/profile/models"
+	"github.com/reviz0r/golang-layout/pkg/profile"
+)
+
+// userEventsChannel is postgres channel notified on every insert into user_audit_events
+const userEventsChannel = "user_events"
+
+// watchBatchSize limits number of audit events selected at once
+const watchBatchSize = 1000
+
+// userEventTypes maps methods recorded in audit log to types of events
+var userEventTypes = map[string]profile.UserEvent_Type{
+	"UserService.Create":           profile.UserEvent_CREATED,
+	"UserService.BatchCreateUsers": profile.UserEvent_CREATED,
+	"UserService.ImportUsers":      profile.UserEvent_CREATED,
+	"UserService.Update":           profile.UserEvent_UPDATED,
+	"UserService.UndeleteUser":     profile.UserEvent_UPDATED,
+	"UserService.Delete":           profile.UserEvent_DELETED,
+	"UserService.BatchDeleteUsers": profile.UserEvent_DELETED,
+}
+
+// WatchUsers .
+func (s *UserService) WatchUsers(in *profile.WatchUsersRequest, stream profile.UserService_WatchUsersServer) error {
+	ctx := stream.Context()
+
+	// subscribe before reading events, so events committed meanwhile are not missed
+	signals, cancel, err := s.Notifier.Subscribe(ctx, userEventsChannel)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "UserService.WatchUsers: %s", err.Error())
+	}
+	defer cancel()
+
+	revision := in.GetRevision()
+	if revision == 0 {
+		revision, err = s.Users.LastEventRevision(ctx)
+		if err != nil {
+			return status.Errorf(codes.Internal, "UserService.WatchUsers: %s", err.Error())
+		}
+	}
+
+	for {
+		if revision, err = s.sendUserEvents(stream, revision); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-signals:
+		}
+	}
+}
+
+// sendUserEvents gives revisions to committed events and sends all events after the revision, it gives
+// revision of the last sent event. Revisions are given after commit, so no event can appear before the sent ones.
+func (s *UserService) sendUserEvents(stream profile.UserService_WatchUsersServer, revision int64) (int64, error) {
+	ctx := stream.Context()
+
+	if err := s.Users.AssignEventRevisions(ctx); err != nil {
+		return revision, status.Errorf(codes.Internal, "UserService.WatchUsers: %s", err.Error())
+	}
+
+	for {
+		events, err := s.Users.ListRevisedAuditEvents(ctx, revision, watchBatchSize)
+		if err != nil {
+			return revision, status.Errorf(codes.Internal, "UserService.WatchUsers: %s", err.Error())
+		}
+
+		for _, event := range events {
+			pbEvent, err := userEventToProto(&event.UserAuditEvent, event.Revision)
+			if err != nil {
+				return revision, status.Errorf(codes.Internal, "UserService.WatchUsers: %s", err.Error())
+			}
+			if err := stream.Send(pbEvent); err != nil {
+				return revision, err
+			}
+
+			revision = event.Revision
+		}
+
+		if len(events) < watchBatchSize {
+			return revision, nil
+		}
+	}
+}
+
+// userEventToProto gives event with user as it is after the change: id, columns recorded in audit log,
+// time and actor of the change
+func userEventToProto(event *models.UserAuditEvent, revision int64) (*profile.UserEvent, error) {
+	user := &models.User{ID: event.UserID}
+	if event.After.Valid {
+		if err := json.Unmarshal(event.After.JSON, user); err != nil {
+			return nil, err
+		}
+	}
+
+	eventType := userEventTypes[event.Method]
+
+	pbUser := &profile.User{
+		Id:         user.ID,
+		Name:       user.Name,
+		Email:      user.Email,
+		DeleteTime: timeToProto(user.DeletedAt.Time),
+		UpdateTime: timeToProto(event.CreatedAt),
+		UpdatedBy:  event.Actor.String,
+	}
+	if eventType == profile.UserEvent_CREATED {
+		pbUser.CreateTime, pbUser.CreatedBy = pbUser.UpdateTime, pbUser.UpdatedBy
+	}
+
+	return &profile.UserEvent{Type: eventType, User: pbUser, Revision: revision}, nil
+}
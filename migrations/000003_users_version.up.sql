@@ -0,0 +1,11 @@
+ALTER TABLE "users" ADD COLUMN "version" bigint NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION "users_increment_version"() RETURNS trigger AS $$
+BEGIN
+  NEW."version" := OLD."version" + 1;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "users_increment_version" BEFORE UPDATE ON "users"
+  FOR EACH ROW EXECUTE PROCEDURE "users_increment_version"();
@@ -0,0 +1,7 @@
+DELETE FROM "users" WHERE "deleted_at" IS NOT NULL;
+
+DROP INDEX IF EXISTS "users_email_lower_key";
+CREATE UNIQUE INDEX IF NOT EXISTS "users_email_lower_key" ON "users" (lower("email"));
+
+DROP INDEX IF EXISTS "users_deleted_at_idx";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "deleted_at";
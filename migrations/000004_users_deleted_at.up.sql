@@ -0,0 +1,7 @@
+ALTER TABLE "users" ADD COLUMN "deleted_at" timestamptz;
+
+CREATE INDEX IF NOT EXISTS "users_deleted_at_idx" ON "users" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+
+-- email of deleted user can be taken by another one
+DROP INDEX IF EXISTS "users_email_lower_key";
+CREATE UNIQUE INDEX IF NOT EXISTS "users_email_lower_key" ON "users" (lower("email")) WHERE "deleted_at" IS NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE "users"
+  DROP COLUMN IF EXISTS "created_at",
+  DROP COLUMN IF EXISTS "updated_at",
+  DROP COLUMN IF EXISTS "created_by",
+  DROP COLUMN IF EXISTS "updated_by";
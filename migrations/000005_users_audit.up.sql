@@ -0,0 +1,5 @@
+ALTER TABLE "users"
+  ADD COLUMN "created_at" timestamptz NOT NULL DEFAULT now(),
+  ADD COLUMN "updated_at" timestamptz NOT NULL DEFAULT now(),
+  ADD COLUMN "created_by" text,
+  ADD COLUMN "updated_by" text;
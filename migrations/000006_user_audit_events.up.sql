@@ -0,0 +1,14 @@
+-- there is no foreign key to users, history is kept after user is purged
+CREATE TABLE IF NOT EXISTS "user_audit_events" (
+  "id"         bigserial PRIMARY KEY,
+  "user_id"    bigint NOT NULL,
+  "method"     text NOT NULL,
+  "actor"      text,
+  "before"     jsonb,
+  "after"      jsonb,
+  "request_id" text,
+  "trace_id"   text,
+  "created_at" timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS "user_audit_events_user_id_idx" ON "user_audit_events" ("user_id", "id");
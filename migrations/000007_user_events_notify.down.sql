@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS "user_audit_events_notify" ON "user_audit_events";
+DROP FUNCTION IF EXISTS "user_audit_events_notify"();
+
+DROP TRIGGER IF EXISTS "user_audit_events_revision" ON "user_audit_events";
+DROP FUNCTION IF EXISTS "user_audit_events_revision"();
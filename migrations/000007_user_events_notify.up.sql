@@ -0,0 +1,24 @@
+-- Ids of audit events are revisions of WatchUsers stream, so they must be committed in order:
+-- id is taken under transaction lock, otherwise a watcher could skip an event committed
+-- after the event with greater id.
+CREATE OR REPLACE FUNCTION "user_audit_events_revision"() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_advisory_xact_lock('user_audit_events'::regclass::oid::bigint);
+  NEW."id" := nextval(pg_get_serial_sequence('user_audit_events', 'id'));
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "user_audit_events_revision" BEFORE INSERT ON "user_audit_events"
+  FOR EACH ROW EXECUTE PROCEDURE "user_audit_events_revision"();
+
+-- notification is delivered to listeners on commit
+CREATE OR REPLACE FUNCTION "user_audit_events_notify"() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('user_events', NEW."id"::text);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "user_audit_events_notify" AFTER INSERT ON "user_audit_events"
+  FOR EACH ROW EXECUTE PROCEDURE "user_audit_events_notify"();
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS "outbox_notify" ON "outbox";
+DROP FUNCTION IF EXISTS "outbox_notify"();
+DROP TABLE IF EXISTS "outbox";
@@ -0,0 +1,27 @@
+-- messages are written in transaction of the change and delivered by the relay at least once
+CREATE TABLE IF NOT EXISTS "outbox" (
+  "id"              bigserial PRIMARY KEY,
+  "topic"           text NOT NULL,
+  "key"             text NOT NULL,
+  "payload"         jsonb NOT NULL,
+  -- pending, delivered or dead
+  "status"          text NOT NULL DEFAULT 'pending',
+  "attempts"        integer NOT NULL DEFAULT 0,
+  "next_attempt_at" timestamptz NOT NULL DEFAULT now(),
+  "last_error"      text,
+  "created_at"      timestamptz NOT NULL DEFAULT now(),
+  "delivered_at"    timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS "outbox_pending_idx" ON "outbox" ("next_attempt_at", "id") WHERE "status" = 'pending';
+
+-- wakes up the relay on commit
+CREATE OR REPLACE FUNCTION "outbox_notify"() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('outbox', NEW."id"::text);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "outbox_notify" AFTER INSERT ON "outbox"
+  FOR EACH ROW EXECUTE PROCEDURE "outbox_notify"();
@@ -0,0 +1,10 @@
+CREATE OR REPLACE FUNCTION "user_audit_events_revision"() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_advisory_xact_lock('user_audit_events'::regclass::oid::bigint);
+  NEW."id" := nextval(pg_get_serial_sequence('user_audit_events', 'id'));
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "user_audit_events_revision" BEFORE INSERT ON "user_audit_events"
+  FOR EACH ROW EXECUTE PROCEDURE "user_audit_events_revision"();
@@ -0,0 +1,5 @@
+-- Ids of audit events are taken from their sequence without a global lock, so revisions of WatchUsers
+-- stream may be committed out of order and rolled back transactions leave gaps. Watchers wait for
+-- a missing revision for a while before skipping it.
+DROP TRIGGER IF EXISTS "user_audit_events_revision" ON "user_audit_events";
+DROP FUNCTION IF EXISTS "user_audit_events_revision"();
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS "user_audit_events_pending" ON "user_audit_events";
+DROP FUNCTION IF EXISTS "user_audit_events_pending"();
+DROP TABLE IF EXISTS "user_event_revisions";
@@ -0,0 +1,23 @@
+-- Revisions of WatchUsers stream are given to audit events after they are committed, so an event is
+-- never committed after events with greater revisions and revisions have no gaps. The trigger adds
+-- pending event without revision, watchers give revisions to pending events under advisory lock.
+-- Writers do not take the lock.
+CREATE TABLE IF NOT EXISTS "user_event_revisions" (
+  "event_id" bigint PRIMARY KEY REFERENCES "user_audit_events" ("id") ON DELETE CASCADE,
+  "revision" bigint UNIQUE
+);
+
+CREATE INDEX IF NOT EXISTS "user_event_revisions_pending_idx" ON "user_event_revisions" ("event_id") WHERE "revision" IS NULL;
+
+-- ids were revisions before, so watchers resume from them
+INSERT INTO "user_event_revisions" ("event_id", "revision") SELECT "id", "id" FROM "user_audit_events";
+
+CREATE OR REPLACE FUNCTION "user_audit_events_pending"() RETURNS trigger AS $$
+BEGIN
+  INSERT INTO "user_event_revisions" ("event_id") VALUES (NEW."id");
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "user_audit_events_pending" AFTER INSERT ON "user_audit_events"
+  FOR EACH ROW EXECUTE PROCEDURE "user_audit_events_pending"();
@@ -0,0 +1,41 @@
+//go:build ignore
+// +build ignore
+
+// gen.go writes SQL files of the directory to files.go
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+)
+
+func main() {
+	names, err := filepath.Glob("*.sql")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen.go. DO NOT EDIT.\n\npackage migrations\n\nvar files = map[string]string{\n")
+	for _, name := range names {
+		content, err := ioutil.ReadFile(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Fprintf(&buf, "%q: %q,\n", name, content)
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile("files.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,10 @@
+// Package migrations embeds SQL migrations of the database schema into the binary.
+// Files are named as golang-migrate expects: <version>_<name>.<up|down>.sql
+package migrations
+
+//go:generate go run gen.go
+
+// Files gives content of migration files by their names
+func Files() map[string]string {
+	return files
+}
@@ -0,0 +1,34 @@
+package migrations_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/reviz0r/golang-layout/migrations"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestMigrations(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Migrations Suite")
+}
+
+var _ = Describe("Files", func() {
+	It("are the same as SQL files of the directory, run go generate if they differ", func() {
+		names, err := filepath.Glob("*.sql")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names).NotTo(BeEmpty())
+
+		onDisk := make(map[string]string, len(names))
+		for _, name := range names {
+			content, err := ioutil.ReadFile(name)
+			Expect(err).NotTo(HaveOccurred())
+			onDisk[name] = string(content)
+		}
+
+		Expect(migrations.Files()).To(Equal(onDisk))
+	})
+})
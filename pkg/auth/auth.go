@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus/ctxlogrus"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	grpcMiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
+
+	"github.com/reviz0r/golang-layout/pkg/config"
+)
+
+// Module authenticates grpc calls by interceptors chained into grpc server options
+var Module = fx.Provide(NewAuthenticator, NewInterceptors)
+
+// metadata keys of credentials
+const (
+	AuthorizationMetadata = "authorization"
+	APIKeyMetadata        = "x-api-key"
+)
+
+// ErrNoCredentials is returned for calls of non public methods without credentials
+var ErrNoCredentials = errors.New("auth: credentials are required")
+
+// Authenticator gives principal of bearer token or api key of call
+type Authenticator struct {
+	enabled bool
+	public  []string
+	tokens  *TokenVerifier
+	apiKeys []apiKey
+}
+
+type apiKey struct {
+	name string
+	key  []byte
+}
+
+// NewAuthenticator gives authenticator configured by auth section. Key set of auth.jwt is loaded on start
+// and is loaded again while the app is running.
+func NewAuthenticator(lc fx.Lifecycle, config config.AuthConfig, logger *logrus.Entry) *Authenticator {
+	a := &Authenticator{
+		enabled: config.Enabled,
+		public:  config.PublicMethods,
+		tokens: &TokenVerifier{
+			Secret:             []byte(config.JWT.Secret),
+			Issuer:             config.JWT.Issuer,
+			Audience:           config.JWT.Audience,
+			ClockSkew:          config.JWT.ClockSkew,
+			AllowMissingExpiry: config.JWT.AllowMissingExpiry,
+		},
+	}
+
+	names := make([]string, 0, len(config.APIKeys))
+	for name := range config.APIKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		a.apiKeys = append(a.apiKeys, apiKey{name: name, key: []byte(config.APIKeys[name])})
+	}
+
+	if config.Enabled && (config.JWT.JWKSFile != "" || config.JWT.JWKSURL != "") {
+		keys := NewJWKS(config.JWT.JWKSFile, config.JWT.JWKSURL)
+		a.tokens.Keys = keys
+
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				if err := keys.Load(ctx); err != nil {
+					return err
+				}
+				keys.Watch(config.JWT.JWKSRefreshInterval, logger)
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				return keys.Close()
+			},
+		})
+	}
+
+	return a
+}
+
+// Public reports if method can be called without credentials
+func (a *Authenticator) Public(method string) bool {
+	for _, public := range a.public {
+		if method == public || strings.HasSuffix(public, "/") && strings.HasPrefix(method, public) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticate gives context with principal of credentials in incoming metadata. Calls of public methods
+// are anonymous if they have no credentials, but invalid credentials are rejected for all methods.
+func (a *Authenticator) Authenticate(ctx context.Context, method string) (context.Context, error) {
+	if !a.enabled {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	p, err := a.principal(md)
+	if err == ErrNoCredentials && a.Public(method) {
+		return ctx, nil
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	ctxlogrus.AddFields(ctx, logrus.Fields{"auth.subject": p.Subject})
+	return NewContext(ctx, p), nil
+}
+
+func (a *Authenticator) principal(md metadata.MD) (*Principal, error) {
+	if values := md.Get(AuthorizationMetadata); len(values) != 0 {
+		const prefix = "bearer "
+		value := values[0]
+		if len(value) <= len(prefix) || !strings.EqualFold(value[:len(prefix)], prefix) {
+			return nil, errors.New("auth: authorization must be bearer token")
+		}
+
+		return a.tokens.Verify(strings.TrimSpace(value[len(prefix):]))
+	}
+
+	if values := md.Get(APIKeyMetadata); len(values) != 0 {
+		return a.apiKey(values[0])
+	}
+
+	return nil, ErrNoCredentials
+}
+
+// apiKey compares key with every configured key in constant time
+func (a *Authenticator) apiKey(value string) (*Principal, error) {
+	var p *Principal
+	for _, k := range a.apiKeys {
+		if subtle.ConstantTimeCompare([]byte(value), k.key) == 1 && p == nil {
+			p = &Principal{Subject: k.name}
+		}
+	}
+
+	if p == nil {
+		return nil, errors.New("auth: invalid api key")
+	}
+	return p, nil
+}
+
+// UnaryServerInterceptor authenticates unary calls
+func (a *Authenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.Authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor authenticates streaming calls
+func (a *Authenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.Authenticate(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		wrapped := grpcMiddleware.WrapServerStream(stream)
+		wrapped.WrappedContext = ctx
+		return handler(srv, wrapped)
+	}
+}
+
+// InterceptorsResult .
+type InterceptorsResult struct {
+	fx.Out
+
+	Unary  grpc.UnaryServerInterceptor  `group:"grpc_unary_interceptors"`
+	Stream grpc.StreamServerInterceptor `group:"grpc_stream_interceptors"`
+}
+
+// NewInterceptors gives interceptors of authenticator, which are chained by server.InterceptorsModule
+func NewInterceptors(a *Authenticator) InterceptorsResult {
+	return InterceptorsResult{
+		Unary:  a.UnaryServerInterceptor(),
+		Stream: a.StreamServerInterceptor(),
+	}
+}
@@ -0,0 +1,524 @@
+package auth_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.uber.org/fx/fxtest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/reviz0r/golang-layout/pkg/auth"
+	"github.com/reviz0r/golang-layout/pkg/config"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+func TestAuth(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Auth Suite")
+}
+
+// now is current time of verifiers
+var now = time.Unix(1600000000, 0)
+
+var rsaKey, otherRSAKey *rsa.PrivateKey
+
+var _ = BeforeSuite(func() {
+	var err error
+	rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).NotTo(HaveOccurred())
+	otherRSAKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).NotTo(HaveOccurred())
+})
+
+type object map[string]interface{}
+
+func segment(v interface{}) string {
+	data, err := json.Marshal(v)
+	Expect(err).NotTo(HaveOccurred())
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// sign gives token signed by HMAC secret or RSA key, header has kid if it is not empty
+func sign(alg, kid string, key interface{}, claims object) string {
+	header := object{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+	signed := segment(header) + "." + segment(claims)
+
+	var signature []byte
+	switch key := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// claims gives valid claims changed by the given ones, nil values remove claims
+func claims(changes object) object {
+	c := object{
+		"sub": "42",
+		"iss": "https://issuer.example.com/",
+		"aud": "profile",
+		"exp": now.Add(time.Hour).Unix(),
+	}
+	for k, v := range changes {
+		if v == nil {
+			delete(c, k)
+		} else {
+			c[k] = v
+		}
+	}
+	return c
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) object {
+	return object{
+		"kty": "RSA", "kid": kid, "use": "sig", "alg": RS256,
+		"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func octJWK(kid string, secret []byte) object {
+	return object{"kty": "oct", "kid": kid, "k": base64.RawURLEncoding.EncodeToString(secret)}
+}
+
+func keySet(keys ...object) []byte {
+	data, err := json.Marshal(object{"keys": keys})
+	Expect(err).NotTo(HaveOccurred())
+	return data
+}
+
+const (
+	HS256 = auth.HS256
+	RS256 = auth.RS256
+)
+
+var (
+	secret    = []byte("secret")
+	octSecret = []byte("oct secret")
+)
+
+var _ = Describe("TokenVerifier", func() {
+	var (
+		dir      string
+		jwksFile string
+		verifier *auth.TokenVerifier
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "auth")
+		Expect(err).NotTo(HaveOccurred())
+
+		jwksFile = filepath.Join(dir, "jwks.json")
+		Expect(ioutil.WriteFile(jwksFile, keySet(
+			octJWK("oct-0", []byte("old oct secret")), octJWK("oct-1", octSecret), rsaJWK("rsa-1", rsaKey),
+		), 0600)).To(Succeed())
+		keys := auth.NewJWKS(jwksFile, "")
+		Expect(keys.Load(context.Background())).To(Succeed())
+
+		verifier = &auth.TokenVerifier{
+			Secret:    secret,
+			Keys:      keys,
+			Issuer:    "https://issuer.example.com/",
+			Audience:  "profile",
+			ClockSkew: 30 * time.Second,
+			Now:       func() time.Time { return now },
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	DescribeTable("verifies token",
+		func(token func() string, expected interface{}) {
+			p, err := verifier.Verify(token())
+			if expected == nil {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(p).To(Equal(&auth.Principal{Subject: "42"}))
+				return
+			}
+			Expect(err).To(MatchError(expected))
+			Expect(p).To(BeNil())
+		},
+
+		Entry("HS256 signed by secret", func() string { return sign(HS256, "", secret, claims(nil)) }, nil),
+		Entry("HS256 signed by other secret",
+			func() string { return sign(HS256, "", []byte("other"), claims(nil)) }, auth.ErrInvalidSignature),
+		Entry("HS256 signed by oct key with kid",
+			func() string { return sign(HS256, "oct-1", octSecret, claims(nil)) }, nil),
+		Entry("HS256 signed by oct key without kid",
+			func() string { return sign(HS256, "", octSecret, claims(nil)) }, nil),
+		Entry("HS256 signed by oct key with other kid",
+			func() string { return sign(HS256, "oct-2", octSecret, claims(nil)) }, auth.ErrInvalidSignature),
+		Entry("HS256 signed by RSA key",
+			func() string { return sign(HS256, "rsa-1", rsaKey, claims(nil)) }, auth.ErrInvalidSignature),
+
+		Entry("RS256 signed by key with kid", func() string { return sign(RS256, "rsa-1", rsaKey, claims(nil)) }, nil),
+		Entry("RS256 signed by key without kid", func() string { return sign(RS256, "", rsaKey, claims(nil)) }, nil),
+		Entry("RS256 signed by key with other kid",
+			func() string { return sign(RS256, "rsa-2", rsaKey, claims(nil)) }, auth.ErrInvalidSignature),
+		Entry("RS256 signed by unknown key",
+			func() string { return sign(RS256, "rsa-1", otherRSAKey, claims(nil)) }, auth.ErrInvalidSignature),
+		Entry("RS256 with kid of oct key",
+			func() string { return sign(RS256, "oct-1", rsaKey, claims(nil)) }, auth.ErrInvalidSignature),
+
+		Entry("alg none", func() string { return segment(object{"alg": "none"}) + "." + segment(claims(nil)) + "." },
+			`auth: unsupported token algorithm "none"`),
+		Entry("unknown alg", func() string { return sign("HS512", "", secret, claims(nil)) },
+			`auth: unsupported token algorithm "HS512"`),
+		Entry("token of two parts", func() string { return "a.b" }, auth.ErrMalformedToken),
+		Entry("invalid signature encoding",
+			func() string { return sign(HS256, "", secret, claims(nil)) + "!" }, auth.ErrMalformedToken),
+		Entry("invalid header", func() string { return "bm90IGpzb24." + segment(claims(nil)) + ".c2ln" },
+			HavePrefix(auth.ErrMalformedToken.Error())),
+
+		Entry("expired within clock skew",
+			func() string {
+				return sign(HS256, "", secret, claims(object{"exp": now.Add(-29 * time.Second).Unix()}))
+			}, nil),
+		Entry("expired beyond clock skew",
+			func() string {
+				return sign(HS256, "", secret, claims(object{"exp": now.Add(-31 * time.Second).Unix()}))
+			},
+			auth.ErrExpiredToken),
+		Entry("exp with fraction of second",
+			func() string { return sign(HS256, "", secret, claims(object{"exp": float64(now.Unix()) + 0.5})) }, nil),
+		Entry("without exp", func() string { return sign(HS256, "", secret, claims(object{"exp": nil})) },
+			auth.ErrMissingExpiry),
+		Entry("not valid yet within clock skew",
+			func() string { return sign(HS256, "", secret, claims(object{"nbf": now.Add(29 * time.Second).Unix()})) }, nil),
+		Entry("not valid yet beyond clock skew",
+			func() string { return sign(HS256, "", secret, claims(object{"nbf": now.Add(31 * time.Second).Unix()})) },
+			auth.ErrTokenNotValidYet),
+
+		Entry("other issuer",
+			func() string { return sign(HS256, "", secret, claims(object{"iss": "https://other.example.com/"})) },
+			`auth: unexpected token issuer "https://other.example.com/"`),
+		Entry("without issuer", func() string { return sign(HS256, "", secret, claims(object{"iss": nil})) },
+			`auth: unexpected token issuer ""`),
+		Entry("audience array with audience",
+			func() string { return sign(HS256, "", secret, claims(object{"aud": []string{"other", "profile"}})) }, nil),
+		Entry("audience array without audience",
+			func() string { return sign(HS256, "", secret, claims(object{"aud": []string{"other"}})) },
+			"auth: token is not issued for this audience"),
+		Entry("other audience", func() string { return sign(HS256, "", secret, claims(object{"aud": "other"})) },
+			"auth: token is not issued for this audience"),
+		Entry("audience of invalid type", func() string { return sign(HS256, "", secret, claims(object{"aud": 1})) },
+			HavePrefix(auth.ErrMalformedToken.Error())),
+		Entry("empty subject", func() string { return sign(HS256, "", secret, claims(object{"sub": ""})) },
+			"auth: token has no subject"),
+	)
+
+	It("accepts token without exp if it is allowed", func() {
+		verifier.AllowMissingExpiry = true
+
+		p, err := verifier.Verify(sign(HS256, "", secret, claims(object{"exp": nil})))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Subject).To(Equal("42"))
+	})
+
+	It("does not check issuer and audience if they are not set", func() {
+		verifier.Issuer, verifier.Audience = "", ""
+
+		_, err := verifier.Verify(sign(HS256, "", secret, claims(object{"iss": nil, "aud": nil})))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	// the test fails with -race if keys of key set are shared with verification
+	It("verifies tokens concurrently", func() {
+		token := sign(HS256, "", secret, claims(nil))
+		start := make(chan struct{})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				<-start
+				for j := 0; j < 1000; j++ {
+					_, err := verifier.Verify(token)
+					Expect(err).NotTo(HaveOccurred())
+				}
+			}()
+		}
+		close(start)
+		wg.Wait()
+	})
+})
+
+var _ = Describe("JWKS", func() {
+	var (
+		dir  string
+		file string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "auth")
+		Expect(err).NotTo(HaveOccurred())
+		file = filepath.Join(dir, "jwks.json")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	verify := func(keys *auth.JWKS, token string) error {
+		v := &auth.TokenVerifier{Keys: keys, Now: func() time.Time { return now }}
+		_, err := v.Verify(token)
+		return err
+	}
+
+	It("loads oct and RSA keys and skips other ones", func() {
+		Expect(ioutil.WriteFile(file, keySet(
+			octJWK("oct-1", octSecret),
+			rsaJWK("rsa-1", rsaKey),
+			object{"kty": "EC", "kid": "ec-1", "crv": "P-256"},
+			object{"kty": "oct", "kid": "enc-1", "use": "enc", "k": "ZW5j"},
+		), 0600)).To(Succeed())
+
+		keys := auth.NewJWKS(file, "")
+		Expect(keys.Load(context.Background())).To(Succeed())
+
+		Expect(verify(keys, sign(HS256, "oct-1", octSecret, claims(nil)))).To(Succeed())
+		Expect(verify(keys, sign(RS256, "rsa-1", rsaKey, claims(nil)))).To(Succeed())
+		Expect(verify(keys, sign(HS256, "enc-1", []byte("enc"), claims(nil)))).To(Equal(auth.ErrInvalidSignature))
+	})
+
+	DescribeTable("rejects invalid key set",
+		func(data string, message string) {
+			Expect(ioutil.WriteFile(file, []byte(data), 0600)).To(Succeed())
+
+			err := auth.NewJWKS(file, "").Load(context.Background())
+			Expect(err).To(MatchError(HavePrefix(message)))
+		},
+		Entry("invalid JSON", `{"keys":`, "auth: invalid key set: "),
+		Entry("no signing keys", `{"keys":[{"kty":"EC"}]}`, "auth: there are no signing keys in key set"),
+		Entry("invalid RSA exponent", `{"keys":[{"kty":"RSA","n":"AQAB","e":""}]}`, "auth: invalid exponent of key 0"),
+		Entry("empty oct key", `{"keys":[{"kty":"oct","k":""}]}`, "auth: invalid secret of key 0"),
+	)
+
+	It("keeps the last loaded keys if key set cannot be loaded again", func() {
+		Expect(ioutil.WriteFile(file, keySet(rsaJWK("rsa-1", rsaKey)), 0600)).To(Succeed())
+		keys := auth.NewJWKS(file, "")
+		Expect(keys.Load(context.Background())).To(Succeed())
+
+		Expect(ioutil.WriteFile(file, []byte(`{"keys":[]}`), 0600)).To(Succeed())
+		Expect(keys.Load(context.Background())).NotTo(Succeed())
+		Expect(os.Remove(file)).To(Succeed())
+		Expect(keys.Load(context.Background())).To(MatchError(HavePrefix("auth: cannot load key set: ")))
+
+		Expect(verify(keys, sign(RS256, "rsa-1", rsaKey, claims(nil)))).To(Succeed())
+	})
+
+	It("loads keys from URL and keeps them if URL fails", func() {
+		var mu sync.Mutex
+		failing := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			if failing {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Write(keySet(rsaJWK("rsa-1", rsaKey)))
+		}))
+		defer server.Close()
+
+		keys := auth.NewJWKS("", server.URL)
+		Expect(keys.Load(context.Background())).To(Succeed())
+
+		mu.Lock()
+		failing = true
+		mu.Unlock()
+		err := keys.Load(context.Background())
+		Expect(err).To(MatchError("auth: cannot load key set: " + server.URL + " responded 500 Internal Server Error"))
+
+		Expect(verify(keys, sign(RS256, "rsa-1", rsaKey, claims(nil)))).To(Succeed())
+	})
+
+	It("loads rotated keys while it is watching them", func() {
+		Expect(ioutil.WriteFile(file, keySet(rsaJWK("rsa-1", rsaKey)), 0600)).To(Succeed())
+		keys := auth.NewJWKS(file, "")
+		Expect(keys.Load(context.Background())).To(Succeed())
+
+		logger := logrus.New()
+		logger.SetOutput(ioutil.Discard)
+		keys.Watch(10*time.Millisecond, logrus.NewEntry(logger))
+		defer keys.Close()
+
+		Expect(ioutil.WriteFile(file, keySet(rsaJWK("rsa-2", otherRSAKey)), 0600)).To(Succeed())
+
+		token := sign(RS256, "rsa-2", otherRSAKey, claims(nil))
+		Eventually(func() error { return verify(keys, token) }, 5*time.Second, 10*time.Millisecond).Should(Succeed())
+		Expect(verify(keys, sign(RS256, "rsa-1", rsaKey, claims(nil)))).To(Equal(auth.ErrInvalidSignature))
+	})
+})
+
+var _ = Describe("Authenticator", func() {
+	var (
+		lc *fxtest.Lifecycle
+		a  *auth.Authenticator
+	)
+
+	newAuthenticator := func(c config.AuthConfig) *auth.Authenticator {
+		logger := logrus.New()
+		logger.SetOutput(ioutil.Discard)
+
+		lc = fxtest.NewLifecycle(GinkgoT())
+		a := auth.NewAuthenticator(lc, c, logrus.NewEntry(logger))
+		lc.RequireStart()
+		return a
+	}
+
+	BeforeEach(func() {
+		a = newAuthenticator(config.AuthConfig{
+			Enabled:       true,
+			PublicMethods: []string{"/grpc.health.v1.Health/", "/profile.UserService/List"},
+			JWT:           config.AuthJWTConfig{Secret: string(secret), ClockSkew: time.Minute},
+			APIKeys:       map[string]string{"ci": "ci-key", "cron": "cron-key"},
+		})
+	})
+
+	AfterEach(func() {
+		lc.RequireStop()
+	})
+
+	authenticate := func(method string, kv ...string) (*auth.Principal, error) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(kv...))
+		ctx, err := a.Authenticate(ctx, method)
+		if err != nil {
+			return nil, err
+		}
+
+		p, _ := auth.FromContext(ctx)
+		return p, nil
+	}
+
+	// token is valid for the current time, as the authenticator uses clock of the system
+	token := func(sub string) string {
+		return sign(HS256, "", secret, object{"sub": sub, "exp": time.Now().Add(time.Hour).Unix()})
+	}
+
+	DescribeTable("authenticates calls",
+		func(method string, md []string, subject string, code codes.Code, message string) {
+			p, err := authenticate(method, md...)
+			if code != codes.OK {
+				Expect(status.Code(err)).To(Equal(code))
+				Expect(status.Convert(err).Message()).To(Equal(message))
+				return
+			}
+
+			Expect(err).NotTo(HaveOccurred())
+			if subject == "" {
+				Expect(p).To(BeNil())
+			} else {
+				Expect(p).To(Equal(&auth.Principal{Subject: subject}))
+			}
+		},
+		Entry("valid api key", "/profile.UserService/Create", []string{"x-api-key", "cron-key"}, "cron", codes.OK, ""),
+		Entry("invalid api key", "/profile.UserService/Create", []string{"x-api-key", "ci-key2"}, "",
+			codes.Unauthenticated, "auth: invalid api key"),
+		Entry("not bearer authorization", "/profile.UserService/Create", []string{"authorization", "Basic Y2k6a2V5"}, "",
+			codes.Unauthenticated, "auth: authorization must be bearer token"),
+		Entry("no credentials", "/profile.UserService/Create", nil, "",
+			codes.Unauthenticated, "auth: credentials are required"),
+		Entry("public method without credentials", "/profile.UserService/List", nil, "", codes.OK, ""),
+		Entry("method of public service without credentials", "/grpc.health.v1.Health/Check", nil, "", codes.OK, ""),
+		Entry("method with prefix of public method", "/profile.UserService/ListUserAuditEvents", nil, "",
+			codes.Unauthenticated, "auth: credentials are required"),
+		Entry("public method with valid credentials", "/profile.UserService/List", []string{"x-api-key", "ci-key"}, "ci",
+			codes.OK, ""),
+		Entry("public method with invalid credentials", "/profile.UserService/List", []string{"x-api-key", "key"}, "",
+			codes.Unauthenticated, "auth: invalid api key"),
+	)
+
+	It("authenticates bearer token", func() {
+		p, err := authenticate("/profile.UserService/Create", "authorization", "Bearer "+token("7"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p).To(Equal(&auth.Principal{Subject: "7"}))
+	})
+
+	It("authenticates bearer token of any case", func() {
+		p, err := authenticate("/profile.UserService/Create", "authorization", "bearer "+token("7"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Subject).To(Equal("7"))
+	})
+
+	It("prefers token if call has both token and api key", func() {
+		p, err := authenticate("/profile.UserService/Create", "authorization", "Bearer "+token("7"), "x-api-key", "ci-key")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Subject).To(Equal("7"))
+	})
+
+	It("rejects expired token", func() {
+		expired := sign(HS256, "", secret, object{"sub": "7", "exp": time.Now().Add(-time.Hour).Unix()})
+
+		_, err := authenticate("/profile.UserService/List", "authorization", "Bearer "+expired)
+		Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+		Expect(status.Convert(err).Message()).To(Equal(auth.ErrExpiredToken.Error()))
+	})
+
+	It("does not authenticate calls if auth is disabled", func() {
+		lc.RequireStop()
+		a = newAuthenticator(config.AuthConfig{})
+
+		p, err := authenticate("/profile.UserService/Create", "x-api-key", "invalid")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p).To(BeNil())
+	})
+
+	It("loads key set on start", func() {
+		dir, err := ioutil.TempDir("", "auth")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+		file := filepath.Join(dir, "jwks.json")
+		Expect(ioutil.WriteFile(file, keySet(rsaJWK("rsa-1", rsaKey)), 0600)).To(Succeed())
+
+		lc.RequireStop()
+		a = newAuthenticator(config.AuthConfig{
+			Enabled: true,
+			JWT:     config.AuthJWTConfig{JWKSFile: file, JWKSRefreshInterval: time.Minute},
+		})
+
+		rs := sign(RS256, "rsa-1", rsaKey, object{"sub": "7", "exp": time.Now().Add(time.Hour).Unix()})
+		p, err := authenticate("/profile.UserService/Create", "authorization", "Bearer "+rs)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Subject).To(Equal("7"))
+	})
+})
@@ -0,0 +1,214 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// jwksTimeout bounds time of loading key set from URL
+const jwksTimeout = 10 * time.Second
+
+// key is a key of JWKS, either RSA public key or HMAC secret
+type key struct {
+	id     string
+	rsa    *rsa.PublicKey
+	secret []byte
+}
+
+// jwk is JSON web key as defined by RFC 7517, only RSA and oct keys are supported
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	K   string `json:"k"`
+}
+
+// parseKeySet gives signing keys of JWKS document, keys of unknown types are skipped
+func parseKeySet(data []byte) ([]key, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("auth: invalid key set: %v", err)
+	}
+
+	var keys []key
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		switch k.Kty {
+		case "RSA":
+			n, err := base64.RawURLEncoding.DecodeString(k.N)
+			if err != nil {
+				return nil, fmt.Errorf("auth: invalid modulus of key %d: %v", i, err)
+			}
+			e, err := base64.RawURLEncoding.DecodeString(k.E)
+			if err != nil || len(e) == 0 || len(e) > 4 {
+				return nil, fmt.Errorf("auth: invalid exponent of key %d", i)
+			}
+
+			pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+			keys = append(keys, key{id: k.Kid, rsa: pub})
+
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil || len(secret) == 0 {
+				return nil, fmt.Errorf("auth: invalid secret of key %d", i)
+			}
+			keys = append(keys, key{id: k.Kid, secret: secret})
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("auth: there are no signing keys in key set")
+	}
+
+	return keys, nil
+}
+
+// JWKS keeps keys loaded from file or URL, keys are loaded again periodically while JWKS is watching them
+type JWKS struct {
+	file, url string
+	client    *http.Client
+
+	mu   sync.RWMutex
+	keys []key
+
+	stop, done chan struct{}
+}
+
+// NewJWKS gives key set of file or URL, keys are not loaded until Load is called
+func NewJWKS(file, url string) *JWKS {
+	return &JWKS{file: file, url: url, client: &http.Client{Timeout: jwksTimeout}}
+}
+
+// Load loads keys again, keys are not changed if key set is invalid
+func (s *JWKS) Load(ctx context.Context) error {
+	data, err := s.read(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys, err := parseKeySet(data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys = keys
+	return nil
+}
+
+func (s *JWKS) read(ctx context.Context) ([]byte, error) {
+	if s.url == "" {
+		data, err := ioutil.ReadFile(s.file)
+		if err != nil {
+			return nil, fmt.Errorf("auth: cannot load key set: %v", err)
+		}
+		return data, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("auth: cannot load key set: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("auth: cannot load key set: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("auth: cannot load key set: %s responded %s", s.url, resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("auth: cannot load key set: %v", err)
+	}
+	return data, nil
+}
+
+// Watch loads keys again every interval, e.g. when keys are rotated by issuer
+func (s *JWKS) Watch(interval time.Duration, logger *logrus.Entry) {
+	s.stop, s.done = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				select {
+				case <-s.stop:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+
+			err := s.Load(ctx)
+			cancel()
+			if err != nil {
+				// the last loaded keys are used until key set is loaded
+				logger.WithError(err).Warn("auth: cannot reload key set")
+			}
+		}
+	}()
+}
+
+// Close stops watching keys
+func (s *JWKS) Close() error {
+	if s.stop == nil {
+		return nil
+	}
+
+	close(s.stop)
+	<-s.done
+	return nil
+}
+
+// lookup gives keys with id, or all keys if id is empty
+func (s *JWKS) lookup(id string) []key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// keys are copied, so callers may append to them
+	if id == "" {
+		return append([]key(nil), s.keys...)
+	}
+
+	var keys []key
+	for _, k := range s.keys {
+		if k.id == id {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
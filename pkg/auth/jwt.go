@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// signing algorithms of tokens
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+)
+
+// token errors
+var (
+	ErrMalformedToken   = errors.New("auth: malformed token")
+	ErrInvalidSignature = errors.New("auth: invalid token signature")
+	ErrExpiredToken     = errors.New("auth: token is expired")
+	ErrMissingExpiry    = errors.New("auth: token has no expiration time")
+	ErrTokenNotValidYet = errors.New("auth: token is not valid yet")
+)
+
+// TokenVerifier verifies JWTs signed by HS256 with secret or with oct key of key set,
+// and by RS256 with RSA key of key set
+type TokenVerifier struct {
+	Secret []byte
+	// Keys is optional key set, keys are selected by kid header of token
+	Keys *JWKS
+
+	// Issuer and Audience are checked if they are not empty
+	Issuer   string
+	Audience string
+	// ClockSkew is allowed difference of clocks on checks of exp and nbf claims
+	ClockSkew time.Duration
+	// AllowMissingExpiry accepts tokens without exp claim, they never expire
+	AllowMissingExpiry bool
+
+	// Now gives current time, time.Now is used if it is nil
+	Now func() time.Time
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *float64 `json:"exp"`
+	NotBefore *float64 `json:"nbf"`
+}
+
+// audience is aud claim, which is either string or array of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.New("aud must be string or array of strings")
+	}
+	*a = list
+	return nil
+}
+
+func (a audience) contains(aud string) bool {
+	for _, v := range a {
+		if v == aud {
+			return true
+		}
+	}
+	return false
+}
+
+// Verify checks signature and claims of token and gives principal of its subject
+func (v *TokenVerifier) Verify(token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	if err := v.verifySignature(h, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var c claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return nil, err
+	}
+	if err := v.verifyClaims(c); err != nil {
+		return nil, err
+	}
+
+	return &Principal{Subject: c.Subject}, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrMalformedToken
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%v: %v", ErrMalformedToken, err)
+	}
+	return nil
+}
+
+func (v *TokenVerifier) verifySignature(h header, signed string, signature []byte) error {
+	var keys []key
+	if v.Keys != nil {
+		keys = v.Keys.lookup(h.Kid)
+	}
+
+	switch h.Alg {
+	case HS256:
+		if len(v.Secret) != 0 {
+			keys = append(keys, key{secret: v.Secret})
+		}
+
+		for _, k := range keys {
+			if k.secret == nil {
+				continue
+			}
+
+			mac := hmac.New(sha256.New, k.secret)
+			mac.Write([]byte(signed))
+			if hmac.Equal(signature, mac.Sum(nil)) {
+				return nil
+			}
+		}
+
+	case RS256:
+		digest := sha256.Sum256([]byte(signed))
+		for _, k := range keys {
+			if k.rsa == nil {
+				continue
+			}
+
+			if rsa.VerifyPKCS1v15(k.rsa, crypto.SHA256, digest[:], signature) == nil {
+				return nil
+			}
+		}
+
+	default:
+		return fmt.Errorf("auth: unsupported token algorithm %q", h.Alg)
+	}
+
+	return ErrInvalidSignature
+}
+
+func (v *TokenVerifier) verifyClaims(c claims) error {
+	now := time.Now
+	if v.Now != nil {
+		now = v.Now
+	}
+	t := now()
+
+	if c.ExpiresAt == nil && !v.AllowMissingExpiry {
+		return ErrMissingExpiry
+	}
+	if c.ExpiresAt != nil && t.After(unixTime(*c.ExpiresAt).Add(v.ClockSkew)) {
+		return ErrExpiredToken
+	}
+	if c.NotBefore != nil && t.Before(unixTime(*c.NotBefore).Add(-v.ClockSkew)) {
+		return ErrTokenNotValidYet
+	}
+	if v.Issuer != "" && c.Issuer != v.Issuer {
+		return fmt.Errorf("auth: unexpected token issuer %q", c.Issuer)
+	}
+	if v.Audience != "" && !c.Audience.contains(v.Audience) {
+		return errors.New("auth: token is not issued for this audience")
+	}
+	if c.Subject == "" {
+		return errors.New("auth: token has no subject")
+	}
+
+	return nil
+}
+
+// unixTime converts NumericDate of claims, which may have fraction of second
+func unixTime(seconds float64) time.Time {
+	whole, frac := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(frac*float64(time.Second)))
+}
@@ -0,0 +1,22 @@
+package auth
+
+import "context"
+
+// Principal is the authenticated caller of request
+type Principal struct {
+	// Subject identifies the caller, e.g. user id from token or name of api key
+	Subject string
+}
+
+type principalKey struct{}
+
+// NewContext gives context carrying principal
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext gives principal stored in context, if any
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
@@ -0,0 +1,248 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+)
+
+// client authentication modes of ServerConfig
+const (
+	ClientAuthRequire  = "require"
+	ClientAuthOptional = "optional"
+)
+
+// Store keeps certificate with its key and pool of CA certificates loaded from files,
+// files are loaded again when they change on disk while Store is watching them
+type Store struct {
+	certFile, keyFile, caFile string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+	pool *x509.CertPool
+
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+}
+
+// NewStore loads certificate and CA files, empty names are skipped
+func NewStore(certFile, keyFile, caFile string) (*Store, error) {
+	s := &Store{certFile: certFile, keyFile: keyFile, caFile: caFile}
+	if err := s.Load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Load loads files again, certificates are not changed if files are invalid
+func (s *Store) Load() error {
+	var cert *tls.Certificate
+	if s.certFile != "" {
+		c, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+		if err != nil {
+			return fmt.Errorf("certs: cannot load certificate: %v", err)
+		}
+		cert = &c
+	}
+
+	var pool *x509.CertPool
+	if s.caFile != "" {
+		pem, err := ioutil.ReadFile(s.caFile)
+		if err != nil {
+			return fmt.Errorf("certs: cannot load CA: %v", err)
+		}
+
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("certs: no certificates in %s", s.caFile)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cert, s.pool = cert, pool
+	return nil
+}
+
+// Watch loads files again whenever they change, e.g. when they are rotated
+func (s *Store) Watch(logger *logrus.Entry) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("certs: cannot watch files: %v", err)
+	}
+
+	// directories are watched, as files are usually replaced rather than written
+	dirs := make(map[string]bool)
+	files := make(map[string]bool)
+	for _, file := range []string{s.certFile, s.keyFile, s.caFile} {
+		if file != "" {
+			dirs[filepath.Dir(file)] = true
+			files[filepath.Clean(file)] = true
+		}
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return fmt.Errorf("certs: cannot watch %s: %v", dir, err)
+		}
+	}
+
+	s.watcher, s.done = watcher, make(chan struct{})
+	go func() {
+		defer close(s.done)
+
+		for {
+			select {
+			case e, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if e.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+				// kubernetes replaces files of mounted secret by swapping ..data symlink
+				if !files[filepath.Clean(e.Name)] && filepath.Base(e.Name) != "..data" {
+					continue
+				}
+
+				if err := s.Load(); err != nil {
+					// key and certificate may be written one by one, the next event loads both
+					logger.WithError(err).Warn("certs: cannot reload certificates")
+					continue
+				}
+				logger.WithField("file", e.Name).Info("certs: certificates are reloaded")
+
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.WithError(err).Warn("certs: cannot watch files")
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Close stops watching files
+func (s *Store) Close() error {
+	if s.watcher == nil {
+		return nil
+	}
+
+	err := s.watcher.Close()
+	<-s.done
+	return err
+}
+
+// Certificate gives the last loaded certificate
+func (s *Store) Certificate() *tls.Certificate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.cert
+}
+
+// Pool gives the last loaded CA certificates, it is nil if there is no CA file
+func (s *Store) Pool() *x509.CertPool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.pool
+}
+
+// ServerConfig gives config of TLS server with the certificate of Store. Client certificates are
+// verified by CA of Store if it has one, they are required unless clientAuth is ClientAuthOptional.
+func (s *Store) ServerConfig(clientAuth string) *tls.Config {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return s.Certificate(), nil
+		},
+	}
+
+	if s.caFile == "" {
+		return cfg
+	}
+
+	// certificates are verified here rather than by ClientCAs, so rotated CA is used without restart
+	cfg.ClientAuth = tls.RequireAnyClientCert
+	if clientAuth == ClientAuthOptional {
+		cfg.ClientAuth = tls.RequestClientCert
+	}
+	cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return nil
+		}
+
+		return s.verify(cs.PeerCertificates, x509.ExtKeyUsageClientAuth)
+	}
+
+	return cfg
+}
+
+// ClientConfig gives config of TLS client of server with DNS name or IP address serverName, server certificate
+// is verified by the last loaded CA of Store, or by system CA if Store has no one. The last loaded certificate
+// of Store is sent to server.
+func (s *Store) ClientConfig(serverName string) *tls.Config {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+
+	if s.caFile != "" {
+		// certificates are verified here rather than by RootCAs, so rotated CA is used without restart.
+		// Name is not taken from connection state, as it is empty for IP addresses.
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			if serverName == "" {
+				return errors.New("certs: there is no server name to verify certificate")
+			}
+			if err := s.verify(cs.PeerCertificates, x509.ExtKeyUsageServerAuth); err != nil {
+				return err
+			}
+			return cs.PeerCertificates[0].VerifyHostname(serverName)
+		}
+	}
+
+	if s.certFile != "" {
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return s.Certificate(), nil
+		}
+	}
+
+	return cfg
+}
+
+// verify verifies chain of peer by the last loaded CA
+func (s *Store) verify(certs []*x509.Certificate, usage x509.ExtKeyUsage) error {
+	if len(certs) == 0 {
+		return errors.New("certs: peer has no certificate")
+	}
+
+	pool := s.Pool()
+	if pool == nil {
+		return errors.New("certs: there is no CA to verify certificate")
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         pool,
+		Intermediates: x509.NewCertPool(),
+		KeyUsages:     []x509.ExtKeyUsage{usage},
+	}
+	for _, cert := range certs[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+
+	_, err := certs[0].Verify(opts)
+	return err
+}
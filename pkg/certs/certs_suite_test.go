@@ -0,0 +1,242 @@
+package certs_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/reviz0r/golang-layout/pkg/certs"
+	"github.com/reviz0r/golang-layout/pkg/mockcerts"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestCerts(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Certs Suite")
+}
+
+var _ = Describe("Store", func() {
+	var (
+		dir               string
+		ca, otherCA       *mockcerts.Authority
+		caFile            string
+		certFile, keyFile string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "certs")
+		Expect(err).NotTo(HaveOccurred())
+
+		ca, err = mockcerts.NewAuthority("ca")
+		Expect(err).NotTo(HaveOccurred())
+		otherCA, err = mockcerts.NewAuthority("other ca")
+		Expect(err).NotTo(HaveOccurred())
+
+		caFile, err = ca.WriteCA(dir, "ca.crt")
+		Expect(err).NotTo(HaveOccurred())
+		certFile, keyFile, err = ca.WriteFiles(dir, "server", x509.ExtKeyUsageServerAuth, "localhost")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("loads certificate and CA", func() {
+		s, err := certs.NewStore(certFile, keyFile, caFile)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(s.Certificate()).NotTo(BeNil())
+		Expect(s.Pool()).NotTo(BeNil())
+	})
+
+	It("skips files which are not set", func() {
+		s, err := certs.NewStore("", "", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(s.Certificate()).To(BeNil())
+		Expect(s.Pool()).To(BeNil())
+	})
+
+	It("fails on invalid files", func() {
+		_, err := certs.NewStore(filepath.Join(dir, "missing.crt"), keyFile, "")
+		Expect(err).To(MatchError(HavePrefix("certs: cannot load certificate: ")))
+
+		_, err = certs.NewStore("", "", keyFile)
+		Expect(err).To(MatchError("certs: no certificates in " + keyFile))
+	})
+
+	It("keeps the last loaded certificate if files are invalid", func() {
+		s, err := certs.NewStore(certFile, keyFile, caFile)
+		Expect(err).NotTo(HaveOccurred())
+		cert, pool := s.Certificate(), s.Pool()
+
+		Expect(ioutil.WriteFile(certFile, []byte("invalid"), 0600)).To(Succeed())
+		Expect(s.Load()).To(MatchError(HavePrefix("certs: cannot load certificate: ")))
+
+		Expect(s.Certificate()).To(BeIdenticalTo(cert))
+		Expect(s.Pool()).To(BeIdenticalTo(pool))
+	})
+
+	It("loads files again when they change", func() {
+		s, err := certs.NewStore(certFile, keyFile, caFile)
+		Expect(err).NotTo(HaveOccurred())
+		cert := s.Certificate()
+
+		logger := logrus.New()
+		logger.SetOutput(ioutil.Discard)
+		Expect(s.Watch(logrus.NewEntry(logger))).To(Succeed())
+		defer s.Close()
+
+		_, _, err = ca.WriteFiles(dir, "server", x509.ExtKeyUsageServerAuth, "localhost")
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(s.Certificate, 5*time.Second, 10*time.Millisecond).ShouldNot(BeIdenticalTo(cert))
+		Expect(s.Certificate().Certificate[0]).NotTo(Equal(cert.Certificate[0]))
+	})
+
+	Describe("TLS round trip", func() {
+		var servers []*httptest.Server
+
+		// serve starts HTTPS server responding with common name of client certificate, it gives its URL
+		serve := func(cfg *tls.Config) string {
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if len(r.TLS.PeerCertificates) != 0 {
+					w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+				}
+			}))
+			server.Listener = tls.NewListener(server.Listener, cfg)
+			// rejected handshakes are expected
+			server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+			server.Start()
+			servers = append(servers, server)
+
+			return "https://" + server.Listener.Addr().String()
+		}
+
+		newClient := func(cfg *tls.Config) *http.Client {
+			// every request makes new handshake
+			return &http.Client{Transport: &http.Transport{TLSClientConfig: cfg, DisableKeepAlives: true}}
+		}
+
+		get := func(client *http.Client, url string) (string, error) {
+			res, err := client.Get(url)
+			if err != nil {
+				return "", err
+			}
+			defer res.Body.Close()
+
+			body, err := ioutil.ReadAll(res.Body)
+			return string(body), err
+		}
+
+		newStore := func(certFile, keyFile, caFile string) *certs.Store {
+			s, err := certs.NewStore(certFile, keyFile, caFile)
+			Expect(err).NotTo(HaveOccurred())
+			return s
+		}
+
+		// clientStore gives store of client certificate issued by ca, server is verified by CA of the suite
+		clientStore := func(ca *mockcerts.Authority, name string) *certs.Store {
+			certFile, keyFile, err := ca.WriteFiles(dir, name, x509.ExtKeyUsageClientAuth)
+			Expect(err).NotTo(HaveOccurred())
+			return newStore(certFile, keyFile, caFile)
+		}
+
+		AfterEach(func() {
+			for _, server := range servers {
+				server.Close()
+			}
+			servers = nil
+		})
+
+		It("verifies server by CA of store", func() {
+			url := serve(newStore(certFile, keyFile, "").ServerConfig(""))
+
+			body, err := get(newClient(newStore("", "", caFile).ClientConfig("localhost")), url)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(BeEmpty())
+		})
+
+		It("rejects server of other CA or of other name", func() {
+			url := serve(newStore(certFile, keyFile, "").ServerConfig(""))
+
+			otherCAFile, err := otherCA.WriteCA(dir, "other.crt")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = get(newClient(newStore("", "", otherCAFile).ClientConfig("localhost")), url)
+			Expect(err).To(MatchError(ContainSubstring("certificate signed by unknown authority")))
+
+			_, err = get(newClient(newStore("", "", caFile).ClientConfig("profile.example.com")), url)
+			Expect(err).To(MatchError(ContainSubstring("profile.example.com")))
+		})
+
+		It("rejects server if there is no name to verify", func() {
+			url := serve(newStore(certFile, keyFile, "").ServerConfig(""))
+
+			_, err := get(newClient(newStore("", "", caFile).ClientConfig("")), url)
+			Expect(err).To(MatchError(ContainSubstring("certs: there is no server name to verify certificate")))
+		})
+
+		It("verifies server by CA loaded after client config is made", func() {
+			clientCAs := newStore("", "", caFile)
+			client := newClient(clientCAs.ClientConfig("localhost"))
+
+			// server certificate and CA are rotated
+			newCertFile, newKeyFile, err := otherCA.WriteFiles(dir, "rotated", x509.ExtKeyUsageServerAuth, "localhost")
+			Expect(err).NotTo(HaveOccurred())
+			url := serve(newStore(newCertFile, newKeyFile, "").ServerConfig(""))
+
+			_, err = get(client, url)
+			Expect(err).To(HaveOccurred())
+
+			_, err = otherCA.WriteCA(dir, "ca.crt")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clientCAs.Load()).To(Succeed())
+
+			_, err = get(client, url)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("requires client certificate signed by CA by default", func() {
+			url := serve(newStore(certFile, keyFile, caFile).ServerConfig(certs.ClientAuthRequire))
+
+			body, err := get(newClient(clientStore(ca, "client").ClientConfig("localhost")), url)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(Equal("client"))
+
+			_, err = get(newClient(newStore("", "", caFile).ClientConfig("localhost")), url)
+			Expect(err).To(HaveOccurred())
+
+			_, err = get(newClient(clientStore(otherCA, "other").ClientConfig("localhost")), url)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("allows clients without certificate if client auth is optional", func() {
+			url := serve(newStore(certFile, keyFile, caFile).ServerConfig(certs.ClientAuthOptional))
+
+			body, err := get(newClient(newStore("", "", caFile).ClientConfig("localhost")), url)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(BeEmpty())
+
+			body, err = get(newClient(clientStore(ca, "client").ClientConfig("localhost")), url)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(Equal("client"))
+
+			// certificate is still verified if client sends it
+			_, err = get(newClient(clientStore(otherCA, "other").ClientConfig("localhost")), url)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
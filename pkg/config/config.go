@@ -7,24 +7,36 @@ import (
 	"go.uber.org/fx"
 )
 
-// Module gives config params from file, env and flags
-var Module = fx.Provide(NewConfig)
+// Module gives config params from default sources, typed sections of them and their watcher
+var Module = fx.Options(fx.Provide(NewConfig), Typed, Watch)
 
-// NewConfig gives new predefined config provider
-func NewConfig() (*viper.Viper, error) {
-	config := viper.New()
-	config.SetEnvKeyReplacer(strings.NewReplacer("__", "."))
+// Use gives module providing the given config instead of reading it again, e.g. after flags are parsed
+func Use(config *viper.Viper, layers *Layers) fx.Option {
+	return fx.Options(
+		fx.Provide(func() (*viper.Viper, *Layers) { return config, layers }),
+		Typed,
+		Watch,
+	)
+}
+
+// NewConfig gives new predefined config provider reading DefaultSources
+func NewConfig() (*viper.Viper, *Layers, error) {
+	return NewConfigFrom(DefaultSources())
+}
 
-	config.SetConfigName("config")
+// NewConfigFrom gives config provider reading the given sources
+func NewConfigFrom(sources Sources) (*viper.Viper, *Layers, error) {
+	config := viper.New()
 	config.SetConfigType("yaml")
-	config.AddConfigPath("./configs")
 
-	if err := config.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			// Config file was found but another error was produced
-			return nil, err
-		}
+	config.SetEnvPrefix(sources.EnvPrefix)
+	config.SetEnvKeyReplacer(strings.NewReplacer(".", "__"))
+	config.AutomaticEnv()
+
+	layers := &Layers{Sources: sources, config: config, flags: make(map[string]string)}
+	if err := layers.Read(); err != nil {
+		return nil, nil, err
 	}
 
-	return config, nil
+	return config, layers, nil
 }
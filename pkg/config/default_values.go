@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 	"go.uber.org/fx"
 )
@@ -11,8 +13,33 @@ var DefaultValues = fx.Invoke(SetConfigDefaults)
 // SetConfigDefaults define default values for app config
 func SetConfigDefaults(config *viper.Viper) {
 	config.SetDefault("database.dsn", "host=localhost user=postgres sslmode=disable")
+	config.SetDefault("database.migrate_on_start", false)
+	config.SetDefault("database.replica_check_interval", 5*time.Second)
+	config.SetDefault("database.replica_max_lag", 10*time.Second)
+	config.SetDefault("database.tx.max_retries", 3)
+	config.SetDefault("database.tx.min_backoff", 10*time.Millisecond)
+	config.SetDefault("database.tx.max_backoff", time.Second)
+	config.SetDefault("database.listener.min_reconnect_interval", 10*time.Millisecond)
+	config.SetDefault("database.listener.max_reconnect_interval", time.Minute)
 	config.SetDefault("grpc.network", "tcp")
 	config.SetDefault("grpc.address", ":50051")
+	config.SetDefault("gateway.profile_service_endpoint", "localhost:50051")
 	config.SetDefault("http.network", "tcp")
 	config.SetDefault("http.address", ":80")
+	config.SetDefault("health.timeout", 2*time.Second)
+	config.SetDefault("health.shutdown_delay", time.Duration(0))
+	config.SetDefault("outbox.webhook.timeout", 10*time.Second)
+	config.SetDefault("outbox.poll_interval", 5*time.Second)
+	config.SetDefault("outbox.batch_size", 100)
+	config.SetDefault("outbox.max_attempts", 10)
+	config.SetDefault("outbox.min_backoff", time.Second)
+	config.SetDefault("outbox.max_backoff", 10*time.Minute)
+	config.SetDefault("outbox.lease", time.Minute)
+	config.SetDefault("profile.purge_after", 30*24*time.Hour)
+	config.SetDefault("profile.purge_interval", time.Hour)
+	config.SetDefault("auth.enabled", false)
+	config.SetDefault("auth.public_methods", []string{"/grpc.health.v1.Health/"})
+	config.SetDefault("auth.jwt.jwks_refresh_interval", 5*time.Minute)
+	config.SetDefault("auth.jwt.clock_skew", 30*time.Second)
+	config.SetDefault("auth.jwt.allow_missing_exp", false)
 }
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// ParseFlags registers flag for every key known to config (by defaults or config file), e.g. --grpc.address,
+// --set key=value flag for any other key and flags of sources, then parses args. Flags override other sources.
+// Sources must be parsed by ParseSources before config is read, their flags are registered for usage only.
+// Defaults shown by usage are redacted, and only flags given in args are applied.
+func ParseFlags(config *viper.Viper, layers *Layers, flags *pflag.FlagSet, args []string) error {
+	sources := layers.Sources
+	SourceFlags(flags, &sources)
+
+	keys := config.AllKeys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		flags.String(key, fmt.Sprint(layers.Redact(key, config.Get(key))), "")
+	}
+
+	set := flags.StringArray("set", nil, "set config `key=value`, can be repeated")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		// default of unchanged flag is redacted, it must not override value of other sources
+		if !flags.Changed(key) {
+			continue
+		}
+		if err := config.BindPFlag(key, flags.Lookup(key)); err != nil {
+			return err
+		}
+		layers.setFlag(key, "flag --"+key)
+	}
+
+	for _, kv := range *set {
+		i := strings.Index(kv, "=")
+		if i <= 0 {
+			return fmt.Errorf("invalid argument %q for --set: expect key=value", kv)
+		}
+		key := strings.ToLower(kv[:i])
+		config.Set(key, kv[i+1:])
+		layers.setFlag(key, "flag --set")
+	}
+
+	return nil
+}
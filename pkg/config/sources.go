@@ -0,0 +1,285 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// Sources are locations of config. Later sources override earlier ones: defaults, base file, environment
+// overlay, files given by --config, secrets directory, environment variables and flags.
+type Sources struct {
+	// Dir contains base file config.yaml and environment overlay config.<Env>.yaml, both are optional
+	Dir string
+	Env string
+	// Files are read after overlay in the given order, they must exist
+	Files []string
+	// SecretsDir contains files named as keys, e.g. database.dsn, with values of the keys
+	SecretsDir string
+	// EnvPrefix is prefix of environment variables, e.g. PROFILE_DATABASE__DSN sets database.dsn
+	EnvPrefix string
+}
+
+// DefaultSources gives sources of ./configs, environment and secrets directory are taken from
+// PROFILE_ENV and PROFILE_SECRETS_DIR
+func DefaultSources() Sources {
+	return Sources{
+		Dir:        "./configs",
+		Env:        os.Getenv("PROFILE_ENV"),
+		SecretsDir: os.Getenv("PROFILE_SECRETS_DIR"),
+		EnvPrefix:  "PROFILE",
+	}
+}
+
+// SourceFlags registers flags changing sources
+func SourceFlags(flags *pflag.FlagSet, sources *Sources) {
+	flags.StringArrayVar(&sources.Files, "config", sources.Files, "read config `file` after files of configs dir, can be repeated")
+	flags.StringVar(&sources.Env, "env", sources.Env, "read environment overlay config.<`env`>.yaml")
+	flags.StringVar(&sources.SecretsDir, "secrets-dir", sources.SecretsDir, "read secrets from files of `dir` named as keys")
+	flags.StringVar(&sources.EnvPrefix, "env-prefix", sources.EnvPrefix, "`prefix` of environment variables")
+}
+
+// ParseSources gives DefaultSources changed by source flags of args, other args are ignored
+func ParseSources(args []string) (Sources, error) {
+	sources := DefaultSources()
+
+	flags := pflag.NewFlagSet("sources", pflag.ContinueOnError)
+	flags.ParseErrorsWhitelist.UnknownFlags = true
+	flags.Usage = func() {}
+	SourceFlags(flags, &sources)
+
+	if err := flags.Parse(args); err != nil && !errors.Is(err, pflag.ErrHelp) {
+		return sources, err
+	}
+
+	return sources, nil
+}
+
+// Layers reads config from Sources and remembers which source gave every key.
+// Once the app is started, config is read by Values and Load only and read again by Reload.
+type Layers struct {
+	Sources Sources
+
+	mu     sync.Mutex
+	config *viper.Viper
+	// values are read from files and secrets, origins are their sources
+	values  map[string]interface{}
+	origins map[string]string
+	// flags are sources of keys set by flags
+	flags map[string]string
+}
+
+// Read reads files and secrets again, keys removed from them fall back to defaults
+func (l *Layers) Read() error {
+	values, origins, err := l.read()
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.apply(values, origins)
+}
+
+// Reload reads files and secrets again and gives config of them. Values of invalid config are not kept,
+// so Values, Load and Origin give the previous ones.
+func (l *Layers) Reload() (*Config, error) {
+	values, origins, err := l.read()
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	previous, previousOrigins := l.values, l.origins
+	if err := l.apply(values, origins); err != nil {
+		return nil, l.restore(err, previous, previousOrigins)
+	}
+
+	config, err := Load(l.config)
+	if err != nil {
+		return nil, l.restore(err, previous, previousOrigins)
+	}
+
+	return config, nil
+}
+
+// restore applies the previous values after err, error of apply is not expected as they were applied once
+func (l *Layers) restore(err error, values map[string]interface{}, origins map[string]string) error {
+	if applyErr := l.apply(values, origins); applyErr != nil {
+		return fmt.Errorf("%v, previous config is not restored: %v", err, applyErr)
+	}
+	return err
+}
+
+// read gives values of files and secrets with their origins
+func (l *Layers) read() (map[string]interface{}, map[string]string, error) {
+	values := make(map[string]interface{})
+	origins := make(map[string]string)
+
+	files := []string{filepath.Join(l.Sources.Dir, "config.yaml")}
+	if l.Sources.Env != "" {
+		files = append(files, filepath.Join(l.Sources.Dir, "config."+l.Sources.Env+".yaml"))
+	}
+	for i, file := range append(files, l.Sources.Files...) {
+		v := viper.New()
+		v.SetConfigFile(file)
+		if err := v.ReadInConfig(); err != nil {
+			// files of configs dir are optional
+			if i < len(files) && os.IsNotExist(err) {
+				continue
+			}
+			return nil, nil, fmt.Errorf("config: cannot read %s: %v", file, err)
+		}
+
+		for _, key := range v.AllKeys() {
+			values[key] = v.Get(key)
+			origins[key] = "file " + file
+		}
+	}
+
+	if l.Sources.SecretsDir != "" {
+		secrets, err := ioutil.ReadDir(l.Sources.SecretsDir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("config: cannot read secrets: %v", err)
+		}
+
+		for _, secret := range secrets {
+			// kubernetes keeps versions of secrets in hidden directories
+			if secret.IsDir() || strings.HasPrefix(secret.Name(), ".") {
+				continue
+			}
+
+			file := filepath.Join(l.Sources.SecretsDir, secret.Name())
+			value, err := ioutil.ReadFile(file)
+			if err != nil {
+				return nil, nil, fmt.Errorf("config: cannot read secret: %v", err)
+			}
+
+			key := strings.ToLower(secret.Name())
+			values[key] = strings.TrimRight(string(value), "\r\n")
+			origins[key] = "secret " + file
+		}
+	}
+
+	return values, origins, nil
+}
+
+// apply replaces values of the previous read, it must be called under lock
+func (l *Layers) apply(values map[string]interface{}, origins map[string]string) error {
+	if err := l.config.ReadConfig(strings.NewReader("")); err != nil {
+		return err
+	}
+	if err := l.config.MergeConfigMap(nest(values)); err != nil {
+		return err
+	}
+	l.values, l.origins = values, origins
+
+	return nil
+}
+
+// Values gives effective values of all keys
+func (l *Layers) Values() map[string]interface{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	values := make(map[string]interface{})
+	for _, key := range l.config.AllKeys() {
+		values[key] = l.config.Get(key)
+	}
+	return values
+}
+
+// Load gives typed config of the effective values, as the package Load does
+func (l *Layers) Load() (*Config, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return Load(l.config)
+}
+
+// Paths gives files and directories of sources which exist
+func (l *Layers) Paths() []string {
+	var paths []string
+	candidates := append([]string{l.Sources.Dir, l.Sources.SecretsDir}, l.Sources.Files...)
+	for _, path := range candidates {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// Origin gives source of the effective value of key
+func (l *Layers) Origin(key string) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if origin, ok := l.flags[key]; ok {
+		return origin
+	}
+
+	env := l.envName(key)
+	if _, ok := os.LookupEnv(env); ok {
+		return "env " + env
+	}
+
+	if origin, ok := l.origins[key]; ok {
+		return origin
+	}
+
+	return "default"
+}
+
+// Secret reports if the effective value of key is read from secrets directory
+func (l *Layers) Secret(key string) bool {
+	return strings.HasPrefix(l.Origin(key), "secret ")
+}
+
+func (l *Layers) setFlag(key, origin string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.flags[key] = origin
+}
+
+// envName is name of environment variable of key, as viper builds it
+func (l *Layers) envName(key string) string {
+	name := strings.ToUpper(strings.Replace(key, ".", "__", -1))
+	if l.Sources.EnvPrefix != "" {
+		name = strings.ToUpper(l.Sources.EnvPrefix) + "_" + name
+	}
+	return name
+}
+
+// nest turns dotted keys into nested maps, as config files have them
+func nest(values map[string]interface{}) map[string]interface{} {
+	nested := make(map[string]interface{})
+	for key, value := range values {
+		path := strings.Split(key, ".")
+
+		m := nested
+		for _, part := range path[:len(path)-1] {
+			sub, ok := m[part].(map[string]interface{})
+			if !ok {
+				sub = make(map[string]interface{})
+				m[part] = sub
+			}
+			m = sub
+		}
+		m[path[len(path)-1]] = value
+	}
+	return nested
+}
@@ -0,0 +1,401 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go.uber.org/fx"
+)
+
+// Typed provides Config and its sections unmarshaled from viper config, it fails on invalid config
+var Typed = fx.Provide(Load, sectionsOf)
+
+// Config is typed config of the app, keys of viper config are set by mapstructure tags
+type Config struct {
+	Database DatabaseConfig `mapstructure:"database"`
+	GRPC     GRPCConfig     `mapstructure:"grpc"`
+	HTTP     HTTPConfig     `mapstructure:"http"`
+	Logger   LoggerConfig   `mapstructure:"logger"`
+	Gateway  GatewayConfig  `mapstructure:"gateway"`
+	Health   HealthConfig   `mapstructure:"health"`
+	Outbox   OutboxConfig   `mapstructure:"outbox"`
+	Profile  ProfileConfig  `mapstructure:"profile"`
+	Auth     AuthConfig     `mapstructure:"auth"`
+}
+
+// DatabaseConfig is config of connections to postgres
+type DatabaseConfig struct {
+	DSN            string `mapstructure:"dsn"`
+	PingOnStart    bool   `mapstructure:"ping_on_start"`
+	MigrateOnStart bool   `mapstructure:"migrate_on_start"`
+
+	// pool settings, 0 keeps defaults of database/sql
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
+	MaxOpenConns    int           `mapstructure:"max_open_conns"`
+
+	// Replicas are DSNs of read-only replicas
+	Replicas             []string      `mapstructure:"replicas"`
+	ReplicaCheckInterval time.Duration `mapstructure:"replica_check_interval"`
+	// ReplicaMaxLag is replication lag making replica unhealthy, 0 means any lag
+	ReplicaMaxLag time.Duration `mapstructure:"replica_max_lag"`
+
+	Listener ListenerConfig `mapstructure:"listener"`
+	Tx       TxConfig       `mapstructure:"tx"`
+}
+
+// ListenerConfig bounds reconnect backoff of LISTEN/NOTIFY connection
+type ListenerConfig struct {
+	MinReconnectInterval time.Duration `mapstructure:"min_reconnect_interval"`
+	MaxReconnectInterval time.Duration `mapstructure:"max_reconnect_interval"`
+}
+
+// TxConfig bounds retries of transactions failed on serialization failure or deadlock
+type TxConfig struct {
+	MaxRetries int           `mapstructure:"max_retries"`
+	MinBackoff time.Duration `mapstructure:"min_backoff"`
+	MaxBackoff time.Duration `mapstructure:"max_backoff"`
+}
+
+// GRPCConfig is listen address of grpc server
+type GRPCConfig struct {
+	Network string    `mapstructure:"network"`
+	Address string    `mapstructure:"address"`
+	TLS     TLSConfig `mapstructure:"tls"`
+}
+
+// HTTPConfig is listen address of http server
+type HTTPConfig struct {
+	Network string    `mapstructure:"network"`
+	Address string    `mapstructure:"address"`
+	TLS     TLSConfig `mapstructure:"tls"`
+}
+
+// TLSConfig is config of TLS of server, it is enabled if CertFile is set.
+// Files are loaded again when they change.
+type TLSConfig struct {
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	// ClientCAFile enables verification of client certificates
+	ClientCAFile string `mapstructure:"client_ca_file"`
+	// ClientAuth is require (default) or optional, clients without certificates are allowed if it is optional
+	ClientAuth string `mapstructure:"client_auth"`
+}
+
+// Enabled reports if server uses TLS
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != ""
+}
+
+// LoggerConfig is config of logrus logger
+type LoggerConfig struct {
+	// Formatter is text or json
+	Formatter      string `mapstructure:"formatter"`
+	Level          string `mapstructure:"level"`
+	NoLock         bool   `mapstructure:"no_lock"`
+	OutputFile     string `mapstructure:"output_file"`
+	LogGRPCPayload bool   `mapstructure:"log_grpc_payload"`
+}
+
+// GatewayConfig is config of grpc-gateway
+type GatewayConfig struct {
+	ProfileServiceEndpoint string                 `mapstructure:"profile_service_endpoint"`
+	TLS                    GatewayTLSConfig       `mapstructure:"tls"`
+	Marshaler              GatewayMarshalerConfig `mapstructure:"marshaler"`
+}
+
+// GatewayTLSConfig is config of TLS connections of gateway to grpc server
+type GatewayTLSConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// CAFile verifies server certificate, system CA is used if it is empty
+	CAFile string `mapstructure:"ca_file"`
+	// CertFile and KeyFile are client certificate sent to server which verifies clients
+	CertFile   string `mapstructure:"cert_file"`
+	KeyFile    string `mapstructure:"key_file"`
+	ServerName string `mapstructure:"server_name"`
+}
+
+// GatewayMarshalerConfig is options of JSON marshaler of gateway responses
+type GatewayMarshalerConfig struct {
+	EnumsAsInts  bool   `mapstructure:"enums_as_ints"`
+	EmitDefaults bool   `mapstructure:"emit_defaults"`
+	Indent       string `mapstructure:"indent"`
+	OrigName     bool   `mapstructure:"orig_name"`
+}
+
+// HealthConfig is config of liveness and readiness checks
+type HealthConfig struct {
+	// Timeout bounds time of all checks of a probe
+	Timeout time.Duration `mapstructure:"timeout"`
+	// ShutdownDelay is time between readiness is failed on stop and servers are stopped,
+	// so load balancers stop sending new requests
+	ShutdownDelay time.Duration `mapstructure:"shutdown_delay"`
+}
+
+// OutboxConfig is config of outbox relay and its publisher
+type OutboxConfig struct {
+	// Publisher is file or webhook, it must be set
+	Publisher string              `mapstructure:"publisher"`
+	File      OutboxFileConfig    `mapstructure:"file"`
+	Webhook   OutboxWebhookConfig `mapstructure:"webhook"`
+
+	// PollInterval is interval of delivery besides notifications, 0 disables polling
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	BatchSize    int           `mapstructure:"batch_size"`
+	MaxAttempts  int           `mapstructure:"max_attempts"`
+	MinBackoff   time.Duration `mapstructure:"min_backoff"`
+	MaxBackoff   time.Duration `mapstructure:"max_backoff"`
+	// Lease is time batch of messages is claimed by relay for, messages which are not
+	// published until the lease ends are published again
+	Lease time.Duration `mapstructure:"lease"`
+}
+
+// OutboxFileConfig is config of file publisher
+type OutboxFileConfig struct {
+	Path string `mapstructure:"path"`
+}
+
+// OutboxWebhookConfig is config of webhook publisher
+type OutboxWebhookConfig struct {
+	URL     string        `mapstructure:"url"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// ProfileConfig is config of user service
+type ProfileConfig struct {
+	// PurgeAfter is time after which deleted users are purged, 0 disables purge
+	PurgeAfter    time.Duration `mapstructure:"purge_after"`
+	PurgeInterval time.Duration `mapstructure:"purge_interval"`
+}
+
+// AuthConfig is config of authentication of grpc calls
+type AuthConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// PublicMethods are called without credentials, e.g. /profile.UserService/ListUsers,
+	// all methods of service are public if it ends with /, e.g. /grpc.health.v1.Health/
+	PublicMethods []string      `mapstructure:"public_methods"`
+	JWT           AuthJWTConfig `mapstructure:"jwt"`
+	// APIKeys are static keys sent in x-api-key metadata by names, name is subject of caller
+	APIKeys map[string]string `mapstructure:"api_keys"`
+}
+
+// AuthJWTConfig is config of bearer tokens sent in authorization metadata
+type AuthJWTConfig struct {
+	// Secret verifies HS256 tokens
+	Secret string `mapstructure:"secret"`
+	// JWKSFile or JWKSURL is key set verifying RS256 tokens and HS256 ones with oct keys,
+	// it is loaded again every JWKSRefreshInterval
+	JWKSFile            string        `mapstructure:"jwks_file"`
+	JWKSURL             string        `mapstructure:"jwks_url"`
+	JWKSRefreshInterval time.Duration `mapstructure:"jwks_refresh_interval"`
+	// Issuer and Audience are checked if set
+	Issuer   string `mapstructure:"issuer"`
+	Audience string `mapstructure:"audience"`
+	// ClockSkew is allowed difference of clocks on checks of exp and nbf claims
+	ClockSkew time.Duration `mapstructure:"clock_skew"`
+	// AllowMissingExpiry accepts tokens without exp claim, which are rejected by default
+	AllowMissingExpiry bool `mapstructure:"allow_missing_exp"`
+}
+
+// ValidationError lists all problems of config
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("config: %d problem(s):\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+// Load unmarshals config with defaults of SetConfigDefaults and validates it.
+// It gives ValidationError with all problems: unknown keys, values of wrong types and invalid values.
+func Load(config *viper.Viper) (*Config, error) {
+	SetConfigDefaults(config)
+
+	var c Config
+	var problems []string
+
+	err := config.Unmarshal(&c, func(dc *mapstructure.DecoderConfig) {
+		dc.ErrorUnused = true
+	})
+	if err != nil {
+		problems = append(problems, decodeProblems(err)...)
+	}
+
+	problems = append(problems, c.validate()...)
+	if len(problems) != 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	return &c, nil
+}
+
+// decodeProblems splits aggregated error of mapstructure
+func decodeProblems(err error) []string {
+	merr, ok := err.(*mapstructure.Error)
+	if !ok {
+		return []string{err.Error()}
+	}
+
+	problems := append([]string(nil), merr.Errors...)
+	sort.Strings(problems)
+	return problems
+}
+
+func (c *Config) validate() []string {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.Database.DSN != "", "database.dsn must be set")
+	for i, dsn := range c.Database.Replicas {
+		check(dsn != "", "database.replicas[%d] must not be empty", i)
+	}
+	check(len(c.Database.Replicas) == 0 || c.Database.ReplicaCheckInterval > 0,
+		"database.replica_check_interval must be positive if there are replicas")
+	check(c.Database.Tx.MaxRetries >= 0, "database.tx.max_retries must not be negative")
+	check(c.Database.Tx.MinBackoff <= c.Database.Tx.MaxBackoff,
+		"database.tx.min_backoff must not exceed database.tx.max_backoff")
+	check(c.Database.Listener.MinReconnectInterval > 0 &&
+		c.Database.Listener.MinReconnectInterval <= c.Database.Listener.MaxReconnectInterval,
+		"database.listener reconnect intervals must be positive and min must not exceed max")
+
+	check(c.GRPC.Address != "", "grpc.address must be set")
+	problems = append(problems, c.GRPC.TLS.validate("grpc.tls")...)
+	check(c.HTTP.Address != "", "http.address must be set")
+	problems = append(problems, c.HTTP.TLS.validate("http.tls")...)
+
+	check(c.Gateway.ProfileServiceEndpoint != "", "gateway.profile_service_endpoint must be set")
+	check((c.Gateway.TLS.CertFile == "") == (c.Gateway.TLS.KeyFile == ""),
+		"gateway.tls.cert_file and gateway.tls.key_file must be set together")
+	check(c.Gateway.TLS.Enabled || c.Gateway.TLS == GatewayTLSConfig{},
+		"gateway.tls.enabled must be set if other gateway.tls keys are set")
+
+	switch c.Logger.Formatter {
+	case "", "text", "json":
+	default:
+		check(false, "logger.formatter must be text or json, got %q", c.Logger.Formatter)
+	}
+	if c.Logger.Level != "" {
+		_, err := logrus.ParseLevel(c.Logger.Level)
+		check(err == nil, "logger.level: %v", err)
+	}
+
+	check(c.Health.Timeout > 0, "health.timeout must be positive")
+	check(c.Health.ShutdownDelay >= 0, "health.shutdown_delay must not be negative")
+
+	switch c.Outbox.Publisher {
+	case "":
+		check(false, "outbox.publisher must be set to file or webhook")
+	case "file":
+		check(c.Outbox.File.Path != "", "outbox.file.path must be set for file publisher")
+	case "webhook":
+		u, err := url.Parse(c.Outbox.Webhook.URL)
+		check(err == nil && u.IsAbs(), "outbox.webhook.url must be absolute URL for webhook publisher, got %q", c.Outbox.Webhook.URL)
+	default:
+		check(false, "outbox.publisher must be file or webhook, got %q", c.Outbox.Publisher)
+	}
+	check(c.Outbox.BatchSize > 0, "outbox.batch_size must be positive")
+	check(c.Outbox.MaxAttempts > 0, "outbox.max_attempts must be positive")
+	check(c.Outbox.PollInterval >= 0, "outbox.poll_interval must not be negative")
+	check(c.Outbox.MinBackoff <= c.Outbox.MaxBackoff, "outbox.min_backoff must not exceed outbox.max_backoff")
+	check(c.Outbox.Lease > 0, "outbox.lease must be positive")
+	check(c.Outbox.Publisher != "webhook" || c.Outbox.Lease > c.Outbox.Webhook.Timeout,
+		"outbox.lease must exceed outbox.webhook.timeout")
+
+	check(c.Profile.PurgeAfter >= 0, "profile.purge_after must not be negative")
+	check(c.Profile.PurgeAfter == 0 || c.Profile.PurgeInterval > 0,
+		"profile.purge_interval must be positive if purge is enabled")
+
+	problems = append(problems, c.Auth.validate()...)
+
+	return problems
+}
+
+func (c AuthConfig) validate() []string {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	jwt := c.JWT
+	check(!c.Enabled || jwt.Secret != "" || jwt.JWKSFile != "" || jwt.JWKSURL != "" || len(c.APIKeys) != 0,
+		"auth.jwt.secret, auth.jwt.jwks_file, auth.jwt.jwks_url or auth.api_keys must be set if auth is enabled")
+	check(jwt.JWKSFile == "" || jwt.JWKSURL == "", "auth.jwt.jwks_file and auth.jwt.jwks_url must not be set together")
+	if jwt.JWKSURL != "" {
+		u, err := url.Parse(jwt.JWKSURL)
+		check(err == nil && u.IsAbs(), "auth.jwt.jwks_url must be absolute URL, got %q", jwt.JWKSURL)
+	}
+	check(jwt.JWKSFile == "" && jwt.JWKSURL == "" || jwt.JWKSRefreshInterval > 0,
+		"auth.jwt.jwks_refresh_interval must be positive if key set is set")
+	check(jwt.ClockSkew >= 0, "auth.jwt.clock_skew must not be negative")
+
+	names := make([]string, 0, len(c.APIKeys))
+	for name := range c.APIKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		check(c.APIKeys[name] != "", "auth.api_keys.%s must not be empty", name)
+	}
+	for i, method := range c.PublicMethods {
+		check(strings.HasPrefix(method, "/"), "auth.public_methods[%d] must start with /, got %q", i, method)
+	}
+
+	return problems
+}
+
+func (c TLSConfig) validate(prefix string) []string {
+	var problems []string
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		problems = append(problems, prefix+".cert_file and "+prefix+".key_file must be set together")
+	}
+	if c.ClientCAFile != "" && !c.Enabled() {
+		problems = append(problems, prefix+".client_ca_file requires "+prefix+".cert_file")
+	}
+	switch c.ClientAuth {
+	case "", "require", "optional":
+	default:
+		problems = append(problems, fmt.Sprintf("%s.client_auth must be require or optional, got %q", prefix, c.ClientAuth))
+	}
+	return problems
+}
+
+// Sections are sections of Config provided separately, so modules depend on their own config only
+type Sections struct {
+	fx.Out
+
+	Database DatabaseConfig
+	GRPC     GRPCConfig
+	HTTP     HTTPConfig
+	Logger   LoggerConfig
+	Gateway  GatewayConfig
+	Health   HealthConfig
+	Outbox   OutboxConfig
+	Profile  ProfileConfig
+	Auth     AuthConfig
+}
+
+func sectionsOf(c *Config) Sections {
+	return Sections{
+		Database: c.Database,
+		GRPC:     c.GRPC,
+		HTTP:     c.HTTP,
+		Logger:   c.Logger,
+		Gateway:  c.Gateway,
+		Health:   c.Health,
+		Outbox:   c.Outbox,
+		Profile:  c.Profile,
+		Auth:     c.Auth,
+	}
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/fx"
+)
+
+// Watch register config watcher in DI container
+var Watch = fx.Provide(NewWatcher)
+
+// reloadable are keys applied without restart, changes of other keys are applied after restart only
+var reloadable = map[string]bool{
+	"logger.level":               true,
+	"logger.formatter":           true,
+	"logger.log_grpc_payload":    true,
+	"database.conn_max_lifetime": true,
+	"database.max_idle_conns":    true,
+	"database.max_open_conns":    true,
+}
+
+// Change is reloaded config, Keys are changed keys of it
+type Change struct {
+	Old  *Config
+	New  *Config
+	Keys []string
+}
+
+// Changed reports if key or any key under it is changed
+func (c Change) Changed(key string) bool {
+	for _, k := range c.Keys {
+		if k == key || strings.HasPrefix(k, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Watcher reloads config when files of its sources are changed or the process gets SIGHUP,
+// and notifies subscribers about changes. Invalid config is not applied.
+type Watcher struct {
+	layers *Layers
+	logger *logrus.Entry
+
+	// reload serializes reloads, so subscribers get changes in order
+	reload sync.Mutex
+
+	mu          sync.Mutex
+	current     *Config
+	subscribers []func(Change)
+}
+
+// NewWatcher gives watcher of config sources, it starts watching on app start
+func NewWatcher(lc fx.Lifecycle, layers *Layers, current *Config, logger *logrus.Entry) *Watcher {
+	w := &Watcher{layers: layers, logger: logger.WithField("component", "config"), current: current}
+
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	var files *fsnotify.Watcher
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			var err error
+			files, err = fsnotify.NewWatcher()
+			if err != nil {
+				return fmt.Errorf("config: cannot watch files: %v", err)
+			}
+			for _, path := range layers.Paths() {
+				if err := files.Add(path); err != nil {
+					return fmt.Errorf("config: cannot watch %s: %v", path, err)
+				}
+			}
+
+			signal.Notify(signals, syscall.SIGHUP)
+			go func() {
+				for {
+					select {
+					case <-done:
+						return
+					case <-signals:
+						w.logger.Info("config: reloading on SIGHUP")
+						w.Reload()
+					case e := <-files.Events:
+						// file is removed or renamed while it is written, reload follows its creation
+						if e.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+							w.logger.WithField("file", e.Name).Debug("config: file is changed")
+							w.Reload()
+						}
+					case err := <-files.Errors:
+						w.logger.WithError(err).Warn("config: cannot watch files")
+					}
+				}
+			}()
+
+			return nil
+		},
+
+		OnStop: func(context.Context) error {
+			signal.Stop(signals)
+			close(done)
+			return files.Close()
+		},
+	})
+
+	return w
+}
+
+// Current gives the last applied config
+func (w *Watcher) Current() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.current
+}
+
+// Subscribe registers fn called with every applied change, it is called from the reloading goroutine
+func (w *Watcher) Subscribe(fn func(Change)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Reload reads config sources again, validates config and notifies subscribers about changes
+func (w *Watcher) Reload() {
+	w.reload.Lock()
+	defer w.reload.Unlock()
+
+	next, err := w.layers.Reload()
+	if err != nil {
+		w.logger.WithError(err).Error("config: config is not reloaded")
+		return
+	}
+
+	w.mu.Lock()
+	change := Change{Old: w.current, New: next, Keys: diffKeys("", reflect.ValueOf(*w.current), reflect.ValueOf(*next))}
+	if len(change.Keys) != 0 {
+		w.current = next
+	}
+	subscribers := w.subscribers
+	w.mu.Unlock()
+
+	if len(change.Keys) == 0 {
+		return
+	}
+
+	for _, key := range change.Keys {
+		if !reloadable[key] {
+			w.logger.WithField("key", key).Warn("config: key is changed, but it is applied after restart only")
+		}
+	}
+
+	for _, fn := range subscribers {
+		fn(change)
+	}
+
+	w.logger.WithField("keys", change.Keys).Info("config: reloaded")
+}
+
+// diffKeys gives keys of leaf fields which differ, keys are built from mapstructure tags
+func diffKeys(prefix string, old, new reflect.Value) []string {
+	if old.Kind() != reflect.Struct {
+		if reflect.DeepEqual(old.Interface(), new.Interface()) {
+			return nil
+		}
+		return []string{prefix}
+	}
+
+	var keys []string
+	for i := 0; i < old.NumField(); i++ {
+		key := old.Type().Field(i).Tag.Get("mapstructure")
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		keys = append(keys, diffKeys(key, old.Field(i), new.Field(i))...)
+	}
+
+	return keys
+}
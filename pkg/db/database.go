@@ -7,35 +7,37 @@ import (
 
 	// import postgresql driver
 	_ "github.com/lib/pq"
-	"github.com/spf13/viper"
+	"github.com/sirupsen/logrus"
 	"go.uber.org/fx"
+
+	"github.com/reviz0r/golang-layout/pkg/config"
+)
+
+// Module register database connection, replica router, transaction manager, notifications listener,
+// migrator and health check in DI container. Migrations are applied on start if database.migrate_on_start is set.
+var Module = fx.Options(
+	fx.Provide(NewDatabase, NewRouter, NewTxManager, NewNotifier, NewMigrator, NewHealthCheck),
+	fx.Invoke(RunMigrationsOnStart),
 )
 
-// Module register database connection in DI container
-var Module = fx.Provide(NewDatabase)
+// ReloadModule applies changes of connection pool limits without restart
+var ReloadModule = fx.Invoke(ReloadPoolOnChange)
+
+// defaultMaxIdleConns is default of database/sql, it is restored when database.max_idle_conns is unset
+const defaultMaxIdleConns = 2
 
 // NewDatabase gives new predefined database connection
-func NewDatabase(lc fx.Lifecycle, config *viper.Viper) (*sql.DB, error) {
-	dbconn, err := sql.Open("postgres", config.GetString("database.dsn"))
+func NewDatabase(lc fx.Lifecycle, config config.DatabaseConfig) (*sql.DB, error) {
+	dbconn, err := sql.Open("postgres", config.DSN)
 	if err != nil {
 		return nil, fmt.Errorf("cannot open connection to database: %v", err)
 	}
 
-	if connMaxLifetime := config.GetDuration("database.conn_max_lifetime"); connMaxLifetime != 0 {
-		dbconn.SetConnMaxLifetime(connMaxLifetime)
-	}
-
-	if maxIdleConns := config.GetInt("database.max_idle_conns"); maxIdleConns != 0 {
-		dbconn.SetMaxIdleConns(maxIdleConns)
-	}
-
-	if maxOpenConns := config.GetInt("database.max_open_conns"); maxOpenConns != 0 {
-		dbconn.SetMaxOpenConns(maxOpenConns)
-	}
+	configurePool(dbconn, config)
 
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			if config.GetBool("database.ping_on_start") {
+			if config.PingOnStart {
 				err = dbconn.PingContext(ctx)
 				if err != nil {
 					return fmt.Errorf("database: cannot ping connection: %v", err)
@@ -55,3 +57,29 @@ func NewDatabase(lc fx.Lifecycle, config *viper.Viper) (*sql.DB, error) {
 
 	return dbconn, nil
 }
+
+// ReloadPoolOnChange adjusts limits of connection pool when they are changed in config
+func ReloadPoolOnChange(w *config.Watcher, conn *sql.DB, logger *logrus.Entry) {
+	w.Subscribe(func(change config.Change) {
+		if !change.Changed("database.conn_max_lifetime") &&
+			!change.Changed("database.max_idle_conns") &&
+			!change.Changed("database.max_open_conns") {
+			return
+		}
+
+		configurePool(conn, change.New.Database)
+		logger.Info("database: connection pool limits are applied")
+	})
+}
+
+// configurePool sets limits of connection pool, 0 keeps defaults of database/sql
+func configurePool(conn *sql.DB, config config.DatabaseConfig) {
+	maxIdleConns := config.MaxIdleConns
+	if maxIdleConns == 0 {
+		maxIdleConns = defaultMaxIdleConns
+	}
+
+	conn.SetConnMaxLifetime(config.ConnMaxLifetime)
+	conn.SetMaxIdleConns(maxIdleConns)
+	conn.SetMaxOpenConns(config.MaxOpenConns)
+}
@@ -0,0 +1,222 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/ioutil"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sirupsen/logrus"
+
+	"github.com/reviz0r/golang-layout/pkg/db"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+func TestDB(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "DB Suite")
+}
+
+var _ = Describe("ParseMigrations", func() {
+	It("gives migrations ordered by version", func() {
+		list, err := db.ParseMigrations(map[string]string{
+			"000010_second.up.sql":   "up 10",
+			"000002_first.down.sql":  "down 2",
+			"000002_first.up.sql":    "up 2",
+			"000010_second.down.sql": "down 10",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list).To(Equal([]*db.Migration{
+			{Version: 2, Name: "first", Up: "up 2", Down: "down 2"},
+			{Version: 10, Name: "second", Up: "up 10", Down: "down 10"},
+		}))
+	})
+
+	DescribeTable("rejects invalid files",
+		func(files map[string]string, message interface{}) {
+			_, err := db.ParseMigrations(files)
+			Expect(err).To(MatchError(message))
+		},
+		Entry("name without version", map[string]string{"init.up.sql": ""},
+			`migrations: invalid file name "init.up.sql"`),
+		Entry("zero version", map[string]string{"0_init.up.sql": ""},
+			`migrations: invalid version of "0_init.up.sql"`),
+		Entry("different names of version", map[string]string{"1_a.up.sql": "", "1_b.up.sql": ""},
+			MatchRegexp(`^migrations: version 1 has different names "[ab]" and "[ab]"$`)),
+	)
+})
+
+var _ = Describe("Migrator", func() {
+	var (
+		conn     *sql.DB
+		mock     sqlmock.Sqlmock
+		migrator *db.Migrator
+	)
+
+	lockKey := int64(4317061527)
+	qLock := `^SELECT pg_advisory_lock\(\$1\)$`
+	qUnlock := `^SELECT pg_advisory_unlock\(\$1\)$`
+	qTable := `^CREATE TABLE IF NOT EXISTS "schema_migrations"`
+	qVersion := `^SELECT "version", "dirty" FROM "schema_migrations" LIMIT 1$`
+	qDelete := `^DELETE FROM "schema_migrations"$`
+	qInsert := `^INSERT INTO "schema_migrations" \("version", "dirty"\) VALUES \(\$1, false\)$`
+
+	expectLock := func() {
+		mock.ExpectExec(qLock).WithArgs(lockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(qTable).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	expectVersion := func(version int64, dirty bool) {
+		rows := sqlmock.NewRows([]string{"version", "dirty"})
+		if version != 0 {
+			rows.AddRow(version, dirty)
+		}
+		mock.ExpectQuery(qVersion).WillReturnRows(rows)
+	}
+	expectStep := func(query string, version int64) {
+		mock.ExpectBegin()
+		mock.ExpectExec("^" + regexp.QuoteMeta(query) + "$").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(qDelete).WillReturnResult(sqlmock.NewResult(0, 1))
+		if version != 0 {
+			mock.ExpectExec(qInsert).WithArgs(version).WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+		mock.ExpectCommit()
+	}
+	expectUnlock := func() {
+		mock.ExpectExec(qUnlock).WithArgs(lockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	BeforeEach(func() {
+		var err error
+		conn, mock, err = sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		logger := logrus.New()
+		logger.SetOutput(ioutil.Discard)
+
+		migrator = db.NewMigratorWithMigrations(conn, []*db.Migration{
+			{Version: 1, Name: "first", Up: "CREATE TABLE a", Down: "DROP TABLE a"},
+			{Version: 2, Name: "second", Up: "CREATE TABLE b", Down: "DROP TABLE b"},
+			{Version: 5, Name: "third", Up: "CREATE TABLE c", Down: "DROP TABLE c"},
+		}, logrus.NewEntry(logger))
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).NotTo(HaveOccurred())
+		conn.Close()
+	})
+
+	It("applies pending migrations in order of versions, each in its own transaction, holding lock", func() {
+		expectLock()
+		expectVersion(1, false)
+		expectStep("CREATE TABLE b", 2)
+		expectStep("CREATE TABLE c", 5)
+		expectUnlock()
+
+		Expect(migrator.Up(context.Background())).To(Succeed())
+	})
+
+	It("does nothing if all migrations are applied", func() {
+		expectLock()
+		expectVersion(5, false)
+		expectUnlock()
+
+		Expect(migrator.Up(context.Background())).To(Succeed())
+	})
+
+	It("reverts the last applied migration", func() {
+		expectLock()
+		expectVersion(5, false)
+		expectStep("DROP TABLE c", 2)
+		expectUnlock()
+
+		Expect(migrator.Down(context.Background())).To(Succeed())
+	})
+
+	It("reverts migrations in reverse order of versions down to the given one", func() {
+		expectLock()
+		expectVersion(5, false)
+		expectStep("DROP TABLE c", 2)
+		expectStep("DROP TABLE b", 1)
+		expectStep("DROP TABLE a", 0)
+		expectUnlock()
+
+		Expect(migrator.Goto(context.Background(), 0)).To(Succeed())
+	})
+
+	It("rolls back failed step, stops and releases lock", func() {
+		expectLock()
+		expectVersion(0, false)
+		expectStep("CREATE TABLE a", 1)
+		mock.ExpectBegin()
+		mock.ExpectExec(`^CREATE TABLE b$`).WillReturnError(errors.New("syntax error"))
+		mock.ExpectRollback()
+		expectUnlock()
+
+		err := migrator.Up(context.Background())
+		Expect(err).To(MatchError("migrations: cannot migrate from version 1 to 2: syntax error"))
+	})
+
+	It("refuses to migrate dirty database", func() {
+		expectLock()
+		expectVersion(2, true)
+		expectUnlock()
+
+		err := migrator.Up(context.Background())
+		Expect(err).To(MatchError("migrations: database is dirty at version 2, fix it manually"))
+	})
+
+	It("refuses to migrate database of unknown version", func() {
+		expectLock()
+		expectVersion(3, false)
+		expectUnlock()
+
+		err := migrator.Up(context.Background())
+		Expect(err).To(MatchError("migrations: database has unknown version 3"))
+	})
+
+	It("gives error if lock cannot be taken", func() {
+		mock.ExpectExec(qLock).WithArgs(lockKey).WillReturnError(errors.New("timeout"))
+
+		err := migrator.Up(context.Background())
+		Expect(err).To(MatchError("migrations: cannot take lock: timeout"))
+	})
+
+	It("rejects unknown target version without touching database", func() {
+		err := migrator.Goto(context.Background(), 3)
+		Expect(err).To(MatchError("migrations: unknown version 3"))
+	})
+
+	It("gives status of applied and pending migrations", func() {
+		mock.ExpectExec(qTable).WillReturnResult(sqlmock.NewResult(0, 0))
+		expectVersion(2, false)
+
+		status, err := migrator.Status(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Version).To(Equal(int64(2)))
+		Expect(status.Dirty).To(BeFalse())
+		Expect(status.Applied).To(HaveLen(2))
+		Expect(status.Pending).To(HaveLen(1))
+		Expect(status.Pending[0].Version).To(Equal(int64(5)))
+	})
+})
+
+var _ = Describe("HealthCheck", func() {
+	It("pings the database", func() {
+		conn, _, err := sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		check := db.NewHealthCheck(conn).Check
+		Expect(check.Name).To(Equal("database"))
+		Expect(check.Liveness).To(BeFalse())
+		Expect(check.Check(context.Background())).To(Succeed())
+
+		conn.Close()
+		Expect(check.Check(context.Background())).To(MatchError("sql: database is closed"))
+	})
+})
@@ -0,0 +1,18 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/reviz0r/golang-layout/pkg/health"
+)
+
+// NewHealthCheck gives readiness check which pings the database
+func NewHealthCheck(conn *sql.DB) health.CheckResult {
+	return health.CheckResult{Check: health.Check{
+		Name: "database",
+		Check: func(ctx context.Context) error {
+			return conn.PingContext(ctx)
+		},
+	}}
+}
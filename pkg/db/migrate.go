@@ -0,0 +1,316 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	"go.uber.org/fx"
+
+	"github.com/reviz0r/golang-layout/migrations"
+	"github.com/reviz0r/golang-layout/pkg/config"
+)
+
+// migrationsLockKey is key of advisory lock held while migrations are applied
+const migrationsLockKey int64 = 4317061527
+
+// migrationsTable is the same as golang-migrate uses, so both tools can migrate the same database
+const migrationsTable = `CREATE TABLE IF NOT EXISTS "schema_migrations" (
+	"version" bigint NOT NULL PRIMARY KEY,
+	"dirty"   boolean NOT NULL
+)`
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is a step of database schema
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus is the current state of database schema
+type MigrationStatus struct {
+	// Version is version of the last applied migration, 0 if there are no applied migrations
+	Version int64
+	// Dirty is set if migration failed without rollback, it must be fixed manually
+	Dirty   bool
+	Applied []*Migration
+	Pending []*Migration
+}
+
+// Migrator applies migrations embedded into the binary
+type Migrator struct {
+	db         *sql.DB
+	migrations []*Migration
+	logger     *logrus.Entry
+}
+
+// NewMigrator gives migrator of the database schema
+func NewMigrator(conn *sql.DB, logger *logrus.Entry) (*Migrator, error) {
+	list, err := ParseMigrations(migrations.Files())
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMigratorWithMigrations(conn, list, logger), nil
+}
+
+// NewMigratorWithMigrations gives migrator applying the given migrations ordered by version
+func NewMigratorWithMigrations(conn *sql.DB, list []*Migration, logger *logrus.Entry) *Migrator {
+	return &Migrator{db: conn, migrations: list, logger: logger}
+}
+
+// ParseMigrations gives migrations ordered by version from files named as <version>_<name>.<up|down>.sql
+func ParseMigrations(files map[string]string) ([]*Migration, error) {
+	byVersion := make(map[int64]*Migration)
+	for name, content := range files {
+		match := migrationFileName.FindStringSubmatch(name)
+		if match == nil {
+			return nil, fmt.Errorf("migrations: invalid file name %q", name)
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migrations: invalid version of %q", name)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migrations: version %d has different names %q and %q", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = content
+		} else {
+			m.Down = content
+		}
+	}
+
+	list := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		list = append(list, m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+
+	return list, nil
+}
+
+// RunMigrationsOnStart applies pending migrations on start if database.migrate_on_start is set
+func RunMigrationsOnStart(lc fx.Lifecycle, config config.DatabaseConfig, migrator *Migrator) {
+	if !config.MigrateOnStart {
+		return
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if err := migrator.Up(ctx); err != nil {
+				return fmt.Errorf("database: cannot apply migrations: %v", err)
+			}
+			return nil
+		},
+	})
+}
+
+// Up applies all pending migrations
+func (m *Migrator) Up(ctx context.Context) error {
+	var last int64
+	if len(m.migrations) != 0 {
+		last = m.migrations[len(m.migrations)-1].Version
+	}
+
+	return m.Goto(ctx, last)
+}
+
+// Down reverts the last applied migration
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		current, err := m.version(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if current == 0 {
+			return nil
+		}
+
+		return m.migrate(ctx, conn, current, m.previous(current))
+	})
+}
+
+// Goto applies or reverts migrations up to the given version, version 0 reverts all migrations
+func (m *Migrator) Goto(ctx context.Context, version int64) error {
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf("migrations: unknown version %d", version)
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		current, err := m.version(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		return m.migrate(ctx, conn, current, version)
+	})
+}
+
+// Status gives the current version and applied and pending migrations
+func (m *Migrator) Status(ctx context.Context) (*MigrationStatus, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, migrationsTable); err != nil {
+		return nil, err
+	}
+
+	status := &MigrationStatus{}
+	err = conn.QueryRowContext(ctx, `SELECT "version", "dirty" FROM "schema_migrations" LIMIT 1`).
+		Scan(&status.Version, &status.Dirty)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	for _, migration := range m.migrations {
+		if migration.Version <= status.Version {
+			status.Applied = append(status.Applied, migration)
+		} else {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+
+	return status, nil
+}
+
+// withLock runs fn holding advisory lock, so migrations of instances started at once do not race
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	// advisory lock belongs to session, so all queries must use the same connection
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationsLockKey); err != nil {
+		return fmt.Errorf("migrations: cannot take lock: %v", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationsLockKey)
+
+	if _, err := conn.ExecContext(ctx, migrationsTable); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// version gives the current version, it fails if database is dirty or version is unknown
+func (m *Migrator) version(ctx context.Context, conn *sql.Conn) (int64, error) {
+	var version int64
+	var dirty bool
+	err := conn.QueryRowContext(ctx, `SELECT "version", "dirty" FROM "schema_migrations" LIMIT 1`).Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if dirty {
+		return 0, fmt.Errorf("migrations: database is dirty at version %d, fix it manually", version)
+	}
+	if m.find(version) == nil {
+		return 0, fmt.Errorf("migrations: database has unknown version %d", version)
+	}
+
+	return version, nil
+}
+
+// migrate applies or reverts migrations between current and target versions one by one
+func (m *Migrator) migrate(ctx context.Context, conn *sql.Conn, current, target int64) error {
+	for current != target {
+		var query string
+		next := current
+		if current < target {
+			migration := m.next(current)
+			query, next = migration.Up, migration.Version
+			m.logger.WithField("version", next).Infof("migrations: applying %s", migration.Name)
+		} else {
+			migration := m.find(current)
+			query, next = migration.Down, m.previous(current)
+			m.logger.WithField("version", current).Infof("migrations: reverting %s", migration.Name)
+		}
+
+		if err := m.step(ctx, conn, query, next); err != nil {
+			return fmt.Errorf("migrations: cannot migrate from version %d to %d: %v", current, next, err)
+		}
+		current = next
+	}
+
+	return nil
+}
+
+// step runs migration query and sets the version in one transaction, so failed step leaves no changes
+func (m *Migrator) step(ctx context.Context, conn *sql.Conn, query string, version int64) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // it does nothing after commit
+
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM "schema_migrations"`); err != nil {
+		return err
+	}
+	if version != 0 {
+		_, err := tx.ExecContext(ctx, `INSERT INTO "schema_migrations" ("version", "dirty") VALUES ($1, false)`, version)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (m *Migrator) find(version int64) *Migration {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration
+		}
+	}
+	return nil
+}
+
+// next gives the first migration after version
+func (m *Migrator) next(version int64) *Migration {
+	for _, migration := range m.migrations {
+		if migration.Version > version {
+			return migration
+		}
+	}
+	return nil
+}
+
+// previous gives version of migration before the given one, or 0 if it is the first one
+func (m *Migrator) previous(version int64) int64 {
+	var previous int64
+	for _, migration := range m.migrations {
+		if migration.Version >= version {
+			break
+		}
+		previous = migration.Version
+	}
+	return previous
+}
@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"sync"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/fx"
+
+	"github.com/reviz0r/golang-layout/pkg/config"
+)
+
+// Notifier signals subscribers about postgres notifications (NOTIFY)
+type Notifier interface {
+	// Subscribe gives channel which is signaled when notification is sent to the postgres channel,
+	// or when connection was re-established and some notifications could be lost.
+	// Signals are coalesced, so subscriber must fetch all changes it is interested in on every signal.
+	// Returned function cancels subscription.
+	Subscribe(ctx context.Context, channel string) (<-chan struct{}, func(), error)
+}
+
+// NewNotifier gives notifier listening on dedicated connection to the database
+func NewNotifier(lc fx.Lifecycle, config config.DatabaseConfig, logger *logrus.Entry) Notifier {
+	n := &pqNotifier{channels: make(map[string]*notifierChannel)}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			n.listener = pq.NewListener(config.DSN,
+				config.Listener.MinReconnectInterval,
+				config.Listener.MaxReconnectInterval,
+				func(event pq.ListenerEventType, err error) {
+					if err != nil {
+						logger.WithError(err).Warn("database: notifications listener")
+					}
+				})
+
+			go n.dispatch()
+			return nil
+		},
+
+		OnStop: func(ctx context.Context) error {
+			return n.listener.Close()
+		},
+	})
+
+	return n
+}
+
+type pqNotifier struct {
+	listener *pq.Listener
+
+	mu       sync.Mutex
+	channels map[string]*notifierChannel
+}
+
+type notifierChannel struct {
+	// listening is closed when LISTEN is done, err is set if it failed
+	listening chan struct{}
+	err       error
+
+	subscribers map[chan struct{}]struct{}
+}
+
+func (n *pqNotifier) Subscribe(ctx context.Context, channel string) (<-chan struct{}, func(), error) {
+	n.mu.Lock()
+	c, ok := n.channels[channel]
+	if !ok {
+		c = &notifierChannel{listening: make(chan struct{}), subscribers: make(map[chan struct{}]struct{})}
+		n.channels[channel] = c
+
+		// LISTEN blocks until connection is established
+		go func() {
+			err := n.listener.Listen(channel)
+			if err == pq.ErrChannelAlreadyOpen {
+				err = nil
+			}
+
+			n.mu.Lock()
+			c.err = err
+			if err != nil {
+				// let the next subscriber try again
+				delete(n.channels, channel)
+			}
+			n.mu.Unlock()
+			close(c.listening)
+		}()
+	}
+
+	signals := make(chan struct{}, 1)
+	c.subscribers[signals] = struct{}{}
+	n.mu.Unlock()
+
+	cancel := func() {
+		n.mu.Lock()
+		delete(c.subscribers, signals)
+		n.mu.Unlock()
+	}
+
+	select {
+	case <-c.listening:
+	case <-ctx.Done():
+		cancel()
+		return nil, nil, ctx.Err()
+	}
+
+	if c.err != nil {
+		cancel()
+		return nil, nil, c.err
+	}
+
+	return signals, cancel, nil
+}
+
+func (n *pqNotifier) dispatch() {
+	for notification := range n.listener.NotificationChannel() {
+		n.mu.Lock()
+		for channel, c := range n.channels {
+			// nil notification is sent after reconnect
+			if notification == nil || notification.Channel == channel {
+				c.signal()
+			}
+		}
+		n.mu.Unlock()
+	}
+}
+
+func (c *notifierChannel) signal() {
+	for signals := range c.subscribers {
+		select {
+		case signals <- struct{}{}:
+		default:
+			// subscriber is already signaled
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/volatiletech/sqlboiler/boil"
+	"go.uber.org/fx"
+
+	"github.com/reviz0r/golang-layout/pkg/config"
+)
+
+// replicaLagQuery gives replication lag in seconds, it is 0 on primary and on replica which replayed all received WAL
+const replicaLagQuery = `SELECT COALESCE(CASE
+	WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+END, 0)`
+
+// Router routes read-only queries to replicas
+type Router interface {
+	// Reader gives executor for read-only queries: transaction of ctx if it is in transaction,
+	// healthy replica if ctx allows replica reads, or the primary otherwise
+	Reader(ctx context.Context) boil.ContextExecutor
+}
+
+// replicaReadKey is context key which marks that reads may go to replica
+type replicaReadKey struct{}
+
+// WithReplicaRead marks ctx as tolerating stale data, so Router may send its reads to replica
+func WithReplicaRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replicaReadKey{}, true)
+}
+
+// Replica is read-only database connection with its last health status
+type Replica struct {
+	DB *sql.DB
+
+	healthy int32
+}
+
+// Healthy reports if the last check of replica succeeded
+func (r *Replica) Healthy() bool {
+	return atomic.LoadInt32(&r.healthy) == 1
+}
+
+func (r *Replica) setHealthy(healthy bool) {
+	var v int32
+	if healthy {
+		v = 1
+	}
+	atomic.StoreInt32(&r.healthy, v)
+}
+
+// NewRouter opens connections to database.replicas and checks their health and replication lag
+// every database.replica_check_interval. Replica lagging more than database.replica_max_lag is skipped.
+func NewRouter(lc fx.Lifecycle, config config.DatabaseConfig, conn *sql.DB, logger *logrus.Entry) (Router, error) {
+	dsns := config.Replicas
+	replicas := make([]*sql.DB, 0, len(dsns))
+	for _, dsn := range dsns {
+		replica, err := sql.Open("postgres", dsn)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open connection to database replica: %v", err)
+		}
+		replicas = append(replicas, replica)
+	}
+
+	r := NewReplicaRouter(conn, replicas...)
+	r.MaxLag = config.ReplicaMaxLag
+	if len(replicas) == 0 {
+		return r, nil
+	}
+
+	interval := config.ReplicaCheckInterval
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(startCtx context.Context) error {
+			r.Check(startCtx, logger)
+
+			go func() {
+				defer close(done)
+
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						r.Check(ctx, logger)
+					}
+				}
+			}()
+
+			return nil
+		},
+
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+
+			for _, replica := range r.Replicas {
+				if err := replica.DB.Close(); err != nil {
+					return fmt.Errorf("database: cannot close replica connection: %v", err)
+				}
+			}
+			return nil
+		},
+	})
+
+	return r, nil
+}
+
+// NewReplicaRouter gives router over given connections, replicas are healthy until they are checked
+func NewReplicaRouter(primary *sql.DB, replicas ...*sql.DB) *ReplicaRouter {
+	r := &ReplicaRouter{Primary: primary}
+	for _, conn := range replicas {
+		r.Replicas = append(r.Replicas, &Replica{DB: conn, healthy: 1})
+	}
+
+	return r
+}
+
+// ReplicaRouter sends reads to healthy replicas in round-robin, and to primary if there is no one
+type ReplicaRouter struct {
+	Primary  *sql.DB
+	Replicas []*Replica
+	// MaxLag is replication lag making replica unhealthy, 0 means any lag
+	MaxLag time.Duration
+
+	next uint32
+}
+
+func (r *ReplicaRouter) Reader(ctx context.Context) boil.ContextExecutor {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.tx
+	}
+	if replicaRead, _ := ctx.Value(replicaReadKey{}).(bool); !replicaRead {
+		return r.Primary
+	}
+
+	for range r.Replicas {
+		replica := r.Replicas[int(atomic.AddUint32(&r.next, 1)-1)%len(r.Replicas)]
+		if replica.Healthy() {
+			return replica.DB
+		}
+	}
+
+	return r.Primary
+}
+
+// Check updates health of replicas, replica is healthy if it responds and its lag is within MaxLag
+func (r *ReplicaRouter) Check(ctx context.Context, logger *logrus.Entry) {
+	for i, replica := range r.Replicas {
+		var lag float64
+		err := replica.DB.QueryRowContext(ctx, replicaLagQuery).Scan(&lag)
+		if err == nil && r.MaxLag != 0 && time.Duration(lag*float64(time.Second)) > r.MaxLag {
+			err = fmt.Errorf("replication lag %.3fs exceeds %s", lag, r.MaxLag)
+		}
+
+		if err != nil && replica.Healthy() {
+			logger.WithError(err).WithField("replica", i).Warn("database: replica is unhealthy")
+		}
+		if err == nil && !replica.Healthy() {
+			logger.WithField("replica", i).Info("database: replica is healthy")
+		}
+		replica.setHealthy(err == nil)
+	}
+}
@@ -0,0 +1,194 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/volatiletech/sqlboiler/boil"
+
+	"github.com/reviz0r/golang-layout/pkg/config"
+)
+
+// postgres codes of errors after which transaction can be retried
+var retryableCodes = map[pq.ErrorCode]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+}
+
+// TxManager runs functions in transactions (unit of work)
+type TxManager interface {
+	// WithinTx runs fn in transaction stored in ctx given to fn, it is committed if fn returns nil
+	// and rolled back otherwise. Transaction is retried from the start on serialization failure
+	// or deadlock, so fn must be safe to run again. Nested call runs fn in savepoint of the outer
+	// transaction, opts of nested call are ignored.
+	WithinTx(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) error
+
+	// Executor gives transaction of ctx, or the database if ctx is not in transaction.
+	// It is executor for sqlboiler calls which must join the current unit of work.
+	Executor(ctx context.Context) boil.ContextExecutor
+}
+
+// RetryPolicy bounds retries of failed transactions
+type RetryPolicy struct {
+	// MaxRetries is number of retries after the first attempt, 0 disables retries
+	MaxRetries int
+	// delay is MinBackoff doubled on every retry up to MaxBackoff, with random jitter
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// NewTxManager gives transaction manager with retry policy from database.tx config
+func NewTxManager(conn *sql.DB, config config.DatabaseConfig) TxManager {
+	return NewTxManagerWithPolicy(conn, RetryPolicy{
+		MaxRetries: config.Tx.MaxRetries,
+		MinBackoff: config.Tx.MinBackoff,
+		MaxBackoff: config.Tx.MaxBackoff,
+	})
+}
+
+// NewTxManagerWithPolicy gives transaction manager with given retry policy
+func NewTxManagerWithPolicy(conn *sql.DB, policy RetryPolicy) TxManager {
+	return &txManager{db: conn, policy: policy}
+}
+
+type txManager struct {
+	db     *sql.DB
+	policy RetryPolicy
+}
+
+// txKey is context key of transaction state, value is *txState
+type txKey struct{}
+
+type txState struct {
+	tx *sql.Tx
+	// savepoints is number of savepoints created in the transaction, it names the next one
+	savepoints int
+}
+
+func (m *txManager) Executor(ctx context.Context) boil.ContextExecutor {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.tx
+	}
+	return m.db
+}
+
+func (m *txManager) WithinTx(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) error {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return m.withinSavepoint(ctx, state, fn)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := m.runTx(ctx, opts, attempt, fn)
+		if err == nil || !isRetryable(err) || attempt >= m.policy.MaxRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(m.backoff(attempt)):
+		}
+	}
+}
+
+func (m *txManager) runTx(ctx context.Context, opts *sql.TxOptions, attempt int, fn func(ctx context.Context) error) (err error) {
+	span, ctx := startSpan(ctx, "db.Tx")
+	span.SetTag("db.tx.attempt", attempt)
+	defer finishSpan(span, &err)
+
+	tx, err := m.db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // it does nothing after commit or rollback, but rolls back if fn panics
+
+	if err := fn(context.WithValue(ctx, txKey{}, &txState{tx: tx})); err != nil {
+		traced(ctx, "db.Rollback", tx.Rollback)
+		return err
+	}
+
+	return traced(ctx, "db.Commit", tx.Commit)
+}
+
+func (m *txManager) withinSavepoint(ctx context.Context, state *txState, fn func(ctx context.Context) error) (err error) {
+	state.savepoints++
+	name := fmt.Sprintf("sp_%d", state.savepoints)
+
+	span, ctx := startSpan(ctx, "db.Savepoint")
+	span.SetTag("db.savepoint", name)
+	defer finishSpan(span, &err)
+
+	if _, err := state.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	if err := fn(ctx); err != nil {
+		traced(ctx, "db.RollbackToSavepoint", func() error {
+			_, err := state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+			return err
+		})
+		return err
+	}
+
+	return traced(ctx, "db.ReleaseSavepoint", func() error {
+		_, err := state.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+		return err
+	})
+}
+
+// backoff gives delay before retry after the given attempt
+func (m *txManager) backoff(attempt int) time.Duration {
+	backoff := m.policy.MinBackoff
+	for i := 0; i < attempt && backoff < m.policy.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > m.policy.MaxBackoff {
+		backoff = m.policy.MaxBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+
+	// jitter spreads retries of conflicting transactions
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// isRetryable reports if transaction failed on serialization failure or deadlock
+func isRetryable(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && retryableCodes[pqErr.Code]
+}
+
+// startSpan starts child span of the span in ctx, it is noop if there is no span (tracing is not installed)
+func startSpan(ctx context.Context, operation string) (opentracing.Span, context.Context) {
+	tracer := opentracing.Tracer(opentracing.NoopTracer{})
+	if parent := opentracing.SpanFromContext(ctx); parent != nil {
+		tracer = parent.Tracer()
+	}
+
+	return opentracing.StartSpanFromContextWithTracer(ctx, tracer, operation)
+}
+
+func finishSpan(span opentracing.Span, err *error) {
+	if *err != nil {
+		ext.Error.Set(span, true)
+		span.LogFields(log.Error(*err))
+	}
+	span.Finish()
+}
+
+// traced runs fn in its own span
+func traced(ctx context.Context, operation string, fn func() error) (err error) {
+	span, _ := startSpan(ctx, operation)
+	defer finishSpan(span, &err)
+
+	return fn()
+}
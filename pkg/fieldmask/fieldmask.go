@@ -0,0 +1,125 @@
+package fieldmask
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// Mapping maps field mask paths of proto message to database columns
+type Mapping map[string]string
+
+// Validate checks that all paths are known
+func (m Mapping) Validate(paths []string) error {
+	for _, path := range paths {
+		if _, ok := m[path]; !ok {
+			return fmt.Errorf("unknown field mask path %q", path)
+		}
+	}
+	return nil
+}
+
+// Columns gives database columns for paths, or error if some path is unknown
+func (m Mapping) Columns(paths []string) ([]string, error) {
+	if err := m.Validate(paths); err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, 0, len(paths))
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		column := m[path]
+		if seen[column] {
+			continue
+		}
+		seen[column] = true
+		columns = append(columns, column)
+	}
+
+	return columns, nil
+}
+
+// Trim clears all fields of message which are not covered by paths.
+// Nested messages are trimmed by dotted paths, e.g. "address.city".
+// Empty paths keep message as is.
+func Trim(msg proto.Message, paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+
+	v := reflect.ValueOf(msg)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return
+	}
+
+	trim(v.Elem(), newTree(paths))
+}
+
+// tree is a set of paths, nil subtree means the whole field is kept
+type tree map[string]tree
+
+func newTree(paths []string) tree {
+	root := make(tree)
+	for _, path := range paths {
+		node := root
+		parts := strings.Split(path, ".")
+		for i, part := range parts {
+			child, ok := node[part]
+			if ok && child == nil {
+				// parent field is already kept as a whole
+				break
+			}
+			if i == len(parts)-1 {
+				node[part] = nil
+				break
+			}
+			if !ok {
+				child = make(tree)
+				node[part] = child
+			}
+			node = child
+		}
+	}
+	return root
+}
+
+func trim(v reflect.Value, keep tree) {
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	props := proto.GetProperties(v.Type())
+	for _, p := range props.Prop {
+		if strings.HasPrefix(p.Name, "XXX_") {
+			continue
+		}
+
+		f := v.FieldByName(p.Name)
+		sub, ok := keep[p.OrigName]
+		switch {
+		case !ok:
+			f.Set(reflect.Zero(f.Type()))
+		case sub != nil:
+			trimNested(f, sub)
+		}
+	}
+}
+
+func trimNested(f reflect.Value, keep tree) {
+	switch f.Kind() {
+	case reflect.Ptr:
+		if !f.IsNil() {
+			trim(f.Elem(), keep)
+		}
+	case reflect.Slice:
+		for i := 0; i < f.Len(); i++ {
+			trimNested(f.Index(i), keep)
+		}
+	case reflect.Map:
+		for _, key := range f.MapKeys() {
+			trimNested(f.MapIndex(key), keep)
+		}
+	}
+}
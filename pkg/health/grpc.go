@@ -0,0 +1,84 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// watchInterval is interval of checks while health is watched
+const watchInterval = time.Second
+
+// RegisterHealthServer registers grpc.health.v1.Health service on grpc server.
+// Status of empty service and of services of the server is readiness of the app.
+func RegisterHealthServer(s *grpc.Server, h *Health) {
+	healthpb.RegisterHealthServer(s, &healthServer{health: h, server: s})
+}
+
+type healthServer struct {
+	health *Health
+	server *grpc.Server
+}
+
+func (s *healthServer) Check(ctx context.Context, in *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if !s.known(in.GetService()) {
+		return nil, status.Errorf(codes.NotFound, "Health.Check: unknown service %q", in.GetService())
+	}
+
+	return &healthpb.HealthCheckResponse{Status: s.status(ctx)}, nil
+}
+
+// Watch sends status on every change, it ends with NOT_SERVING when app is stopping,
+// so graceful stop of grpc server does not wait for watchers
+func (s *healthServer) Watch(in *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	ctx := stream.Context()
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		current := healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+		if s.known(in.GetService()) {
+			current = s.status(ctx)
+		}
+
+		if current != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: current}); err != nil {
+				return err
+			}
+			last = current
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-s.health.stopped:
+			if last != healthpb.HealthCheckResponse_NOT_SERVING {
+				return stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING})
+			}
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *healthServer) known(service string) bool {
+	if service == "" {
+		return true
+	}
+
+	_, ok := s.server.GetServiceInfo()[service]
+	return ok
+}
+
+func (s *healthServer) status(ctx context.Context) healthpb.HealthCheckResponse_ServingStatus {
+	if !s.health.Ready(ctx).OK() {
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	return healthpb.HealthCheckResponse_SERVING
+}
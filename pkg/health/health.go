@@ -0,0 +1,159 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.uber.org/fx"
+
+	"github.com/reviz0r/golang-layout/pkg/config"
+)
+
+// Module register health checks service, /healthz and /readyz handlers and grpc.health.v1.Health service
+var Module = fx.Options(
+	fx.Provide(NewHealth),
+	fx.Invoke(RegisterHealthHandlers, RegisterHealthServer),
+)
+
+// Check is health check of a dependency or a part of the app
+type Check struct {
+	Name string
+	// Liveness checks fail liveness and readiness, other checks fail readiness only
+	Liveness bool
+	Check    func(ctx context.Context) error
+}
+
+// CheckResult registers health check in DI container
+type CheckResult struct {
+	fx.Out
+
+	Check Check `group:"health_checks"`
+}
+
+// Params .
+type Params struct {
+	fx.In
+
+	Checks []Check `group:"health_checks"`
+}
+
+// Report is result of checks, Checks has error of every failed check and "ok" for others
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// OK reports if all checks passed
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+// statuses of Report
+const (
+	StatusOK       = "ok"
+	StatusFail     = "fail"
+	StatusStopping = "stopping"
+)
+
+// Health runs registered checks
+type Health struct {
+	checks []Check
+	config config.HealthConfig
+
+	stopping int32
+	// stopped is closed when app is stopping, so watchers of health end
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// NewHealth gives service of health checks, readiness fails on app stop before servers are stopped
+func NewHealth(lc fx.Lifecycle, config config.HealthConfig, logger *logrus.Entry, p Params) *Health {
+	checks := append([]Check(nil), p.Checks...)
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Name < checks[j].Name })
+
+	h := &Health{checks: checks, config: config, stopped: make(chan struct{})}
+
+	// servers are registered before health, so this hook is run before they are stopped
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			h.Stop()
+			logger.Info("health: app is not ready, it is stopping")
+
+			if config.ShutdownDelay > 0 {
+				select {
+				case <-time.After(config.ShutdownDelay):
+				case <-ctx.Done():
+				}
+			}
+			return nil
+		},
+	})
+
+	return h
+}
+
+// Stop fails readiness, it is called on app stop
+func (h *Health) Stop() {
+	h.once.Do(func() {
+		atomic.StoreInt32(&h.stopping, 1)
+		close(h.stopped)
+	})
+}
+
+// Stopping reports if app is stopping
+func (h *Health) Stopping() bool {
+	return atomic.LoadInt32(&h.stopping) == 1
+}
+
+// Live runs liveness checks
+func (h *Health) Live(ctx context.Context) Report {
+	return h.run(ctx, true)
+}
+
+// Ready runs all checks, app is not ready while it is stopping
+func (h *Health) Ready(ctx context.Context) Report {
+	if h.Stopping() {
+		return Report{Status: StatusStopping}
+	}
+	return h.run(ctx, false)
+}
+
+// run runs checks concurrently within timeout
+func (h *Health) run(ctx context.Context, liveness bool) Report {
+	ctx, cancel := context.WithTimeout(ctx, h.config.Timeout)
+	defer cancel()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]string)}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range h.checks {
+		if liveness && !check.Liveness {
+			continue
+		}
+
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+
+			result := StatusOK
+			if err := check.Check(ctx); err != nil {
+				result = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.Name] = result
+			if result != StatusOK {
+				report.Status = StatusFail
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	return report
+}
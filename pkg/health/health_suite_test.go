@@ -0,0 +1,244 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/reviz0r/golang-layout/pkg/config"
+	"github.com/reviz0r/golang-layout/pkg/health"
+	"github.com/reviz0r/golang-layout/pkg/mockserver"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestHealth(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Health Suite")
+}
+
+// check gives health check returning err
+func check(name string, liveness bool, err error) health.Check {
+	return health.Check{Name: name, Liveness: liveness, Check: func(context.Context) error { return err }}
+}
+
+func newHealth(lc fx.Lifecycle, c config.HealthConfig, checks ...health.Check) *health.Health {
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	return health.NewHealth(lc, c, logrus.NewEntry(logger), health.Params{Checks: checks})
+}
+
+var _ = Describe("Health", func() {
+	var lc *fxtest.Lifecycle
+
+	BeforeEach(func() {
+		lc = fxtest.NewLifecycle(GinkgoT())
+	})
+
+	It("runs liveness checks for liveness and all checks for readiness", func() {
+		h := newHealth(lc, config.HealthConfig{Timeout: time.Second},
+			check("process", true, nil),
+			check("database", false, errors.New("connection refused")))
+
+		Expect(h.Live(context.Background())).To(Equal(health.Report{
+			Status: health.StatusOK,
+			Checks: map[string]string{"process": "ok"},
+		}))
+		Expect(h.Ready(context.Background())).To(Equal(health.Report{
+			Status: health.StatusFail,
+			Checks: map[string]string{"process": "ok", "database": "connection refused"},
+		}))
+	})
+
+	It("fails check which does not end within timeout", func() {
+		h := newHealth(lc, config.HealthConfig{Timeout: 50 * time.Millisecond}, health.Check{
+			Name: "slow",
+			Check: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		})
+
+		start := time.Now()
+		report := h.Ready(context.Background())
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		Expect(report.OK()).To(BeFalse())
+		Expect(report.Checks).To(HaveKeyWithValue("slow", context.DeadlineExceeded.Error()))
+	})
+
+	It("fails readiness on stop before servers are stopped", func() {
+		var readyOnServerStop health.Report
+		var h *health.Health
+		// servers are registered before health, so they are stopped after it
+		lc.Append(fx.Hook{OnStop: func(ctx context.Context) error {
+			readyOnServerStop = h.Ready(ctx)
+			return nil
+		}})
+		h = newHealth(lc, config.HealthConfig{Timeout: time.Second}, check("process", true, nil))
+
+		lc.RequireStart()
+		Expect(h.Ready(context.Background()).OK()).To(BeTrue())
+		lc.RequireStop()
+
+		Expect(h.Stopping()).To(BeTrue())
+		Expect(readyOnServerStop).To(Equal(health.Report{Status: health.StatusStopping}))
+		Expect(h.Live(context.Background()).OK()).To(BeTrue())
+	})
+
+	It("waits for shutdown delay on stop", func() {
+		h := newHealth(lc, config.HealthConfig{Timeout: time.Second, ShutdownDelay: 100 * time.Millisecond})
+
+		lc.RequireStart()
+		start := time.Now()
+		lc.RequireStop()
+
+		Expect(time.Since(start)).To(BeNumerically(">=", 100*time.Millisecond))
+		Expect(h.Stopping()).To(BeTrue())
+	})
+})
+
+var _ = Describe("HTTP handlers", func() {
+	var (
+		h   *health.Health
+		mux *http.ServeMux
+	)
+
+	BeforeEach(func() {
+		h = newHealth(fxtest.NewLifecycle(GinkgoT()), config.HealthConfig{Timeout: time.Second},
+			check("process", true, nil),
+			check("database", false, errors.New("connection refused")))
+		mux = http.NewServeMux()
+		health.RegisterHealthHandlers(mux, h)
+	})
+
+	get := func(path string) (int, health.Report) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(rec.Header().Get("Cache-Control")).To(Equal("no-store"))
+
+		var report health.Report
+		Expect(json.Unmarshal(rec.Body.Bytes(), &report)).To(Succeed())
+		return rec.Code, report
+	}
+
+	It("serves liveness with 200 if liveness checks passed", func() {
+		code, report := get("/healthz")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(report.Status).To(Equal(health.StatusOK))
+	})
+
+	It("serves readiness with 503 if any check failed", func() {
+		code, report := get("/readyz")
+		Expect(code).To(Equal(http.StatusServiceUnavailable))
+		Expect(report.Checks).To(HaveKeyWithValue("database", "connection refused"))
+	})
+
+	It("serves readiness with 503 while app is stopping", func() {
+		h.Stop()
+
+		code, report := get("/readyz")
+		Expect(code).To(Equal(http.StatusServiceUnavailable))
+		Expect(report.Status).To(Equal(health.StatusStopping))
+
+		code, _ = get("/healthz")
+		Expect(code).To(Equal(http.StatusOK))
+	})
+})
+
+var _ = Describe("grpc.health.v1.Health", func() {
+	var (
+		lc     *fxtest.Lifecycle
+		h      *health.Health
+		client healthpb.HealthClient
+	)
+
+	start := func(checks ...health.Check) {
+		lc = fxtest.NewLifecycle(GinkgoT())
+		s, conn, err := mockserver.NewServer(lc, mockserver.ServerParams{})
+		Expect(err).NotTo(HaveOccurred())
+
+		h = newHealth(lc, config.HealthConfig{Timeout: time.Second}, checks...)
+		health.RegisterHealthServer(s, h)
+		client = healthpb.NewHealthClient(conn)
+
+		lc.RequireStart()
+	}
+
+	AfterEach(func() {
+		lc.RequireStop()
+	})
+
+	It("gives SERVING for the app and services of the server if app is ready", func() {
+		start(check("process", true, nil))
+
+		for _, service := range []string{"", "grpc.health.v1.Health"} {
+			res, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.GetStatus()).To(Equal(healthpb.HealthCheckResponse_SERVING))
+		}
+	})
+
+	It("gives NOT_SERVING if app is not ready", func() {
+		start(check("database", false, errors.New("connection refused")))
+
+		res, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.GetStatus()).To(Equal(healthpb.HealthCheckResponse_NOT_SERVING))
+	})
+
+	It("gives NotFound for unknown service", func() {
+		start()
+
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "profile.Unknown"})
+		Expect(status.Code(err)).To(Equal(codes.NotFound))
+		Expect(status.Convert(err).Message()).To(Equal(`Health.Check: unknown service "profile.Unknown"`))
+	})
+
+	It("ends watch with NOT_SERVING when app is stopping", func() {
+		start()
+
+		stream, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+		Expect(err).NotTo(HaveOccurred())
+
+		res, err := stream.Recv()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.GetStatus()).To(Equal(healthpb.HealthCheckResponse_SERVING))
+
+		h.Stop()
+
+		res, err = stream.Recv()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.GetStatus()).To(Equal(healthpb.HealthCheckResponse_NOT_SERVING))
+
+		_, err = stream.Recv()
+		Expect(err).To(Equal(io.EOF))
+	})
+
+	It("watches unknown service as SERVICE_UNKNOWN", func() {
+		start()
+
+		stream, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{Service: "profile.Unknown"})
+		Expect(err).NotTo(HaveOccurred())
+
+		res, err := stream.Recv()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.GetStatus()).To(Equal(healthpb.HealthCheckResponse_SERVICE_UNKNOWN))
+	})
+})
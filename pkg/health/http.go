@@ -0,0 +1,30 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// RegisterHealthHandlers serves liveness at /healthz and readiness at /readyz,
+// status is 200 if checks passed and 503 otherwise
+func RegisterHealthHandlers(mux *http.ServeMux, h *Health) {
+	mux.Handle("/healthz", reportHandler(h.Live))
+	mux.Handle("/readyz", reportHandler(h.Ready))
+}
+
+func reportHandler(probe func(ctx context.Context) Report) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := probe(r.Context())
+
+		code := http.StatusOK
+		if !report.OK() {
+			code = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(report)
+	})
+}
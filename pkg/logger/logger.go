@@ -6,43 +6,31 @@ import (
 	"os"
 
 	"github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
 	"go.uber.org/fx"
+
+	"github.com/reviz0r/golang-layout/pkg/config"
 )
 
 // Module register logger in DI container
 var Module = fx.Provide(NewLogger, logrus.NewEntry)
 
+// ReloadModule applies changes of logger level and formatter without restart
+var ReloadModule = fx.Invoke(ReloadOnChange)
+
 // NewLogger gives new predefined logger
-func NewLogger(lc fx.Lifecycle, config *viper.Viper) (*logrus.Logger, error) {
+func NewLogger(lc fx.Lifecycle, config config.LoggerConfig) (*logrus.Logger, error) {
 	logger := logrus.New()
 
-	if logFormatter := config.GetString("logger.formatter"); logFormatter != "" {
-		switch logFormatter {
-		case "text":
-			logger.SetFormatter(new(logrus.TextFormatter))
-		case "json":
-			logger.SetFormatter(new(logrus.JSONFormatter))
-		default:
-			return nil, errors.New("unknown formatter")
-		}
-	}
-
-	if logLevel := config.GetString("logger.level"); logLevel != "" {
-		level, err := logrus.ParseLevel(logLevel)
-		if err != nil {
-			return nil, err
-		}
-
-		logger.SetLevel(level)
+	if err := configure(logger, config); err != nil {
+		return nil, err
 	}
 
-	if config.GetBool("logger.no_lock") {
+	if config.NoLock {
 		logger.SetNoLock()
 	}
 
 	var outputFile *os.File
-	if fileName := config.GetString("logger.output_file"); fileName != "" {
+	if fileName := config.OutputFile; fileName != "" {
 		var err error
 
 		outputFile, err = os.OpenFile(fileName, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
@@ -65,3 +53,44 @@ func NewLogger(lc fx.Lifecycle, config *viper.Viper) (*logrus.Logger, error) {
 
 	return logger, nil
 }
+
+// ReloadOnChange switches level and formatter of logger when they are changed in config
+func ReloadOnChange(w *config.Watcher, logger *logrus.Logger) {
+	w.Subscribe(func(change config.Change) {
+		if !change.Changed("logger.level") && !change.Changed("logger.formatter") {
+			return
+		}
+
+		if err := configure(logger, change.New.Logger); err != nil {
+			logger.WithError(err).Error("logger: cannot apply config")
+			return
+		}
+		logger.Infof("logger: config is applied, level is %s", logger.GetLevel())
+	})
+}
+
+// configure sets formatter and level of logger, empty values give defaults of logrus
+func configure(logger *logrus.Logger, config config.LoggerConfig) error {
+	var formatter logrus.Formatter
+	switch config.Formatter {
+	case "", "text":
+		formatter = new(logrus.TextFormatter)
+	case "json":
+		formatter = new(logrus.JSONFormatter)
+	default:
+		return errors.New("unknown formatter")
+	}
+
+	level := logrus.InfoLevel
+	if config.Level != "" {
+		var err error
+		level, err = logrus.ParseLevel(config.Level)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.SetFormatter(formatter)
+	logger.SetLevel(level)
+	return nil
+}
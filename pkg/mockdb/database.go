@@ -6,10 +6,12 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"go.uber.org/fx"
+
+	"github.com/reviz0r/golang-layout/pkg/db"
 )
 
-// Module register mock database connection in DI container
-var Module = fx.Provide(NewDatabase)
+// Module register mock database connection, router, transaction manager and notifier in DI container
+var Module = fx.Provide(NewDatabase, NewRouter, NewTxManager, NewNotifier)
 
 // NewDatabase gives new mocked database connection
 func NewDatabase(lc fx.Lifecycle) (*sql.DB, sqlmock.Sqlmock, error) {
@@ -26,3 +28,13 @@ func NewDatabase(lc fx.Lifecycle) (*sql.DB, sqlmock.Sqlmock, error) {
 
 	return db, mock, nil
 }
+
+// NewRouter gives router without replicas, all queries go to mocked database
+func NewRouter(conn *sql.DB) db.Router {
+	return db.NewReplicaRouter(conn)
+}
+
+// NewTxManager gives transaction manager over mocked database, failed transactions are not retried
+func NewTxManager(conn *sql.DB) db.TxManager {
+	return db.NewTxManagerWithPolicy(conn, db.RetryPolicy{})
+}
@@ -0,0 +1,53 @@
+package mockdb
+
+import (
+	"context"
+	"sync"
+
+	"github.com/reviz0r/golang-layout/pkg/db"
+)
+
+// Notifier is in-memory db.Notifier, Notify signals its subscribers
+type Notifier struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan struct{}]struct{}
+}
+
+// NewNotifier gives new mocked notifier
+func NewNotifier() (*Notifier, db.Notifier) {
+	n := &Notifier{subscribers: make(map[string]map[chan struct{}]struct{})}
+	return n, n
+}
+
+// Subscribe implements db.Notifier
+func (n *Notifier) Subscribe(ctx context.Context, channel string) (<-chan struct{}, func(), error) {
+	signals := make(chan struct{}, 1)
+
+	n.mu.Lock()
+	if n.subscribers[channel] == nil {
+		n.subscribers[channel] = make(map[chan struct{}]struct{})
+	}
+	n.subscribers[channel][signals] = struct{}{}
+	n.mu.Unlock()
+
+	cancel := func() {
+		n.mu.Lock()
+		delete(n.subscribers[channel], signals)
+		n.mu.Unlock()
+	}
+
+	return signals, cancel, nil
+}
+
+// Notify signals subscribers of the channel as postgres NOTIFY does
+func (n *Notifier) Notify(channel string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for signals := range n.subscribers[channel] {
+		select {
+		case signals <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package mockoutbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/reviz0r/golang-layout/pkg/outbox"
+)
+
+// ErrFull is returned by Publish of full publisher, rejected messages stay pending in outbox
+var ErrFull = errors.New("mockoutbox: publisher is full")
+
+// Publisher keeps published messages in memory up to its capacity, it is intended for tests only
+type Publisher struct {
+	capacity int
+
+	mu       sync.Mutex
+	messages []*outbox.Message
+	err      error
+}
+
+// NewPublisher gives new empty in-memory publisher keeping up to capacity messages
+func NewPublisher(capacity int) *Publisher {
+	return &Publisher{capacity: capacity}
+}
+
+// Publish implements outbox.Publisher
+func (p *Publisher) Publish(ctx context.Context, msg *outbox.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+	if len(p.messages) >= p.capacity {
+		return ErrFull
+	}
+
+	copied := *msg
+	p.messages = append(p.messages, &copied)
+	return nil
+}
+
+// Messages gives kept messages in order of publishing
+func (p *Publisher) Messages() []*outbox.Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]*outbox.Message(nil), p.messages...)
+}
+
+// Drain gives kept messages in order of publishing and frees space of them
+func (p *Publisher) Drain() []*outbox.Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	messages := p.messages
+	p.messages = nil
+	return messages
+}
+
+// Fail makes publisher reject messages with err, nil err makes it accept them again
+func (p *Publisher) Fail(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.err = err
+}
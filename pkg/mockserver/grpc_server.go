@@ -12,13 +12,20 @@ import (
 // Module register mock grpc server in DI container
 var Module = fx.Provide(NewServer)
 
+// ServerParams .
+type ServerParams struct {
+	fx.In
+
+	ServerOptions []grpc.ServerOption `group:"grpc_server_options"`
+}
+
 // NewServer gives new mocked grpc server
-func NewServer(lc fx.Lifecycle) (*grpc.Server, *grpc.ClientConn, error) {
+func NewServer(lc fx.Lifecycle, p ServerParams) (*grpc.Server, *grpc.ClientConn, error) {
 	const bufSize = 1024 * 1024
 	lis := bufconn.Listen(bufSize)
 
 	// create local grpc server
-	s := grpc.NewServer()
+	s := grpc.NewServer(p.ServerOptions...)
 
 	bufDialer := func(context.Context, string) (net.Conn, error) {
 		return lis.Dial()
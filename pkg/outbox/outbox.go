@@ -0,0 +1,47 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"go.uber.org/fx"
+)
+
+// Module register publisher and relay delivering outbox messages in DI container
+var Module = fx.Options(
+	fx.Provide(NewPublisher),
+	fx.Invoke(RunRelay),
+)
+
+// Statuses of outbox messages
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusDead      = "dead"
+)
+
+// Message is an event stored in outbox until it is published
+type Message struct {
+	ID        int64           `json:"id"`
+	Topic     string          `json:"topic"`
+	Key       string          `json:"key"`
+	Payload   json.RawMessage `json:"payload"`
+	Attempts  int             `json:"attempts"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Executor is satisfied by *sql.DB and *sql.Tx
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Write stores message in outbox, exec must be transaction of the change, so message is
+// published if and only if the change is committed. Payload must be JSON.
+func Write(ctx context.Context, exec Executor, topic, key string, payload []byte) error {
+	_, err := exec.ExecContext(ctx,
+		`INSERT INTO "outbox" ("topic", "key", "payload") VALUES ($1, $2, $3)`,
+		topic, key, string(payload))
+	return err
+}
@@ -0,0 +1,295 @@
+package outbox_test
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sirupsen/logrus"
+
+	"github.com/reviz0r/golang-layout/pkg/db"
+	"github.com/reviz0r/golang-layout/pkg/mockoutbox"
+	"github.com/reviz0r/golang-layout/pkg/outbox"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+func TestOutbox(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Outbox Suite")
+}
+
+// publisherFunc is Publisher calling the function
+type publisherFunc func(ctx context.Context, msg *outbox.Message) error
+
+func (f publisherFunc) Publish(ctx context.Context, msg *outbox.Message) error {
+	return f(ctx, msg)
+}
+
+var _ = Describe("Relay", func() {
+	var (
+		conn      *sql.DB
+		mock      sqlmock.Sqlmock
+		publisher *mockoutbox.Publisher
+		relay     *outbox.Relay
+	)
+
+	columns := []string{"id", "topic", "key", "payload", "attempts", "created_at"}
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	qClaim := `^UPDATE "outbox" SET "next_attempt_at" = now\(\) \+ \$1 \* interval '1 microsecond'\s+` +
+		`WHERE "id" IN \(SELECT "id" FROM "outbox" WHERE "status" = \$2 AND "next_attempt_at" <= now\(\)\s+` +
+		`ORDER BY "id" LIMIT \$3 FOR UPDATE SKIP LOCKED\)\s+` +
+		`RETURNING "id", "topic", "key", "payload", "attempts", "created_at"$`
+	qDelivered := `^UPDATE "outbox" SET "status" = \$1, "attempts" = \$2, "delivered_at" = now\(\) WHERE "id" = \$3$`
+	qRetry := `^UPDATE "outbox" SET "attempts" = \$1, "last_error" = \$2, "next_attempt_at" = now\(\) \+ \$3 \* interval '1 microsecond' WHERE "id" = \$4$`
+	qDead := `^UPDATE "outbox" SET "status" = \$1, "attempts" = \$2, "last_error" = \$3 WHERE "id" = \$4$`
+
+	BeforeEach(func() {
+		var err error
+		conn, mock, err = sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		logger := logrus.New()
+		logger.SetOutput(ioutil.Discard)
+
+		publisher = mockoutbox.NewPublisher(100)
+		relay = &outbox.Relay{
+			Tx:          db.NewTxManagerWithPolicy(conn, db.RetryPolicy{}),
+			Publisher:   publisher,
+			Logger:      logrus.NewEntry(logger),
+			BatchSize:   10,
+			MaxAttempts: 3,
+			MinBackoff:  time.Second,
+			MaxBackoff:  3 * time.Second,
+			Lease:       time.Minute,
+		}
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).NotTo(HaveOccurred())
+		conn.Close()
+	})
+
+	It("claims due messages for the lease and publishes them in order of ids", func() {
+		mock.ExpectQuery(qClaim).WithArgs(time.Minute.Microseconds(), outbox.StatusPending, 10).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(2, "users", "1", []byte(`{"id":2}`), 0, created).
+				AddRow(1, "users", "1", []byte(`{"id":1}`), 0, created))
+		mock.ExpectExec(qDelivered).WithArgs(outbox.StatusDelivered, 1, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(qDelivered).WithArgs(outbox.StatusDelivered, 1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		n, err := relay.Deliver(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(2))
+
+		messages := publisher.Messages()
+		Expect(messages).To(HaveLen(2))
+		Expect(messages[0]).To(Equal(&outbox.Message{
+			ID: 1, Topic: "users", Key: "1", Payload: json.RawMessage(`{"id":1}`), CreatedAt: created}))
+		Expect(messages[1].ID).To(Equal(int64(2)))
+	})
+
+	It("publishes messages outside of transaction", func() {
+		mock.ExpectQuery(qClaim).WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "users", "1", []byte(`{}`), 0, created))
+		mock.ExpectExec(qDelivered).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// sqlmock fails on unexpected begin of transaction
+		_, err := relay.Deliver(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("gives error if messages cannot be claimed", func() {
+		mock.ExpectQuery(qClaim).WillReturnError(errors.New("some error"))
+
+		n, err := relay.Deliver(context.Background())
+		Expect(err).To(MatchError("some error"))
+		Expect(n).To(BeZero())
+		Expect(publisher.Messages()).To(BeEmpty())
+	})
+
+	DescribeTable("retries failed message after backoff doubled on every attempt up to max backoff",
+		func(attempts int, backoff time.Duration) {
+			relay.MaxAttempts = 10
+			publisher.Fail(errors.New("unavailable"))
+
+			mock.ExpectQuery(qClaim).WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "users", "1", []byte(`{}`), attempts, created))
+			mock.ExpectExec(qRetry).WithArgs(attempts+1, "unavailable", backoff.Microseconds(), 1).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			n, err := relay.Deliver(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(Equal(1))
+		},
+		Entry("first attempt", 0, time.Second),
+		Entry("second attempt", 1, 2*time.Second),
+		Entry("third attempt is limited by max backoff", 2, 3*time.Second),
+		Entry("later attempts", 7, 3*time.Second),
+	)
+
+	It("retries messages rejected by full publisher", func() {
+		publisher = mockoutbox.NewPublisher(1)
+		relay.Publisher = publisher
+
+		mock.ExpectQuery(qClaim).WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, "users", "1", []byte(`{}`), 0, created).
+			AddRow(2, "users", "1", []byte(`{}`), 0, created))
+		mock.ExpectExec(qDelivered).WithArgs(outbox.StatusDelivered, 1, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(qRetry).WithArgs(1, mockoutbox.ErrFull.Error(), time.Second.Microseconds(), 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, err := relay.Deliver(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(publisher.Drain()).To(HaveLen(1))
+		Expect(publisher.Messages()).To(BeEmpty())
+	})
+
+	It("marks message as dead after the last attempt", func() {
+		publisher.Fail(errors.New("unavailable"))
+
+		mock.ExpectQuery(qClaim).WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "users", "1", []byte(`{}`), 2, created))
+		mock.ExpectExec(qDead).WithArgs(outbox.StatusDead, 3, "unavailable", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, err := relay.Deliver(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("gives error if result cannot be recorded", func() {
+		mock.ExpectQuery(qClaim).WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, "users", "1", []byte(`{}`), 0, created).
+			AddRow(2, "users", "1", []byte(`{}`), 0, created))
+		mock.ExpectExec(qDelivered).WillReturnError(errors.New("some error"))
+
+		n, err := relay.Deliver(context.Background())
+		Expect(err).To(MatchError("some error"))
+		Expect(n).To(BeZero())
+		Expect(publisher.Messages()).To(HaveLen(1))
+	})
+
+	It("stops publishing when the lease ends", func() {
+		relay.Lease = 50 * time.Millisecond
+		relay.Publisher = publisherFunc(func(ctx context.Context, msg *outbox.Message) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		mock.ExpectQuery(qClaim).WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, "users", "1", []byte(`{}`), 0, created).
+			AddRow(2, "users", "1", []byte(`{}`), 0, created))
+
+		// messages stay claimed and are published again after the lease
+		n, err := relay.Deliver(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(BeZero())
+	})
+
+	It("leaves messages claimed if relay is stopped", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		relay.Publisher = publisherFunc(func(context.Context, *outbox.Message) error {
+			cancel()
+			return nil
+		})
+
+		mock.ExpectQuery(qClaim).WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "users", "1", []byte(`{}`), 0, created))
+
+		_, err := relay.Deliver(ctx)
+		Expect(err).To(Equal(context.Canceled))
+	})
+})
+
+var _ = Describe("Publishers", func() {
+	msg := &outbox.Message{ID: 7, Topic: "users", Key: "1", Payload: json.RawMessage(`{"id":"1"}`), Attempts: 1,
+		CreatedAt: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	It("memory publisher keeps copies of messages up to its capacity until it fails", func() {
+		p := mockoutbox.NewPublisher(2)
+		Expect(p.Publish(context.Background(), msg)).To(Succeed())
+
+		p.Fail(errors.New("unavailable"))
+		Expect(p.Publish(context.Background(), msg)).To(MatchError("unavailable"))
+
+		p.Fail(nil)
+		Expect(p.Publish(context.Background(), msg)).To(Succeed())
+		Expect(p.Publish(context.Background(), msg)).To(Equal(mockoutbox.ErrFull))
+
+		messages := p.Drain()
+		Expect(messages).To(Equal([]*outbox.Message{msg, msg}))
+		Expect(messages[0]).NotTo(BeIdenticalTo(msg))
+		Expect(p.Publish(context.Background(), msg)).To(Succeed())
+	})
+
+	It("file publisher appends messages as newline delimited JSON", func() {
+		dir, err := ioutil.TempDir("", "outbox")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "outbox.ndjson")
+
+		for i := 0; i < 2; i++ {
+			// file is appended by every publisher
+			p, err := outbox.NewFilePublisher(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.Publish(context.Background(), msg)).To(Succeed())
+			Expect(p.Close()).To(Succeed())
+		}
+
+		file, err := os.Open(path)
+		Expect(err).NotTo(HaveOccurred())
+		defer file.Close()
+
+		var lines []string
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[1]).To(MatchJSON(`{"id":7,"topic":"users","key":"1","payload":{"id":"1"},"attempts":1,"created_at":"2020-01-02T03:04:05Z"}`))
+	})
+
+	It("file publisher requires path", func() {
+		_, err := outbox.NewFilePublisher("")
+		Expect(err).To(MatchError("outbox: file path is not set"))
+	})
+
+	It("webhook publisher posts payload with message headers", func() {
+		var req *http.Request
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			req = r
+			body, _ = ioutil.ReadAll(r.Body)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		p := outbox.NewWebhookPublisher(server.URL, time.Second)
+		Expect(p.Publish(context.Background(), msg)).To(Succeed())
+
+		Expect(req.Method).To(Equal(http.MethodPost))
+		Expect(req.Header.Get("Content-Type")).To(Equal("application/json"))
+		Expect(req.Header.Get("X-Outbox-Id")).To(Equal("7"))
+		Expect(req.Header.Get("X-Outbox-Topic")).To(Equal("users"))
+		Expect(req.Header.Get("X-Outbox-Key")).To(Equal("1"))
+		Expect(body).To(MatchJSON(`{"id":"1"}`))
+	})
+
+	It("webhook publisher fails on status other than 2xx", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		p := outbox.NewWebhookPublisher(server.URL, time.Second)
+		Expect(p.Publish(context.Background(), msg)).To(MatchError("webhook responded with 503 Service Unavailable"))
+	})
+})
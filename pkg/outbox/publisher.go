@@ -0,0 +1,39 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/fx"
+
+	"github.com/reviz0r/golang-layout/pkg/config"
+)
+
+// Publisher delivers outbox messages to consumers, it must be safe for concurrent use.
+// The same message may be published more than once, consumers deduplicate it by id.
+type Publisher interface {
+	Publish(ctx context.Context, msg *Message) error
+}
+
+// NewPublisher gives publisher chosen by outbox.publisher: file or webhook
+func NewPublisher(lc fx.Lifecycle, config config.OutboxConfig) (Publisher, error) {
+	switch kind := config.Publisher; kind {
+	case "file":
+		p, err := NewFilePublisher(config.File.Path)
+		if err != nil {
+			return nil, err
+		}
+		lc.Append(fx.Hook{
+			OnStop: func(context.Context) error {
+				return p.Close()
+			},
+		})
+		return p, nil
+
+	case "webhook":
+		return NewWebhookPublisher(config.Webhook.URL, config.Webhook.Timeout), nil
+
+	default:
+		return nil, fmt.Errorf("outbox: unknown publisher %q", kind)
+	}
+}
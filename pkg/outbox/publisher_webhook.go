@@ -0,0 +1,53 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// WebhookPublisher posts payload of every message to the URL.
+// Id, topic and key of the message are sent in X-Outbox-Id, X-Outbox-Topic and X-Outbox-Key headers.
+type WebhookPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookPublisher gives publisher posting to url, zero timeout means no timeout
+func NewWebhookPublisher(url string, timeout time.Duration) *WebhookPublisher {
+	return &WebhookPublisher{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Publish implements Publisher, any response status except 2xx is an error
+func (p *WebhookPublisher) Publish(ctx context.Context, msg *Message) error {
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(msg.Payload))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Outbox-Id", strconv.FormatInt(msg.ID, 10))
+	req.Header.Set("X-Outbox-Topic", msg.Topic)
+	req.Header.Set("X-Outbox-Key", msg.Key)
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	// drain body, so connection can be reused
+	io.Copy(ioutil.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %s", res.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,214 @@
+package outbox
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/volatiletech/sqlboiler/boil"
+	"go.uber.org/fx"
+
+	"github.com/reviz0r/golang-layout/pkg/config"
+	"github.com/reviz0r/golang-layout/pkg/db"
+)
+
+// notifyChannel is postgres channel notified on every insert into outbox
+const notifyChannel = "outbox"
+
+// Relay moves pending outbox messages to publisher.
+// Several relays may run at once, every message is claimed by one of them while it is published.
+type Relay struct {
+	Tx        db.TxManager
+	Publisher Publisher
+	Logger    *logrus.Entry
+
+	// BatchSize limits number of messages claimed at once
+	BatchSize int
+	// Lease is time messages are claimed for
+	Lease time.Duration
+	// MaxAttempts is number of failed attempts after which message becomes dead
+	MaxAttempts int
+	// failed attempts are retried after MinBackoff doubled on every attempt up to MaxBackoff
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// RunRelay delivers outbox messages in background. Relay wakes up on every outbox
+// notification and every outbox.poll_interval in case notifications are lost,
+// it is disabled if outbox.poll_interval is 0.
+func RunRelay(lc fx.Lifecycle, config config.OutboxConfig, tx db.TxManager, notifier db.Notifier, publisher Publisher, logger *logrus.Entry) {
+	logger = logger.WithField("job", "outbox_relay")
+
+	relay := &Relay{
+		Tx:          tx,
+		Publisher:   publisher,
+		Logger:      logger,
+		BatchSize:   config.BatchSize,
+		MaxAttempts: config.MaxAttempts,
+		MinBackoff:  config.MinBackoff,
+		MaxBackoff:  config.MaxBackoff,
+		Lease:       config.Lease,
+	}
+	interval := config.PollInterval
+	if interval <= 0 {
+		logger.Info("outbox relay is disabled")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+
+				signals, unsubscribe, err := notifier.Subscribe(ctx, notifyChannel)
+				if err != nil {
+					logger.WithError(err).Warn("cannot listen outbox notifications, relay falls back to polling")
+				} else {
+					defer unsubscribe()
+				}
+
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+
+				for {
+					n, err := relay.Deliver(ctx)
+					if err != nil && ctx.Err() == nil {
+						logger.WithError(err).Error("cannot deliver outbox messages")
+					}
+					if err == nil && n > 0 && n == relay.BatchSize {
+						// there are more messages
+						continue
+					}
+
+					select {
+					case <-ctx.Done():
+						return
+					case <-signals:
+					case <-ticker.C:
+					}
+				}
+			}()
+			return nil
+		},
+
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-stopCtx.Done():
+				return stopCtx.Err()
+			}
+		},
+	})
+}
+
+// Deliver publishes one batch of pending messages, which are due, and gives their number.
+// Messages are claimed for the lease by a short statement and published outside of transaction,
+// the result of every message is recorded right after it is published. Messages whose results
+// are not recorded until the lease ends, e.g. as relay is stopped, are published again.
+func (r *Relay) Deliver(ctx context.Context) (int, error) {
+	exec := r.Tx.Executor(ctx)
+
+	leaseEnd := time.Now().Add(r.Lease)
+	messages, err := claimPendingMessages(ctx, exec, r.BatchSize, r.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	publishCtx, cancel := context.WithDeadline(ctx, leaseEnd)
+	defer cancel()
+
+	for i, msg := range messages {
+		err := r.Publisher.Publish(publishCtx, msg)
+		if ctx.Err() != nil {
+			// relay is stopped, the rest of messages are published after the lease
+			return i, ctx.Err()
+		}
+		if publishCtx.Err() != nil {
+			// the rest of messages may be claimed by another relay
+			return i, nil
+		}
+
+		if err := r.complete(ctx, exec, msg, err); err != nil {
+			return i, err
+		}
+	}
+
+	return len(messages), nil
+}
+
+// claimPendingMessages moves next attempt of due messages after the lease, so other relays skip them
+func claimPendingMessages(ctx context.Context, exec boil.ContextExecutor, limit int, lease time.Duration) ([]*Message, error) {
+	rows, err := exec.QueryContext(ctx, `UPDATE "outbox" SET "next_attempt_at" = now() + $1 * interval '1 microsecond'
+		WHERE "id" IN (SELECT "id" FROM "outbox" WHERE "status" = $2 AND "next_attempt_at" <= now()
+			ORDER BY "id" LIMIT $3 FOR UPDATE SKIP LOCKED)
+		RETURNING "id", "topic", "key", "payload", "attempts", "created_at"`,
+		lease.Microseconds(), StatusPending, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*Message
+	for rows.Next() {
+		msg := new(Message)
+		var payload []byte
+		if err := rows.Scan(&msg.ID, &msg.Topic, &msg.Key, &payload, &msg.Attempts, &msg.CreatedAt); err != nil {
+			return nil, err
+		}
+		msg.Payload = payload
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not keep order of the subquery
+	sort.Slice(messages, func(i, j int) bool { return messages[i].ID < messages[j].ID })
+	return messages, nil
+}
+
+// complete records result of publishing, failed message is retried later or becomes dead
+func (r *Relay) complete(ctx context.Context, exec boil.ContextExecutor, msg *Message, publishErr error) error {
+	attempts := msg.Attempts + 1
+	logger := r.Logger.WithField("outbox_id", msg.ID).WithField("topic", msg.Topic)
+
+	if publishErr == nil {
+		_, err := exec.ExecContext(ctx,
+			`UPDATE "outbox" SET "status" = $1, "attempts" = $2, "delivered_at" = now() WHERE "id" = $3`,
+			StatusDelivered, attempts, msg.ID)
+		return err
+	}
+
+	if attempts >= r.MaxAttempts {
+		logger.WithError(publishErr).Error("outbox message is dead after last attempt")
+		_, err := exec.ExecContext(ctx,
+			`UPDATE "outbox" SET "status" = $1, "attempts" = $2, "last_error" = $3 WHERE "id" = $4`,
+			StatusDead, attempts, publishErr.Error(), msg.ID)
+		return err
+	}
+
+	backoff := r.backoff(attempts)
+	logger.WithError(publishErr).WithField("retry_in", backoff).Warn("cannot publish outbox message")
+	_, err := exec.ExecContext(ctx,
+		`UPDATE "outbox" SET "attempts" = $1, "last_error" = $2, "next_attempt_at" = now() + $3 * interval '1 microsecond' WHERE "id" = $4`,
+		attempts, publishErr.Error(), backoff.Microseconds(), msg.ID)
+	return err
+}
+
+// backoff gives delay before the next attempt
+func (r *Relay) backoff(attempts int) time.Duration {
+	backoff := r.MinBackoff
+	for i := 1; i < attempts && backoff < r.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > r.MaxBackoff {
+		backoff = r.MaxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,208 @@
+package profile
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/grpclog"
+	"google.golang.org/grpc/status"
+)
+
+// importChunkSize is number of rows sent in one message of ImportUsers stream
+const importChunkSize = 500
+
+// contentTypes of import and export formats
+var contentTypes = map[string]string{
+	FormatCSV:    "text/csv",
+	FormatNDJSON: "application/x-ndjson",
+}
+
+var ImportExportModule = fx.Invoke(RegisterImportExportHandlers)
+
+// RegisterImportExportHandlers serves import and export of users in CSV and NDJSON,
+// they are not generated by grpc-gateway as it can not stream formats other than JSON
+func RegisterImportExportHandlers(lc fx.Lifecycle, p GatewayParams, mux *http.ServeMux, gatewayMux *runtime.ServeMux) error {
+	conn, err := grpc.Dial(p.Config.ProfileServiceEndpoint, p.DialOption)
+	if err != nil {
+		return err
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return conn.Close()
+		},
+	})
+
+	client := NewUserServiceClient(conn)
+	mux.Handle("/v1/users:import", importUsersHandler(client, gatewayMux))
+	mux.Handle("/v1/users:export", exportUsersHandler(client, gatewayMux))
+
+	return nil
+}
+
+// importUsersHandler streams rows of request body to ImportUsers, format is given by Content-Type
+// or "format" query parameter. Query parameter "dry_run" only checks rows.
+func importUsersHandler(client UserServiceClient, gatewayMux *runtime.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, marshaler := runtime.MarshalerForRequest(gatewayMux, r)
+		fail := func(ctx context.Context, err error) {
+			runtime.HTTPError(ctx, gatewayMux, marshaler, w, r, err)
+		}
+
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		ctx, err := runtime.AnnotateContext(ctx, gatewayMux, r)
+		if err != nil {
+			fail(ctx, err)
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = formatOfContentType(r.Header.Get("Content-Type"))
+		}
+
+		dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+
+		rows, err := NewRowReader(r.Body, format)
+		if err != nil {
+			fail(ctx, status.Error(codes.InvalidArgument, err.Error()))
+			return
+		}
+
+		stream, err := client.ImportUsers(ctx)
+		if err != nil {
+			fail(ctx, err)
+			return
+		}
+
+		chunk := &ImportUsersRequest{DryRun: dryRun}
+		for sent := false; ; {
+			row, err := rows.Read()
+			if err != nil && err != io.EOF {
+				// cancel stream, so the rest is not imported
+				cancel()
+				fail(ctx, status.Error(codes.InvalidArgument, err.Error()))
+				return
+			}
+			if row != nil {
+				chunk.Rows = append(chunk.Rows, row)
+			}
+
+			// the first message is sent even if it is empty, it carries dry_run
+			if len(chunk.Rows) == importChunkSize || (err == io.EOF && (len(chunk.Rows) != 0 || !sent)) {
+				if err := stream.Send(chunk); err != nil && err != io.EOF {
+					fail(ctx, err)
+					return
+				}
+				chunk, sent = &ImportUsersRequest{}, true
+			}
+
+			if err == io.EOF {
+				break
+			}
+		}
+
+		res, err := stream.CloseAndRecv()
+		if err != nil {
+			fail(ctx, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", marshaler.ContentType())
+		if err := marshaler.NewEncoder(w).Encode(res); err != nil {
+			grpclog.Infof("Failed to write import response: %v", err)
+		}
+	})
+}
+
+// exportUsersHandler streams users from ExportUsers, format is given by "format" query parameter
+// or Accept header, NDJSON by default. Query parameters "filter" and "show_deleted" select users.
+func exportUsersHandler(client UserServiceClient, gatewayMux *runtime.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, marshaler := runtime.MarshalerForRequest(gatewayMux, r)
+		fail := func(ctx context.Context, err error) {
+			runtime.HTTPError(ctx, gatewayMux, marshaler, w, r, err)
+		}
+
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		ctx, err := runtime.AnnotateContext(r.Context(), gatewayMux, r)
+		if err != nil {
+			fail(ctx, err)
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = formatOfContentType(r.Header.Get("Accept"))
+		}
+
+		users, err := NewUserWriter(w, format)
+		if err != nil {
+			fail(ctx, status.Error(codes.InvalidArgument, err.Error()))
+			return
+		}
+
+		showDeleted, _ := strconv.ParseBool(r.URL.Query().Get("show_deleted"))
+		stream, err := client.ExportUsers(ctx, &ExportUsersRequest{
+			Filter:      r.URL.Query().Get("filter"),
+			ShowDeleted: showDeleted,
+		})
+		if err != nil {
+			fail(ctx, err)
+			return
+		}
+
+		// the first user is received before response is started, so request errors get their status
+		user, err := stream.Recv()
+		if err != nil && err != io.EOF {
+			fail(ctx, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentTypes[format])
+		for ; err == nil; user, err = stream.Recv() {
+			if err := users.Write(user); err != nil {
+				grpclog.Infof("Failed to write exported user: %v", err)
+				return
+			}
+		}
+		if err != io.EOF {
+			// status can not be changed after response is started, so the response is just cut
+			grpclog.Infof("Failed to receive exported user: %v", err)
+			return
+		}
+
+		if err := users.Flush(); err != nil {
+			grpclog.Infof("Failed to write exported users: %v", err)
+		}
+	})
+}
+
+// formatOfContentType gives format of media type, it is NDJSON for unknown types
+func formatOfContentType(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == contentTypes[FormatCSV] {
+		return FormatCSV
+	}
+	return FormatNDJSON
+}
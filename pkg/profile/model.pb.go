@@ -5,9 +5,10 @@ package profile
 
 import (
 	fmt "fmt"
-	math "math"
-
 	proto "github.com/golang/protobuf/proto"
+	_struct "github.com/golang/protobuf/ptypes/struct"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	math "math"
 )
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -21,10 +22,54 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+type UserEvent_Type int32
+
+const (
+	UserEvent_TYPE_UNSPECIFIED UserEvent_Type = 0
+	UserEvent_CREATED          UserEvent_Type = 1
+	UserEvent_UPDATED          UserEvent_Type = 2
+	UserEvent_DELETED          UserEvent_Type = 3
+)
+
+var UserEvent_Type_name = map[int32]string{
+	0: "TYPE_UNSPECIFIED",
+	1: "CREATED",
+	2: "UPDATED",
+	3: "DELETED",
+}
+
+var UserEvent_Type_value = map[string]int32{
+	"TYPE_UNSPECIFIED": 0,
+	"CREATED":          1,
+	"UPDATED":          2,
+	"DELETED":          3,
+}
+
+func (x UserEvent_Type) String() string {
+	return proto.EnumName(UserEvent_Type_name, int32(x))
+}
+
+func (UserEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_4c16552f9fdb66d8, []int{2, 0}
+}
+
 type User struct {
-	Id                   int64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Email                string   `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	Id    int64  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name  string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Email string `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	// Output only. Changes on every update of user.
+	// Pass it to Update or Delete to make sure user was not changed since it was read.
+	Etag string `protobuf:"bytes,4,opt,name=etag,proto3" json:"etag,omitempty"`
+	// Output only. Time when user was deleted, it is set for deleted users only.
+	DeleteTime *timestamp.Timestamp `protobuf:"bytes,5,opt,name=delete_time,json=deleteTime,proto3" json:"delete_time,omitempty"`
+	// Output only. Time when user was created.
+	CreateTime *timestamp.Timestamp `protobuf:"bytes,6,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	// Output only. Time when user was last updated.
+	UpdateTime *timestamp.Timestamp `protobuf:"bytes,7,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`
+	// Output only. Subject of the caller who created user, empty if unknown.
+	CreatedBy string `protobuf:"bytes,8,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	// Output only. Subject of the caller who last updated user, empty if unknown.
+	UpdatedBy            string   `protobuf:"bytes,9,opt,name=updated_by,json=updatedBy,proto3" json:"updated_by,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -76,24 +121,258 @@ func (m *User) GetEmail() string {
 	return ""
 }
 
+func (m *User) GetEtag() string {
+	if m != nil {
+		return m.Etag
+	}
+	return ""
+}
+
+func (m *User) GetDeleteTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.DeleteTime
+	}
+	return nil
+}
+
+func (m *User) GetCreateTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreateTime
+	}
+	return nil
+}
+
+func (m *User) GetUpdateTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.UpdateTime
+	}
+	return nil
+}
+
+func (m *User) GetCreatedBy() string {
+	if m != nil {
+		return m.CreatedBy
+	}
+	return ""
+}
+
+func (m *User) GetUpdatedBy() string {
+	if m != nil {
+		return m.UpdatedBy
+	}
+	return ""
+}
+
+// UserAuditEvent is a change of user made by some method of UserService.
+type UserAuditEvent struct {
+	Id     int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId int64 `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Method which changed user, e.g. `UserService.Update`.
+	Method string `protobuf:"bytes,3,opt,name=method,proto3" json:"method,omitempty"`
+	// Subject of the caller who changed user, empty if unknown.
+	Actor string `protobuf:"bytes,4,opt,name=actor,proto3" json:"actor,omitempty"`
+	// Changed fields of user before and after the change, keyed by column name.
+	Before *_struct.Struct `protobuf:"bytes,5,opt,name=before,proto3" json:"before,omitempty"`
+	After  *_struct.Struct `protobuf:"bytes,6,opt,name=after,proto3" json:"after,omitempty"`
+	// Value of x-request-id header of the request.
+	RequestId            string               `protobuf:"bytes,7,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	TraceId              string               `protobuf:"bytes,8,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+	CreateTime           *timestamp.Timestamp `protobuf:"bytes,9,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *UserAuditEvent) Reset()         { *m = UserAuditEvent{} }
+func (m *UserAuditEvent) String() string { return proto.CompactTextString(m) }
+func (*UserAuditEvent) ProtoMessage()    {}
+func (*UserAuditEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4c16552f9fdb66d8, []int{1}
+}
+
+func (m *UserAuditEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UserAuditEvent.Unmarshal(m, b)
+}
+func (m *UserAuditEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UserAuditEvent.Marshal(b, m, deterministic)
+}
+func (m *UserAuditEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UserAuditEvent.Merge(m, src)
+}
+func (m *UserAuditEvent) XXX_Size() int {
+	return xxx_messageInfo_UserAuditEvent.Size(m)
+}
+func (m *UserAuditEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_UserAuditEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UserAuditEvent proto.InternalMessageInfo
+
+func (m *UserAuditEvent) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *UserAuditEvent) GetUserId() int64 {
+	if m != nil {
+		return m.UserId
+	}
+	return 0
+}
+
+func (m *UserAuditEvent) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *UserAuditEvent) GetActor() string {
+	if m != nil {
+		return m.Actor
+	}
+	return ""
+}
+
+func (m *UserAuditEvent) GetBefore() *_struct.Struct {
+	if m != nil {
+		return m.Before
+	}
+	return nil
+}
+
+func (m *UserAuditEvent) GetAfter() *_struct.Struct {
+	if m != nil {
+		return m.After
+	}
+	return nil
+}
+
+func (m *UserAuditEvent) GetRequestId() string {
+	if m != nil {
+		return m.RequestId
+	}
+	return ""
+}
+
+func (m *UserAuditEvent) GetTraceId() string {
+	if m != nil {
+		return m.TraceId
+	}
+	return ""
+}
+
+func (m *UserAuditEvent) GetCreateTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreateTime
+	}
+	return nil
+}
+
+// UserEvent is a change of user sent by WatchUsers.
+type UserEvent struct {
+	Type UserEvent_Type `protobuf:"varint,1,opt,name=type,proto3,enum=github.reviz0r.layout.profile.UserEvent_Type" json:"type,omitempty"`
+	// User as it is after the change: id, fields set by the change, time and actor of the change.
+	User *User `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	// Revision of the change, pass it to WatchUsers to resume watching after this event.
+	Revision             int64    `protobuf:"varint,3,opt,name=revision,proto3" json:"revision,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UserEvent) Reset()         { *m = UserEvent{} }
+func (m *UserEvent) String() string { return proto.CompactTextString(m) }
+func (*UserEvent) ProtoMessage()    {}
+func (*UserEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4c16552f9fdb66d8, []int{2}
+}
+
+func (m *UserEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UserEvent.Unmarshal(m, b)
+}
+func (m *UserEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UserEvent.Marshal(b, m, deterministic)
+}
+func (m *UserEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UserEvent.Merge(m, src)
+}
+func (m *UserEvent) XXX_Size() int {
+	return xxx_messageInfo_UserEvent.Size(m)
+}
+func (m *UserEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_UserEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UserEvent proto.InternalMessageInfo
+
+func (m *UserEvent) GetType() UserEvent_Type {
+	if m != nil {
+		return m.Type
+	}
+	return UserEvent_TYPE_UNSPECIFIED
+}
+
+func (m *UserEvent) GetUser() *User {
+	if m != nil {
+		return m.User
+	}
+	return nil
+}
+
+func (m *UserEvent) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
 func init() {
+	proto.RegisterEnum("github.reviz0r.layout.profile.UserEvent_Type", UserEvent_Type_name, UserEvent_Type_value)
 	proto.RegisterType((*User)(nil), "github.reviz0r.layout.profile.User")
+	proto.RegisterType((*UserAuditEvent)(nil), "github.reviz0r.layout.profile.UserAuditEvent")
+	proto.RegisterType((*UserEvent)(nil), "github.reviz0r.layout.profile.UserEvent")
 }
 
 func init() { proto.RegisterFile("model.proto", fileDescriptor_4c16552f9fdb66d8) }
 
 var fileDescriptor_4c16552f9fdb66d8 = []byte{
-	// 178 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0xce, 0xcd, 0x4f, 0x49,
-	0xcd, 0xd1, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x92, 0x4d, 0xcf, 0x2c, 0xc9, 0x28, 0x4d, 0xd2,
-	0x2b, 0x4a, 0x2d, 0xcb, 0xac, 0x32, 0x28, 0xd2, 0xcb, 0x49, 0xac, 0xcc, 0x2f, 0x2d, 0x01, 0x49,
-	0xa6, 0x65, 0xe6, 0xa4, 0x4a, 0xf1, 0x97, 0x25, 0xe6, 0x64, 0xa6, 0x24, 0x96, 0xe4, 0x17, 0x41,
-	0xd4, 0x2b, 0x05, 0x70, 0xb1, 0x84, 0x16, 0xa7, 0x16, 0x09, 0xf1, 0x71, 0x31, 0x65, 0xa6, 0x48,
-	0x30, 0x2a, 0x30, 0x6a, 0x30, 0x07, 0x31, 0x65, 0xa6, 0x08, 0x49, 0x71, 0xb1, 0xe4, 0x25, 0xe6,
-	0xa6, 0x4a, 0x30, 0x29, 0x30, 0x6a, 0x70, 0x3a, 0xb1, 0x3d, 0xba, 0x2f, 0xcf, 0x14, 0xc1, 0x18,
-	0x04, 0x16, 0x13, 0x92, 0xe1, 0x62, 0x4d, 0xcd, 0x4d, 0xcc, 0xcc, 0x91, 0x60, 0x46, 0x91, 0x84,
-	0x08, 0x3a, 0xe9, 0x45, 0xe9, 0x40, 0xdd, 0x90, 0x9c, 0x9f, 0xab, 0x0f, 0x75, 0x87, 0x7e, 0x7a,
-	0x7e, 0x4e, 0x62, 0x5e, 0xba, 0x2e, 0xc4, 0x39, 0xfa, 0x05, 0xd9, 0xe9, 0xfa, 0x50, 0x27, 0x25,
-	0xb1, 0x81, 0x1d, 0x62, 0x0c, 0x08, 0x00, 0x00, 0xff, 0xff, 0xb8, 0xfb, 0x4c, 0xca, 0xc7, 0x00,
-	0x00, 0x00,
+	// 545 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x53, 0x4d, 0x6b, 0xdb, 0x40,
+	0x14, 0xac, 0x64, 0x59, 0x8e, 0x9f, 0x21, 0x0d, 0x4b, 0x68, 0x54, 0x93, 0x90, 0xe0, 0x5e, 0x72,
+	0xa8, 0xa5, 0xe2, 0x1e, 0x7a, 0xe8, 0xc9, 0x1f, 0x2a, 0x18, 0x4a, 0x31, 0x8a, 0x0d, 0x6d, 0x2f,
+	0x66, 0xed, 0x7d, 0x56, 0x44, 0x25, 0xaf, 0xba, 0x5a, 0x19, 0xd4, 0x1f, 0xd7, 0xff, 0xd1, 0x53,
+	0xa1, 0xa7, 0xfe, 0x8c, 0xb2, 0xbb, 0xb2, 0xa1, 0x09, 0x24, 0xed, 0x4d, 0xf3, 0x66, 0x66, 0x35,
+	0x6f, 0x56, 0x82, 0x4e, 0xc6, 0x19, 0xa6, 0x7e, 0x2e, 0xb8, 0xe4, 0xe4, 0x22, 0x4e, 0xe4, 0x6d,
+	0xb9, 0xf2, 0x05, 0xee, 0x92, 0x6f, 0xaf, 0x84, 0x9f, 0xd2, 0x8a, 0x97, 0x52, 0x91, 0x9b, 0x24,
+	0xc5, 0xee, 0x79, 0xcc, 0x79, 0x9c, 0x62, 0xa0, 0xc5, 0xab, 0x72, 0x13, 0x14, 0x52, 0x94, 0x6b,
+	0x69, 0xcc, 0xdd, 0xcb, 0xbb, 0xac, 0x4c, 0x32, 0x2c, 0x24, 0xcd, 0xf2, 0x5a, 0xf0, 0x74, 0x47,
+	0xd3, 0x84, 0x51, 0xc9, 0x85, 0x19, 0xf4, 0x7e, 0xd8, 0xe0, 0x2c, 0x0a, 0x14, 0xe4, 0x18, 0xec,
+	0x84, 0x79, 0xd6, 0x95, 0x75, 0xdd, 0x88, 0xec, 0x84, 0x91, 0x2e, 0x38, 0x5b, 0x9a, 0xa1, 0x67,
+	0x5f, 0x59, 0xd7, 0xed, 0x91, 0xfb, 0xeb, 0xe7, 0xa5, 0xfd, 0xd1, 0x8a, 0xf4, 0x8c, 0x9c, 0x43,
+	0x13, 0x33, 0x9a, 0xa4, 0x5e, 0xe3, 0x2f, 0xd2, 0x0c, 0x09, 0x01, 0x07, 0x25, 0x8d, 0x3d, 0x47,
+	0x91, 0x91, 0x7e, 0x26, 0x6f, 0xa1, 0xc3, 0x30, 0x45, 0x89, 0x4b, 0x95, 0xc8, 0x6b, 0x5e, 0x59,
+	0xd7, 0x9d, 0x41, 0xd7, 0x37, 0x71, 0xfd, 0x7d, 0x5c, 0x7f, 0xbe, 0x8f, 0x1b, 0x81, 0x91, 0xab,
+	0x81, 0x32, 0xaf, 0x05, 0xd2, 0xbd, 0xd9, 0x7d, 0xdc, 0x6c, 0xe4, 0x7b, 0x73, 0x99, 0xb3, 0x83,
+	0xb9, 0xf5, 0xb8, 0xd9, 0xc8, 0xb5, 0xf9, 0x02, 0xea, 0xa3, 0xd8, 0x72, 0x55, 0x79, 0x47, 0x7a,
+	0xa1, 0x76, 0x3d, 0x19, 0x55, 0x8a, 0x36, 0x62, 0x4d, 0xb7, 0x0d, 0x5d, 0x4f, 0x46, 0x55, 0xef,
+	0xbb, 0x0d, 0xc7, 0xaa, 0xdb, 0x61, 0xc9, 0x12, 0x19, 0xee, 0x70, 0x2b, 0xef, 0xb5, 0x7c, 0x06,
+	0xad, 0xb2, 0x40, 0xb1, 0x4c, 0x98, 0x2e, 0xba, 0x11, 0xb9, 0x0a, 0x4e, 0x19, 0x79, 0x06, 0x6e,
+	0x86, 0xf2, 0x96, 0x33, 0xd3, 0x71, 0x54, 0x23, 0x72, 0x0a, 0x4d, 0xba, 0x96, 0x5c, 0xd4, 0xed,
+	0x1a, 0x40, 0x02, 0x70, 0x57, 0xb8, 0xe1, 0x62, 0xdf, 0xec, 0xd9, 0xbd, 0xfd, 0x6e, 0xf4, 0x67,
+	0x12, 0xd5, 0x32, 0xd2, 0x87, 0x26, 0xdd, 0x48, 0x14, 0x9e, 0xfb, 0xb0, 0xde, 0xa8, 0xd4, 0xa2,
+	0x02, 0xbf, 0x96, 0x58, 0x48, 0x95, 0xb4, 0x65, 0x16, 0xad, 0x27, 0x53, 0x46, 0x9e, 0xc3, 0x91,
+	0x14, 0x74, 0x8d, 0x8a, 0x34, 0x25, 0xb5, 0x34, 0x9e, 0xb2, 0xbb, 0x77, 0xd7, 0xfe, 0x9f, 0xbb,
+	0xeb, 0xfd, 0xb6, 0xa0, 0xad, 0x0a, 0x34, 0xdd, 0x0d, 0xc1, 0x91, 0x55, 0x8e, 0xba, 0xbd, 0xe3,
+	0x41, 0xdf, 0x7f, 0xf0, 0x47, 0xf1, 0x0f, 0x3e, 0x7f, 0x5e, 0xe5, 0x18, 0x69, 0x2b, 0x79, 0x03,
+	0x8e, 0xea, 0x57, 0x77, 0xdd, 0x19, 0xbc, 0xf8, 0x87, 0x23, 0x22, 0x6d, 0x20, 0x5d, 0x38, 0x52,
+	0xa2, 0x22, 0xe1, 0x5b, 0x7d, 0x21, 0x8d, 0xe8, 0x80, 0x7b, 0x63, 0x70, 0xd4, 0x2b, 0xc8, 0x29,
+	0x9c, 0xcc, 0x3f, 0xcd, 0xc2, 0xe5, 0xe2, 0xc3, 0xcd, 0x2c, 0x1c, 0x4f, 0xdf, 0x4d, 0xc3, 0xc9,
+	0xc9, 0x13, 0xd2, 0x81, 0xd6, 0x38, 0x0a, 0x87, 0xf3, 0x70, 0x72, 0x62, 0x29, 0xb0, 0x98, 0x4d,
+	0x34, 0xb0, 0x15, 0x98, 0x84, 0xef, 0x43, 0x05, 0x1a, 0x23, 0xff, 0xf3, 0xcb, 0x3a, 0xcc, 0x9a,
+	0x67, 0x41, 0x1d, 0x28, 0x88, 0x79, 0x4a, 0xb7, 0x71, 0xdf, 0xe4, 0x0a, 0xf2, 0x2f, 0x71, 0x50,
+	0x67, 0x5b, 0xb9, 0xba, 0xba, 0xd7, 0x7f, 0x06, 0x00, 0x1e, 0xf9, 0x40, 0xe6, 0x3c, 0x04, 0x00,
+	0x00,
 }
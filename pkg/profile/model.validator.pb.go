@@ -6,6 +6,8 @@ package profile
 import (
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
+	_ "github.com/golang/protobuf/ptypes/struct"
+	_ "github.com/golang/protobuf/ptypes/timestamp"
 	github_com_mwitkow_go_proto_validators "github.com/mwitkow/go-proto-validators"
 	math "math"
 )
@@ -22,5 +24,46 @@ func (this *User) Validate() error {
 	if this.Email == "" {
 		return github_com_mwitkow_go_proto_validators.FieldError("Email", fmt.Errorf(`value '%v' must not be an empty string`, this.Email))
 	}
+	if this.DeleteTime != nil {
+		if err := github_com_mwitkow_go_proto_validators.CallValidatorIfExists(this.DeleteTime); err != nil {
+			return github_com_mwitkow_go_proto_validators.FieldError("DeleteTime", err)
+		}
+	}
+	if this.CreateTime != nil {
+		if err := github_com_mwitkow_go_proto_validators.CallValidatorIfExists(this.CreateTime); err != nil {
+			return github_com_mwitkow_go_proto_validators.FieldError("CreateTime", err)
+		}
+	}
+	if this.UpdateTime != nil {
+		if err := github_com_mwitkow_go_proto_validators.CallValidatorIfExists(this.UpdateTime); err != nil {
+			return github_com_mwitkow_go_proto_validators.FieldError("UpdateTime", err)
+		}
+	}
+	return nil
+}
+func (this *UserAuditEvent) Validate() error {
+	if this.Before != nil {
+		if err := github_com_mwitkow_go_proto_validators.CallValidatorIfExists(this.Before); err != nil {
+			return github_com_mwitkow_go_proto_validators.FieldError("Before", err)
+		}
+	}
+	if this.After != nil {
+		if err := github_com_mwitkow_go_proto_validators.CallValidatorIfExists(this.After); err != nil {
+			return github_com_mwitkow_go_proto_validators.FieldError("After", err)
+		}
+	}
+	if this.CreateTime != nil {
+		if err := github_com_mwitkow_go_proto_validators.CallValidatorIfExists(this.CreateTime); err != nil {
+			return github_com_mwitkow_go_proto_validators.FieldError("CreateTime", err)
+		}
+	}
+	return nil
+}
+func (this *UserEvent) Validate() error {
+	if this.User != nil {
+		if err := github_com_mwitkow_go_proto_validators.CallValidatorIfExists(this.User); err != nil {
+			return github_com_mwitkow_go_proto_validators.FieldError("User", err)
+		}
+	}
 	return nil
 }
@@ -2,19 +2,61 @@ package profile
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
-	"github.com/spf13/viper"
 	"go.uber.org/fx"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+
+	"github.com/reviz0r/golang-layout/pkg/config"
+	"github.com/reviz0r/golang-layout/pkg/health"
+)
+
+var GatewayModule = fx.Options(
+	fx.Provide(NewGatewayHealthCheck),
+	fx.Invoke(UserServiceGateway),
 )
 
-var GatewayModule = fx.Invoke(UserServiceGateway)
+// GatewayParams are endpoint of user service and credentials of gateway connections to it
+type GatewayParams struct {
+	fx.In
+
+	Config     config.GatewayConfig
+	DialOption grpc.DialOption `name:"gateway_dial_option"`
+}
 
-func UserServiceGateway(config *viper.Viper, mux *runtime.ServeMux) error {
+func UserServiceGateway(p GatewayParams, mux *runtime.ServeMux) error {
 	return RegisterUserServiceHandlerFromEndpoint(
-		context.TODO(), mux, config.GetString("gateway.profile_service_endpoint"), []grpc.DialOption{grpc.WithInsecure()})
+		context.TODO(), mux, p.Config.ProfileServiceEndpoint, []grpc.DialOption{p.DialOption})
+}
+
+// NewGatewayHealthCheck gives readiness check which fails if gateway can not connect to user service
+func NewGatewayHealthCheck(lc fx.Lifecycle, p GatewayParams) (health.CheckResult, error) {
+	endpoint := p.Config.ProfileServiceEndpoint
+	conn, err := grpc.Dial(endpoint, p.DialOption)
+	if err != nil {
+		return health.CheckResult{}, err
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return conn.Close()
+		},
+	})
+
+	return health.CheckResult{Check: health.Check{
+		Name: "gateway",
+		Check: func(ctx context.Context) error {
+			for state := conn.GetState(); state != connectivity.Ready; state = conn.GetState() {
+				if !conn.WaitForStateChange(ctx, state) {
+					return fmt.Errorf("user service %s is %s", endpoint, state)
+				}
+			}
+			return nil
+		},
+	}}, nil
 }
 
 var SwaggerModule = fx.Invoke(RegisterProfileSwagger)
@@ -28,6 +28,34 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+type ImportUsersResponse_Problem_Kind int32
+
+const (
+	ImportUsersResponse_Problem_KIND_UNSPECIFIED ImportUsersResponse_Problem_Kind = 0
+	ImportUsersResponse_Problem_SKIPPED          ImportUsersResponse_Problem_Kind = 1
+	ImportUsersResponse_Problem_FAILED           ImportUsersResponse_Problem_Kind = 2
+)
+
+var ImportUsersResponse_Problem_Kind_name = map[int32]string{
+	0: "KIND_UNSPECIFIED",
+	1: "SKIPPED",
+	2: "FAILED",
+}
+
+var ImportUsersResponse_Problem_Kind_value = map[string]int32{
+	"KIND_UNSPECIFIED": 0,
+	"SKIPPED":          1,
+	"FAILED":           2,
+}
+
+func (x ImportUsersResponse_Problem_Kind) String() string {
+	return proto.EnumName(ImportUsersResponse_Problem_Kind_name, int32(x))
+}
+
+func (ImportUsersResponse_Problem_Kind) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_d59e6a97f11722e0, []int{18, 0, 0}
+}
+
 type CreateRequest struct {
 	User                 *User    `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -107,12 +135,23 @@ func (m *CreateResponse) GetId() int64 {
 }
 
 type ReadAllRequest struct {
-	Limit                int32                 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
-	Offset               int32                 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
-	Fields               *field_mask.FieldMask `protobuf:"bytes,3,opt,name=fields,proto3" json:"fields,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
-	XXX_unrecognized     []byte                `json:"-"`
-	XXX_sizecache        int32                 `json:"-"`
+	Limit  int32                 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset int32                 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	Fields *field_mask.FieldMask `protobuf:"bytes,3,opt,name=fields,proto3" json:"fields,omitempty"`
+	// Opaque cursor from a previous ReadAllResponse.next_page_token.
+	// Can not be combined with offset.
+	PageToken string `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// Compute total count of users (costs an extra query).
+	IncludeTotal bool `protobuf:"varint,5,opt,name=include_total,json=includeTotal,proto3" json:"include_total,omitempty"`
+	// Filter expression (AIP-160), e.g. `email:"*@corp.com" AND name!="bot"`.
+	Filter string `protobuf:"bytes,6,opt,name=filter,proto3" json:"filter,omitempty"`
+	// Comma separated list of fields with optional direction, e.g. `name desc, id`.
+	OrderBy string `protobuf:"bytes,7,opt,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`
+	// Include deleted users.
+	ShowDeleted          bool     `protobuf:"varint,8,opt,name=show_deleted,json=showDeleted,proto3" json:"show_deleted,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *ReadAllRequest) Reset()         { *m = ReadAllRequest{} }
@@ -161,11 +200,48 @@ func (m *ReadAllRequest) GetFields() *field_mask.FieldMask {
 	return nil
 }
 
+func (m *ReadAllRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+func (m *ReadAllRequest) GetIncludeTotal() bool {
+	if m != nil {
+		return m.IncludeTotal
+	}
+	return false
+}
+
+func (m *ReadAllRequest) GetFilter() string {
+	if m != nil {
+		return m.Filter
+	}
+	return ""
+}
+
+func (m *ReadAllRequest) GetOrderBy() string {
+	if m != nil {
+		return m.OrderBy
+	}
+	return ""
+}
+
+func (m *ReadAllRequest) GetShowDeleted() bool {
+	if m != nil {
+		return m.ShowDeleted
+	}
+	return false
+}
+
 type ReadAllResponse struct {
-	Users                []*User  `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
-	Limit                int32    `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
-	Offset               int32    `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
-	Total                int32    `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+	Users  []*User `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	Limit  int32   `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset int32   `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	Total  int32   `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+	// Cursor of the next page, empty if there are no more users.
+	NextPageToken        string   `protobuf:"bytes,5,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -224,12 +300,21 @@ func (m *ReadAllResponse) GetTotal() int32 {
 	return 0
 }
 
+func (m *ReadAllResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 type ReadRequest struct {
-	Id                   int64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Fields               *field_mask.FieldMask `protobuf:"bytes,2,opt,name=fields,proto3" json:"fields,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
-	XXX_unrecognized     []byte                `json:"-"`
-	XXX_sizecache        int32                 `json:"-"`
+	Id     int64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Fields *field_mask.FieldMask `protobuf:"bytes,2,opt,name=fields,proto3" json:"fields,omitempty"`
+	// Give user even if it is deleted.
+	ShowDeleted          bool     `protobuf:"varint,3,opt,name=show_deleted,json=showDeleted,proto3" json:"show_deleted,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *ReadRequest) Reset()         { *m = ReadRequest{} }
@@ -271,6 +356,13 @@ func (m *ReadRequest) GetFields() *field_mask.FieldMask {
 	return nil
 }
 
+func (m *ReadRequest) GetShowDeleted() bool {
+	if m != nil {
+		return m.ShowDeleted
+	}
+	return false
+}
+
 type ReadResponse struct {
 	User                 *User    `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -311,12 +403,15 @@ func (m *ReadResponse) GetUser() *User {
 }
 
 type UpdateRequest struct {
-	Id                   int64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	User                 *User                 `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
-	Fields               *field_mask.FieldMask `protobuf:"bytes,3,opt,name=fields,proto3" json:"fields,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
-	XXX_unrecognized     []byte                `json:"-"`
-	XXX_sizecache        int32                 `json:"-"`
+	Id     int64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	User   *User                 `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	Fields *field_mask.FieldMask `protobuf:"bytes,3,opt,name=fields,proto3" json:"fields,omitempty"`
+	// Etag of user from previous read, update fails with ABORTED if user was changed since then.
+	// If-Match header is used if it is empty.
+	Etag                 string   `protobuf:"bytes,4,opt,name=etag,proto3" json:"etag,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *UpdateRequest) Reset()         { *m = UpdateRequest{} }
@@ -365,8 +460,18 @@ func (m *UpdateRequest) GetFields() *field_mask.FieldMask {
 	return nil
 }
 
+func (m *UpdateRequest) GetEtag() string {
+	if m != nil {
+		return m.Etag
+	}
+	return ""
+}
+
 type DeleteRequest struct {
-	Id                   int64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Id int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Etag of user from previous read, delete fails with ABORTED if user was changed since then.
+	// If-Match header is used if it is empty.
+	Etag                 string   `protobuf:"bytes,2,opt,name=etag,proto3" json:"etag,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -404,232 +509,1189 @@ func (m *DeleteRequest) GetId() int64 {
 	return 0
 }
 
-func init() {
-	proto.RegisterType((*CreateRequest)(nil), "github.reviz0r.layout.profile.CreateRequest")
-	proto.RegisterType((*CreateResponse)(nil), "github.reviz0r.layout.profile.CreateResponse")
-	proto.RegisterType((*ReadAllRequest)(nil), "github.reviz0r.layout.profile.ReadAllRequest")
-	proto.RegisterType((*ReadAllResponse)(nil), "github.reviz0r.layout.profile.ReadAllResponse")
-	proto.RegisterType((*ReadRequest)(nil), "github.reviz0r.layout.profile.ReadRequest")
-	proto.RegisterType((*ReadResponse)(nil), "github.reviz0r.layout.profile.ReadResponse")
-	proto.RegisterType((*UpdateRequest)(nil), "github.reviz0r.layout.profile.UpdateRequest")
-	proto.RegisterType((*DeleteRequest)(nil), "github.reviz0r.layout.profile.DeleteRequest")
+func (m *DeleteRequest) GetEtag() string {
+	if m != nil {
+		return m.Etag
+	}
+	return ""
 }
 
-func init() { proto.RegisterFile("profile_api.proto", fileDescriptor_d59e6a97f11722e0) }
+type UndeleteUserRequest struct {
+	Id                   int64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
 
-var fileDescriptor_d59e6a97f11722e0 = []byte{
-	// 670 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x94, 0x4f, 0x6f, 0xd3, 0x30,
-	0x18, 0xc6, 0x49, 0xba, 0x06, 0xe1, 0xb2, 0x6e, 0x35, 0x53, 0x55, 0x65, 0x4c, 0x58, 0xe1, 0xc0,
-	0xd8, 0xda, 0x84, 0x75, 0x07, 0x34, 0x2e, 0xa8, 0xe3, 0xcf, 0x4e, 0x48, 0x28, 0x68, 0x07, 0xb8,
-	0x4c, 0xee, 0xe2, 0x66, 0xd6, 0xdc, 0x38, 0xc4, 0x6e, 0xa7, 0xf1, 0xe7, 0xc2, 0x8d, 0x1b, 0x2a,
-	0xdc, 0xe0, 0x5b, 0xf1, 0x01, 0x10, 0x88, 0x0f, 0x82, 0x62, 0xbb, 0xdb, 0x5a, 0x36, 0x1a, 0x76,
-	0xaa, 0x6c, 0xbf, 0xf6, 0xf3, 0x7b, 0x9f, 0xf7, 0x49, 0x41, 0x2d, 0xcd, 0x78, 0x8f, 0x32, 0xb2,
-	0x87, 0x53, 0xea, 0xa7, 0x19, 0x97, 0x1c, 0xae, 0xc4, 0x54, 0x1e, 0x0c, 0xba, 0x7e, 0x46, 0x86,
-	0xf4, 0xcd, 0xbd, 0xcc, 0x67, 0xf8, 0x98, 0x0f, 0xa4, 0x6f, 0x0a, 0xdd, 0x9b, 0x31, 0xe7, 0x31,
-	0x23, 0x01, 0x4e, 0x69, 0x80, 0x93, 0x84, 0x4b, 0x2c, 0x29, 0x4f, 0x84, 0xbe, 0xec, 0x2e, 0x9b,
-	0x53, 0xb5, 0xea, 0x0e, 0x7a, 0x01, 0xe9, 0xa7, 0xf2, 0xd8, 0x1c, 0xa2, 0xe9, 0xc3, 0x1e, 0x25,
-	0x2c, 0xda, 0xeb, 0x63, 0x71, 0x68, 0x2a, 0x2a, 0x7d, 0x1e, 0x11, 0x66, 0x16, 0x4d, 0xf5, 0xb3,
-	0xdf, 0x8a, 0x49, 0xd2, 0x12, 0x47, 0x38, 0x8e, 0x49, 0x16, 0xf0, 0x54, 0xa9, 0x9d, 0xa3, 0xbc,
-	0x30, 0xc4, 0x8c, 0x46, 0x58, 0xf2, 0x4c, 0x6f, 0x78, 0xcf, 0xc1, 0xfc, 0xa3, 0x8c, 0x60, 0x49,
-	0x42, 0xf2, 0x7a, 0x40, 0x84, 0x84, 0x0f, 0xc1, 0xdc, 0x40, 0x90, 0xac, 0x61, 0x21, 0x6b, 0xb5,
-	0xd2, 0xbe, 0xed, 0xff, 0xb3, 0x4f, 0x7f, 0x57, 0x90, 0x6c, 0xdb, 0xf9, 0xf5, 0xe3, 0x96, 0x8d,
-	0xac, 0x50, 0x5d, 0xf4, 0x10, 0xa8, 0x8e, 0x5f, 0x14, 0x29, 0x4f, 0x04, 0x81, 0x55, 0x60, 0xd3,
-	0x48, 0x3d, 0x58, 0x0a, 0x6d, 0x1a, 0x79, 0x19, 0xa8, 0x86, 0x04, 0x47, 0x1d, 0xc6, 0xc6, 0xa2,
-	0x4b, 0xa0, 0xcc, 0x68, 0x9f, 0x4a, 0x55, 0x54, 0x0e, 0xf5, 0x02, 0xd6, 0x81, 0xc3, 0x7b, 0x3d,
-	0x41, 0x64, 0xc3, 0x56, 0xdb, 0x66, 0x05, 0xdb, 0xc0, 0x51, 0x9e, 0x88, 0x46, 0x49, 0x41, 0xba,
-	0xbe, 0xb6, 0xcc, 0x1f, 0x5b, 0xe6, 0x3f, 0xcd, 0x8f, 0x9f, 0x61, 0x71, 0x18, 0x9a, 0x4a, 0xef,
-	0x93, 0x05, 0x16, 0x4e, 0x44, 0x0d, 0xd7, 0x16, 0x28, 0xe7, 0xc4, 0xa2, 0x61, 0xa1, 0x52, 0xc1,
-	0x5e, 0x43, 0x7d, 0xe3, 0x14, 0xd8, 0x3e, 0x1f, 0xb8, 0x34, 0x01, 0xbc, 0x04, 0xca, 0x92, 0x4b,
-	0xcc, 0x1a, 0x73, 0xba, 0x5a, 0x2d, 0xbc, 0x97, 0xa0, 0x92, 0x13, 0x8d, 0x3d, 0xa8, 0x9f, 0xba,
-	0xa4, 0x1d, 0x5d, 0xbc, 0x92, 0xbb, 0x75, 0xa6, 0x5b, 0xbb, 0x70, 0xb7, 0x3b, 0xe0, 0xba, 0x7e,
-	0xda, 0x74, 0x7a, 0xff, 0xbf, 0x87, 0x6a, 0x86, 0xf9, 0xcd, 0x02, 0xf3, 0xbb, 0x69, 0x74, 0x26,
-	0x1f, 0x17, 0x61, 0x8e, 0x73, 0x63, 0x5f, 0x32, 0x37, 0x97, 0x9a, 0xea, 0x1d, 0x30, 0xff, 0x98,
-	0x30, 0x32, 0x93, 0xae, 0xfd, 0xb3, 0x0c, 0x2a, 0xb9, 0xe6, 0x0b, 0x92, 0x0d, 0xe9, 0x3e, 0x81,
-	0x23, 0x0b, 0x38, 0x3a, 0xa5, 0xb0, 0x39, 0x03, 0x75, 0xe2, 0xf3, 0x70, 0x5b, 0x05, 0xab, 0xb5,
-	0xf1, 0xde, 0xfa, 0xa8, 0x53, 0x83, 0x0b, 0x7a, 0x13, 0x25, 0xe4, 0x08, 0xe5, 0xad, 0x7e, 0xf8,
-	0xfe, 0xfb, 0xb3, 0x5d, 0xf3, 0xae, 0x05, 0xc3, 0x8d, 0x40, 0xc5, 0xe9, 0x81, 0x76, 0xe0, 0xab,
-	0x05, 0xae, 0x9a, 0x8c, 0xc2, 0x59, 0x3a, 0x93, 0x1f, 0x90, 0xeb, 0x17, 0x2d, 0x37, 0x5c, 0x1b,
-	0xa3, 0xce, 0x0a, 0x5c, 0xde, 0x21, 0x12, 0x61, 0xc6, 0x14, 0x94, 0x40, 0xab, 0x47, 0x54, 0x1e,
-	0xa0, 0x14, 0xc7, 0x34, 0x89, 0xef, 0x2a, 0xc6, 0x0a, 0x3c, 0x65, 0x84, 0x5f, 0x2c, 0x30, 0x97,
-	0x3f, 0x03, 0xd7, 0x0a, 0x68, 0x8d, 0xb9, 0xd6, 0x0b, 0xd5, 0x1a, 0xa8, 0xcd, 0x51, 0x67, 0x09,
-	0xc2, 0x1c, 0x8a, 0x27, 0x44, 0x41, 0xa1, 0xee, 0x31, 0xa2, 0x91, 0x62, 0xa9, 0xc3, 0xea, 0x09,
-	0x4b, 0xf0, 0x96, 0x46, 0xef, 0xbb, 0xda, 0xb4, 0x8f, 0x16, 0x70, 0x74, 0x42, 0x67, 0x4e, 0x72,
-	0x22, 0xc8, 0x6e, 0xfd, 0xaf, 0x7c, 0x3d, 0xc9, 0xff, 0x85, 0xbd, 0xad, 0x51, 0xc7, 0x85, 0x0d,
-	0x5d, 0xab, 0x21, 0x74, 0xd8, 0xce, 0xb2, 0xb4, 0xa7, 0x58, 0xcc, 0x00, 0xdf, 0x01, 0x47, 0xc7,
-	0x71, 0x26, 0xca, 0x44, 0x6a, 0x2f, 0x44, 0x69, 0x8e, 0x3a, 0x37, 0x60, 0x4d, 0xd7, 0x4e, 0xfb,
-	0xb1, 0xb8, 0x36, 0xc5, 0xb0, 0xed, 0xbf, 0x6a, 0x1a, 0xd1, 0x7d, 0xde, 0x0f, 0x8c, 0x70, 0x10,
-	0x73, 0x86, 0x93, 0xb8, 0xa5, 0xf5, 0x83, 0xf4, 0x30, 0x0e, 0x0c, 0x43, 0xd7, 0x51, 0x6a, 0x9b,
-	0x7f, 0x02, 0x00, 0x00, 0xff, 0xff, 0xf6, 0x60, 0xe2, 0x81, 0xde, 0x06, 0x00, 0x00,
+func (m *UndeleteUserRequest) Reset()         { *m = UndeleteUserRequest{} }
+func (m *UndeleteUserRequest) String() string { return proto.CompactTextString(m) }
+func (*UndeleteUserRequest) ProtoMessage()    {}
+func (*UndeleteUserRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d59e6a97f11722e0, []int{8}
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *UndeleteUserRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UndeleteUserRequest.Unmarshal(m, b)
+}
+func (m *UndeleteUserRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UndeleteUserRequest.Marshal(b, m, deterministic)
+}
+func (m *UndeleteUserRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UndeleteUserRequest.Merge(m, src)
+}
+func (m *UndeleteUserRequest) XXX_Size() int {
+	return xxx_messageInfo_UndeleteUserRequest.Size(m)
+}
+func (m *UndeleteUserRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UndeleteUserRequest.DiscardUnknown(m)
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+var xxx_messageInfo_UndeleteUserRequest proto.InternalMessageInfo
 
-// UserServiceClient is the client API for UserService service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type UserServiceClient interface {
-	Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error)
-	ReadAll(ctx context.Context, in *ReadAllRequest, opts ...grpc.CallOption) (*ReadAllResponse, error)
-	Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (*ReadResponse, error)
-	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*empty.Empty, error)
-	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+func (m *UndeleteUserRequest) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
 }
 
-type userServiceClient struct {
-	cc *grpc.ClientConn
+type BatchCreateUsersRequest struct {
+	Users                []*User  `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func NewUserServiceClient(cc *grpc.ClientConn) UserServiceClient {
-	return &userServiceClient{cc}
+func (m *BatchCreateUsersRequest) Reset()         { *m = BatchCreateUsersRequest{} }
+func (m *BatchCreateUsersRequest) String() string { return proto.CompactTextString(m) }
+func (*BatchCreateUsersRequest) ProtoMessage()    {}
+func (*BatchCreateUsersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d59e6a97f11722e0, []int{9}
 }
 
-func (c *userServiceClient) Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error) {
-	out := new(CreateResponse)
-	err := c.cc.Invoke(ctx, "/github.reviz0r.layout.profile.UserService/Create", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func (m *BatchCreateUsersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchCreateUsersRequest.Unmarshal(m, b)
+}
+func (m *BatchCreateUsersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchCreateUsersRequest.Marshal(b, m, deterministic)
+}
+func (m *BatchCreateUsersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchCreateUsersRequest.Merge(m, src)
+}
+func (m *BatchCreateUsersRequest) XXX_Size() int {
+	return xxx_messageInfo_BatchCreateUsersRequest.Size(m)
+}
+func (m *BatchCreateUsersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchCreateUsersRequest.DiscardUnknown(m)
 }
 
-func (c *userServiceClient) ReadAll(ctx context.Context, in *ReadAllRequest, opts ...grpc.CallOption) (*ReadAllResponse, error) {
-	out := new(ReadAllResponse)
-	err := c.cc.Invoke(ctx, "/github.reviz0r.layout.profile.UserService/ReadAll", in, out, opts...)
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_BatchCreateUsersRequest proto.InternalMessageInfo
+
+func (m *BatchCreateUsersRequest) GetUsers() []*User {
+	if m != nil {
+		return m.Users
 	}
-	return out, nil
+	return nil
 }
 
-func (c *userServiceClient) Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (*ReadResponse, error) {
-	out := new(ReadResponse)
-	err := c.cc.Invoke(ctx, "/github.reviz0r.layout.profile.UserService/Read", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+type BatchCreateUsersResponse struct {
+	// Ids of created users, in order of request.
+	Ids                  []int64  `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (c *userServiceClient) Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
-	out := new(empty.Empty)
-	err := c.cc.Invoke(ctx, "/github.reviz0r.layout.profile.UserService/Update", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func (m *BatchCreateUsersResponse) Reset()         { *m = BatchCreateUsersResponse{} }
+func (m *BatchCreateUsersResponse) String() string { return proto.CompactTextString(m) }
+func (*BatchCreateUsersResponse) ProtoMessage()    {}
+func (*BatchCreateUsersResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d59e6a97f11722e0, []int{10}
 }
 
-func (c *userServiceClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
-	out := new(empty.Empty)
-	err := c.cc.Invoke(ctx, "/github.reviz0r.layout.profile.UserService/Delete", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *BatchCreateUsersResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchCreateUsersResponse.Unmarshal(m, b)
+}
+func (m *BatchCreateUsersResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchCreateUsersResponse.Marshal(b, m, deterministic)
+}
+func (m *BatchCreateUsersResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchCreateUsersResponse.Merge(m, src)
+}
+func (m *BatchCreateUsersResponse) XXX_Size() int {
+	return xxx_messageInfo_BatchCreateUsersResponse.Size(m)
+}
+func (m *BatchCreateUsersResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchCreateUsersResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchCreateUsersResponse proto.InternalMessageInfo
+
+func (m *BatchCreateUsersResponse) GetIds() []int64 {
+	if m != nil {
+		return m.Ids
 	}
-	return out, nil
+	return nil
 }
 
-// UserServiceServer is the server API for UserService service.
-type UserServiceServer interface {
-	Create(context.Context, *CreateRequest) (*CreateResponse, error)
-	ReadAll(context.Context, *ReadAllRequest) (*ReadAllResponse, error)
-	Read(context.Context, *ReadRequest) (*ReadResponse, error)
-	Update(context.Context, *UpdateRequest) (*empty.Empty, error)
-	Delete(context.Context, *DeleteRequest) (*empty.Empty, error)
+type BatchGetUsersRequest struct {
+	Ids                  []int64               `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	Fields               *field_mask.FieldMask `protobuf:"bytes,2,opt,name=fields,proto3" json:"fields,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
 }
 
-// UnimplementedUserServiceServer can be embedded to have forward compatible implementations.
-type UnimplementedUserServiceServer struct {
+func (m *BatchGetUsersRequest) Reset()         { *m = BatchGetUsersRequest{} }
+func (m *BatchGetUsersRequest) String() string { return proto.CompactTextString(m) }
+func (*BatchGetUsersRequest) ProtoMessage()    {}
+func (*BatchGetUsersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d59e6a97f11722e0, []int{11}
 }
 
-func (*UnimplementedUserServiceServer) Create(ctx context.Context, req *CreateRequest) (*CreateResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+func (m *BatchGetUsersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchGetUsersRequest.Unmarshal(m, b)
 }
-func (*UnimplementedUserServiceServer) ReadAll(ctx context.Context, req *ReadAllRequest) (*ReadAllResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ReadAll not implemented")
+func (m *BatchGetUsersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchGetUsersRequest.Marshal(b, m, deterministic)
 }
-func (*UnimplementedUserServiceServer) Read(ctx context.Context, req *ReadRequest) (*ReadResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Read not implemented")
+func (m *BatchGetUsersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchGetUsersRequest.Merge(m, src)
 }
-func (*UnimplementedUserServiceServer) Update(ctx context.Context, req *UpdateRequest) (*empty.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+func (m *BatchGetUsersRequest) XXX_Size() int {
+	return xxx_messageInfo_BatchGetUsersRequest.Size(m)
 }
-func (*UnimplementedUserServiceServer) Delete(ctx context.Context, req *DeleteRequest) (*empty.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+func (m *BatchGetUsersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchGetUsersRequest.DiscardUnknown(m)
 }
 
-func RegisterUserServiceServer(s *grpc.Server, srv UserServiceServer) {
-	s.RegisterService(&_UserService_serviceDesc, srv)
-}
+var xxx_messageInfo_BatchGetUsersRequest proto.InternalMessageInfo
 
-func _UserService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(UserServiceServer).Create(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/github.reviz0r.layout.profile.UserService/Create",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(UserServiceServer).Create(ctx, req.(*CreateRequest))
+func (m *BatchGetUsersRequest) GetIds() []int64 {
+	if m != nil {
+		return m.Ids
 	}
-	return interceptor(ctx, in, info, handler)
+	return nil
 }
 
-func _UserService_ReadAll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ReadAllRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(UserServiceServer).ReadAll(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/github.reviz0r.layout.profile.UserService/ReadAll",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(UserServiceServer).ReadAll(ctx, req.(*ReadAllRequest))
+func (m *BatchGetUsersRequest) GetFields() *field_mask.FieldMask {
+	if m != nil {
+		return m.Fields
 	}
-	return interceptor(ctx, in, info, handler)
+	return nil
 }
 
-func _UserService_Read_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ReadRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(UserServiceServer).Read(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/github.reviz0r.layout.profile.UserService/Read",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(UserServiceServer).Read(ctx, req.(*ReadRequest))
-	}
-	return interceptor(ctx, in, info, handler)
+type BatchGetUsersResponse struct {
+	Users                []*User  `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func _UserService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(UpdateRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(UserServiceServer).Update(ctx, in)
-	}
+func (m *BatchGetUsersResponse) Reset()         { *m = BatchGetUsersResponse{} }
+func (m *BatchGetUsersResponse) String() string { return proto.CompactTextString(m) }
+func (*BatchGetUsersResponse) ProtoMessage()    {}
+func (*BatchGetUsersResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d59e6a97f11722e0, []int{12}
+}
+
+func (m *BatchGetUsersResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchGetUsersResponse.Unmarshal(m, b)
+}
+func (m *BatchGetUsersResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchGetUsersResponse.Marshal(b, m, deterministic)
+}
+func (m *BatchGetUsersResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchGetUsersResponse.Merge(m, src)
+}
+func (m *BatchGetUsersResponse) XXX_Size() int {
+	return xxx_messageInfo_BatchGetUsersResponse.Size(m)
+}
+func (m *BatchGetUsersResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchGetUsersResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchGetUsersResponse proto.InternalMessageInfo
+
+func (m *BatchGetUsersResponse) GetUsers() []*User {
+	if m != nil {
+		return m.Users
+	}
+	return nil
+}
+
+type BatchDeleteUsersRequest struct {
+	Ids                  []int64  `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BatchDeleteUsersRequest) Reset()         { *m = BatchDeleteUsersRequest{} }
+func (m *BatchDeleteUsersRequest) String() string { return proto.CompactTextString(m) }
+func (*BatchDeleteUsersRequest) ProtoMessage()    {}
+func (*BatchDeleteUsersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d59e6a97f11722e0, []int{13}
+}
+
+func (m *BatchDeleteUsersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchDeleteUsersRequest.Unmarshal(m, b)
+}
+func (m *BatchDeleteUsersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchDeleteUsersRequest.Marshal(b, m, deterministic)
+}
+func (m *BatchDeleteUsersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchDeleteUsersRequest.Merge(m, src)
+}
+func (m *BatchDeleteUsersRequest) XXX_Size() int {
+	return xxx_messageInfo_BatchDeleteUsersRequest.Size(m)
+}
+func (m *BatchDeleteUsersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchDeleteUsersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchDeleteUsersRequest proto.InternalMessageInfo
+
+func (m *BatchDeleteUsersRequest) GetIds() []int64 {
+	if m != nil {
+		return m.Ids
+	}
+	return nil
+}
+
+type ListUserAuditEventsRequest struct {
+	Id int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Maximum number of events to return, 100 by default and 1000 at most.
+	PageSize int32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Opaque cursor from a previous ListUserAuditEventsResponse.next_page_token.
+	PageToken            string   `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListUserAuditEventsRequest) Reset()         { *m = ListUserAuditEventsRequest{} }
+func (m *ListUserAuditEventsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListUserAuditEventsRequest) ProtoMessage()    {}
+func (*ListUserAuditEventsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d59e6a97f11722e0, []int{14}
+}
+
+func (m *ListUserAuditEventsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListUserAuditEventsRequest.Unmarshal(m, b)
+}
+func (m *ListUserAuditEventsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListUserAuditEventsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListUserAuditEventsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListUserAuditEventsRequest.Merge(m, src)
+}
+func (m *ListUserAuditEventsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListUserAuditEventsRequest.Size(m)
+}
+func (m *ListUserAuditEventsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListUserAuditEventsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListUserAuditEventsRequest proto.InternalMessageInfo
+
+func (m *ListUserAuditEventsRequest) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *ListUserAuditEventsRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *ListUserAuditEventsRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+type ListUserAuditEventsResponse struct {
+	Events []*UserAuditEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	// Cursor of the next page, empty if there are no more events.
+	NextPageToken        string   `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListUserAuditEventsResponse) Reset()         { *m = ListUserAuditEventsResponse{} }
+func (m *ListUserAuditEventsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListUserAuditEventsResponse) ProtoMessage()    {}
+func (*ListUserAuditEventsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d59e6a97f11722e0, []int{15}
+}
+
+func (m *ListUserAuditEventsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListUserAuditEventsResponse.Unmarshal(m, b)
+}
+func (m *ListUserAuditEventsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListUserAuditEventsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListUserAuditEventsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListUserAuditEventsResponse.Merge(m, src)
+}
+func (m *ListUserAuditEventsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListUserAuditEventsResponse.Size(m)
+}
+func (m *ListUserAuditEventsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListUserAuditEventsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListUserAuditEventsResponse proto.InternalMessageInfo
+
+func (m *ListUserAuditEventsResponse) GetEvents() []*UserAuditEvent {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+func (m *ListUserAuditEventsResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+type WatchUsersRequest struct {
+	// Send changes made after this revision, only new changes are sent if it is 0.
+	Revision             int64    `protobuf:"varint,1,opt,name=revision,proto3" json:"revision,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchUsersRequest) Reset()         { *m = WatchUsersRequest{} }
+func (m *WatchUsersRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchUsersRequest) ProtoMessage()    {}
+func (*WatchUsersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d59e6a97f11722e0, []int{16}
+}
+
+func (m *WatchUsersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchUsersRequest.Unmarshal(m, b)
+}
+func (m *WatchUsersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchUsersRequest.Marshal(b, m, deterministic)
+}
+func (m *WatchUsersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchUsersRequest.Merge(m, src)
+}
+func (m *WatchUsersRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchUsersRequest.Size(m)
+}
+func (m *WatchUsersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchUsersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchUsersRequest proto.InternalMessageInfo
+
+func (m *WatchUsersRequest) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+type ImportUsersRequest struct {
+	// Check rows without creating users, it is read from the first message of stream.
+	DryRun               bool                      `protobuf:"varint,1,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	Rows                 []*ImportUsersRequest_Row `protobuf:"bytes,2,rep,name=rows,proto3" json:"rows,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                  `json:"-"`
+	XXX_unrecognized     []byte                    `json:"-"`
+	XXX_sizecache        int32                     `json:"-"`
+}
+
+func (m *ImportUsersRequest) Reset()         { *m = ImportUsersRequest{} }
+func (m *ImportUsersRequest) String() string { return proto.CompactTextString(m) }
+func (*ImportUsersRequest) ProtoMessage()    {}
+func (*ImportUsersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d59e6a97f11722e0, []int{17}
+}
+
+func (m *ImportUsersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportUsersRequest.Unmarshal(m, b)
+}
+func (m *ImportUsersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportUsersRequest.Marshal(b, m, deterministic)
+}
+func (m *ImportUsersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportUsersRequest.Merge(m, src)
+}
+func (m *ImportUsersRequest) XXX_Size() int {
+	return xxx_messageInfo_ImportUsersRequest.Size(m)
+}
+func (m *ImportUsersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportUsersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportUsersRequest proto.InternalMessageInfo
+
+func (m *ImportUsersRequest) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+func (m *ImportUsersRequest) GetRows() []*ImportUsersRequest_Row {
+	if m != nil {
+		return m.Rows
+	}
+	return nil
+}
+
+// Row is user to import, it is checked by validation rules of User.
+type ImportUsersRequest_Row struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Email                string   `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ImportUsersRequest_Row) Reset()         { *m = ImportUsersRequest_Row{} }
+func (m *ImportUsersRequest_Row) String() string { return proto.CompactTextString(m) }
+func (*ImportUsersRequest_Row) ProtoMessage()    {}
+func (*ImportUsersRequest_Row) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d59e6a97f11722e0, []int{17, 0}
+}
+
+func (m *ImportUsersRequest_Row) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportUsersRequest_Row.Unmarshal(m, b)
+}
+func (m *ImportUsersRequest_Row) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportUsersRequest_Row.Marshal(b, m, deterministic)
+}
+func (m *ImportUsersRequest_Row) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportUsersRequest_Row.Merge(m, src)
+}
+func (m *ImportUsersRequest_Row) XXX_Size() int {
+	return xxx_messageInfo_ImportUsersRequest_Row.Size(m)
+}
+func (m *ImportUsersRequest_Row) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportUsersRequest_Row.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportUsersRequest_Row proto.InternalMessageInfo
+
+func (m *ImportUsersRequest_Row) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ImportUsersRequest_Row) GetEmail() string {
+	if m != nil {
+		return m.Email
+	}
+	return ""
+}
+
+type ImportUsersResponse struct {
+	// Number of created users, or of users which would be created in dry run.
+	Inserted int32 `protobuf:"varint,1,opt,name=inserted,proto3" json:"inserted,omitempty"`
+	// Number of rows skipped as duplicates by email.
+	Skipped int32 `protobuf:"varint,2,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	// Number of invalid rows.
+	Failed int32 `protobuf:"varint,3,opt,name=failed,proto3" json:"failed,omitempty"`
+	// Skipped and failed rows in order of stream, only the first 1000 problems are reported.
+	Problems             []*ImportUsersResponse_Problem `protobuf:"bytes,4,rep,name=problems,proto3" json:"problems,omitempty"`
+	DryRun               bool                           `protobuf:"varint,5,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                       `json:"-"`
+	XXX_unrecognized     []byte                         `json:"-"`
+	XXX_sizecache        int32                          `json:"-"`
+}
+
+func (m *ImportUsersResponse) Reset()         { *m = ImportUsersResponse{} }
+func (m *ImportUsersResponse) String() string { return proto.CompactTextString(m) }
+func (*ImportUsersResponse) ProtoMessage()    {}
+func (*ImportUsersResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d59e6a97f11722e0, []int{18}
+}
+
+func (m *ImportUsersResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportUsersResponse.Unmarshal(m, b)
+}
+func (m *ImportUsersResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportUsersResponse.Marshal(b, m, deterministic)
+}
+func (m *ImportUsersResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportUsersResponse.Merge(m, src)
+}
+func (m *ImportUsersResponse) XXX_Size() int {
+	return xxx_messageInfo_ImportUsersResponse.Size(m)
+}
+func (m *ImportUsersResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportUsersResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportUsersResponse proto.InternalMessageInfo
+
+func (m *ImportUsersResponse) GetInserted() int32 {
+	if m != nil {
+		return m.Inserted
+	}
+	return 0
+}
+
+func (m *ImportUsersResponse) GetSkipped() int32 {
+	if m != nil {
+		return m.Skipped
+	}
+	return 0
+}
+
+func (m *ImportUsersResponse) GetFailed() int32 {
+	if m != nil {
+		return m.Failed
+	}
+	return 0
+}
+
+func (m *ImportUsersResponse) GetProblems() []*ImportUsersResponse_Problem {
+	if m != nil {
+		return m.Problems
+	}
+	return nil
+}
+
+func (m *ImportUsersResponse) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+// Problem is a skipped or failed row.
+type ImportUsersResponse_Problem struct {
+	// Number of row in the whole stream, starting from 1.
+	Row                  int32                            `protobuf:"varint,1,opt,name=row,proto3" json:"row,omitempty"`
+	Kind                 ImportUsersResponse_Problem_Kind `protobuf:"varint,2,opt,name=kind,proto3,enum=github.reviz0r.layout.profile.ImportUsersResponse_Problem_Kind" json:"kind,omitempty"`
+	Message              string                           `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                         `json:"-"`
+	XXX_unrecognized     []byte                           `json:"-"`
+	XXX_sizecache        int32                            `json:"-"`
+}
+
+func (m *ImportUsersResponse_Problem) Reset()         { *m = ImportUsersResponse_Problem{} }
+func (m *ImportUsersResponse_Problem) String() string { return proto.CompactTextString(m) }
+func (*ImportUsersResponse_Problem) ProtoMessage()    {}
+func (*ImportUsersResponse_Problem) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d59e6a97f11722e0, []int{18, 0}
+}
+
+func (m *ImportUsersResponse_Problem) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportUsersResponse_Problem.Unmarshal(m, b)
+}
+func (m *ImportUsersResponse_Problem) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportUsersResponse_Problem.Marshal(b, m, deterministic)
+}
+func (m *ImportUsersResponse_Problem) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportUsersResponse_Problem.Merge(m, src)
+}
+func (m *ImportUsersResponse_Problem) XXX_Size() int {
+	return xxx_messageInfo_ImportUsersResponse_Problem.Size(m)
+}
+func (m *ImportUsersResponse_Problem) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportUsersResponse_Problem.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportUsersResponse_Problem proto.InternalMessageInfo
+
+func (m *ImportUsersResponse_Problem) GetRow() int32 {
+	if m != nil {
+		return m.Row
+	}
+	return 0
+}
+
+func (m *ImportUsersResponse_Problem) GetKind() ImportUsersResponse_Problem_Kind {
+	if m != nil {
+		return m.Kind
+	}
+	return ImportUsersResponse_Problem_KIND_UNSPECIFIED
+}
+
+func (m *ImportUsersResponse_Problem) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type ExportUsersRequest struct {
+	// Filter expression, as in ReadAllRequest.
+	Filter string `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	// Include deleted users.
+	ShowDeleted          bool     `protobuf:"varint,2,opt,name=show_deleted,json=showDeleted,proto3" json:"show_deleted,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExportUsersRequest) Reset()         { *m = ExportUsersRequest{} }
+func (m *ExportUsersRequest) String() string { return proto.CompactTextString(m) }
+func (*ExportUsersRequest) ProtoMessage()    {}
+func (*ExportUsersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d59e6a97f11722e0, []int{19}
+}
+
+func (m *ExportUsersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExportUsersRequest.Unmarshal(m, b)
+}
+func (m *ExportUsersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExportUsersRequest.Marshal(b, m, deterministic)
+}
+func (m *ExportUsersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExportUsersRequest.Merge(m, src)
+}
+func (m *ExportUsersRequest) XXX_Size() int {
+	return xxx_messageInfo_ExportUsersRequest.Size(m)
+}
+func (m *ExportUsersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExportUsersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExportUsersRequest proto.InternalMessageInfo
+
+func (m *ExportUsersRequest) GetFilter() string {
+	if m != nil {
+		return m.Filter
+	}
+	return ""
+}
+
+func (m *ExportUsersRequest) GetShowDeleted() bool {
+	if m != nil {
+		return m.ShowDeleted
+	}
+	return false
+}
+
+func init() {
+	proto.RegisterEnum("github.reviz0r.layout.profile.ImportUsersResponse_Problem_Kind", ImportUsersResponse_Problem_Kind_name, ImportUsersResponse_Problem_Kind_value)
+	proto.RegisterType((*CreateRequest)(nil), "github.reviz0r.layout.profile.CreateRequest")
+	proto.RegisterType((*CreateResponse)(nil), "github.reviz0r.layout.profile.CreateResponse")
+	proto.RegisterType((*ReadAllRequest)(nil), "github.reviz0r.layout.profile.ReadAllRequest")
+	proto.RegisterType((*ReadAllResponse)(nil), "github.reviz0r.layout.profile.ReadAllResponse")
+	proto.RegisterType((*ReadRequest)(nil), "github.reviz0r.layout.profile.ReadRequest")
+	proto.RegisterType((*ReadResponse)(nil), "github.reviz0r.layout.profile.ReadResponse")
+	proto.RegisterType((*UpdateRequest)(nil), "github.reviz0r.layout.profile.UpdateRequest")
+	proto.RegisterType((*DeleteRequest)(nil), "github.reviz0r.layout.profile.DeleteRequest")
+	proto.RegisterType((*UndeleteUserRequest)(nil), "github.reviz0r.layout.profile.UndeleteUserRequest")
+	proto.RegisterType((*BatchCreateUsersRequest)(nil), "github.reviz0r.layout.profile.BatchCreateUsersRequest")
+	proto.RegisterType((*BatchCreateUsersResponse)(nil), "github.reviz0r.layout.profile.BatchCreateUsersResponse")
+	proto.RegisterType((*BatchGetUsersRequest)(nil), "github.reviz0r.layout.profile.BatchGetUsersRequest")
+	proto.RegisterType((*BatchGetUsersResponse)(nil), "github.reviz0r.layout.profile.BatchGetUsersResponse")
+	proto.RegisterType((*BatchDeleteUsersRequest)(nil), "github.reviz0r.layout.profile.BatchDeleteUsersRequest")
+	proto.RegisterType((*ListUserAuditEventsRequest)(nil), "github.reviz0r.layout.profile.ListUserAuditEventsRequest")
+	proto.RegisterType((*ListUserAuditEventsResponse)(nil), "github.reviz0r.layout.profile.ListUserAuditEventsResponse")
+	proto.RegisterType((*WatchUsersRequest)(nil), "github.reviz0r.layout.profile.WatchUsersRequest")
+	proto.RegisterType((*ImportUsersRequest)(nil), "github.reviz0r.layout.profile.ImportUsersRequest")
+	proto.RegisterType((*ImportUsersRequest_Row)(nil), "github.reviz0r.layout.profile.ImportUsersRequest.Row")
+	proto.RegisterType((*ImportUsersResponse)(nil), "github.reviz0r.layout.profile.ImportUsersResponse")
+	proto.RegisterType((*ImportUsersResponse_Problem)(nil), "github.reviz0r.layout.profile.ImportUsersResponse.Problem")
+	proto.RegisterType((*ExportUsersRequest)(nil), "github.reviz0r.layout.profile.ExportUsersRequest")
+}
+
+func init() { proto.RegisterFile("profile_api.proto", fileDescriptor_d59e6a97f11722e0) }
+
+var fileDescriptor_d59e6a97f11722e0 = []byte{
+	// 1889 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x57, 0xcd, 0x6f, 0x23, 0x49,
+	0x15, 0xdf, 0xb6, 0x13, 0x3b, 0x29, 0x4f, 0x12, 0xa7, 0x26, 0x93, 0xf1, 0xf6, 0xec, 0x28, 0x45,
+	0xaf, 0x84, 0xc2, 0x4c, 0xd2, 0x9e, 0xc9, 0xb0, 0x2c, 0x33, 0x1c, 0x16, 0x67, 0x93, 0x19, 0xa2,
+	0x59, 0x86, 0xa8, 0xb3, 0x01, 0xb4, 0x17, 0xd3, 0x71, 0x3f, 0xdb, 0xa5, 0xb4, 0xbb, 0x4d, 0x55,
+	0xd9, 0x8e, 0x87, 0xe5, 0xc2, 0x05, 0x90, 0x56, 0x42, 0x18, 0x90, 0x40, 0x70, 0xe2, 0xc4, 0x11,
+	0x71, 0x01, 0x71, 0xe3, 0x6f, 0xe0, 0x4e, 0xa4, 0x68, 0x0f, 0x9c, 0xf8, 0x0b, 0x10, 0xa0, 0xfa,
+	0x68, 0x7f, 0x26, 0x63, 0x27, 0xf8, 0xe2, 0xae, 0xd7, 0xef, 0xf3, 0x57, 0xbf, 0x7a, 0xf5, 0x1a,
+	0xad, 0x36, 0x59, 0x5c, 0xa5, 0x21, 0x94, 0xfd, 0x26, 0x75, 0x9b, 0x2c, 0x16, 0x31, 0xbe, 0x5f,
+	0xa3, 0xa2, 0xde, 0x3a, 0x71, 0x19, 0xb4, 0xe9, 0xeb, 0x47, 0xcc, 0x0d, 0xfd, 0x6e, 0xdc, 0x12,
+	0xae, 0x51, 0xb4, 0xdf, 0xa9, 0xc5, 0x71, 0x2d, 0x84, 0xa2, 0xdf, 0xa4, 0x45, 0x3f, 0x8a, 0x62,
+	0xe1, 0x0b, 0x1a, 0x47, 0x5c, 0x1b, 0xdb, 0xf7, 0xcc, 0x5b, 0xb5, 0x3a, 0x69, 0x55, 0x8b, 0xd0,
+	0x68, 0x8a, 0xae, 0x79, 0x49, 0xc6, 0x5f, 0x56, 0x29, 0x84, 0x41, 0xb9, 0xe1, 0xf3, 0x53, 0xa3,
+	0x91, 0x6b, 0xc4, 0x01, 0x84, 0x66, 0xb1, 0xa5, 0xfe, 0x2a, 0xdb, 0x35, 0x88, 0xb6, 0x79, 0xc7,
+	0xaf, 0xd5, 0x80, 0x15, 0xe3, 0xa6, 0x8a, 0x76, 0x49, 0xe4, 0x95, 0xb6, 0x1f, 0xd2, 0xc0, 0x17,
+	0x31, 0xd3, 0x02, 0xe7, 0x10, 0x2d, 0x7d, 0xc8, 0xc0, 0x17, 0xe0, 0xc1, 0xf7, 0x5b, 0xc0, 0x05,
+	0xfe, 0x00, 0xcd, 0xb5, 0x38, 0xb0, 0x82, 0x45, 0xac, 0xcd, 0xdc, 0xce, 0xbb, 0xee, 0x1b, 0xeb,
+	0x74, 0x8f, 0x39, 0xb0, 0xdd, 0xcc, 0xc5, 0xf9, 0x46, 0x8a, 0x58, 0x9e, 0x32, 0x74, 0x08, 0x5a,
+	0x4e, 0x3c, 0xf2, 0x66, 0x1c, 0x71, 0xc0, 0xcb, 0x28, 0x45, 0x03, 0xe5, 0x30, 0xed, 0xa5, 0x68,
+	0xe0, 0x7c, 0x96, 0x42, 0xcb, 0x1e, 0xf8, 0x41, 0x29, 0x0c, 0x93, 0xa8, 0x6b, 0x68, 0x3e, 0xa4,
+	0x0d, 0x2a, 0x94, 0xd6, 0xbc, 0xa7, 0x17, 0x78, 0x1d, 0x65, 0xe2, 0x6a, 0x95, 0x83, 0x28, 0xa4,
+	0x94, 0xd8, 0xac, 0xf0, 0x0e, 0xca, 0x28, 0x50, 0x78, 0x21, 0xad, 0xb2, 0xb4, 0x5d, 0x8d, 0x99,
+	0x9b, 0x60, 0xe6, 0x3e, 0x97, 0xaf, 0xbf, 0xe9, 0xf3, 0x53, 0xcf, 0x68, 0xe2, 0xfb, 0x08, 0x35,
+	0xfd, 0x1a, 0x94, 0x45, 0x7c, 0x0a, 0x51, 0x61, 0x8e, 0x58, 0x9b, 0x8b, 0xde, 0xa2, 0x94, 0x7c,
+	0x2c, 0x05, 0xf8, 0x5d, 0xb4, 0x44, 0xa3, 0x4a, 0xd8, 0x0a, 0xa4, 0x86, 0xf0, 0xc3, 0xc2, 0x3c,
+	0xb1, 0x36, 0x17, 0xbc, 0x5b, 0x46, 0xf8, 0xb1, 0x94, 0xc9, 0x7c, 0xaa, 0x34, 0x14, 0xc0, 0x0a,
+	0x19, 0x65, 0x6f, 0x56, 0xf8, 0x6d, 0xb4, 0x10, 0xb3, 0x00, 0x58, 0xf9, 0xa4, 0x5b, 0xc8, 0xaa,
+	0x37, 0x59, 0xb5, 0xde, 0xed, 0xe2, 0x2f, 0xa0, 0x5b, 0xbc, 0x1e, 0x77, 0xca, 0x01, 0x84, 0x20,
+	0x20, 0x28, 0x2c, 0x28, 0xb7, 0x39, 0x29, 0xdb, 0xd3, 0x22, 0xe7, 0x2f, 0x16, 0x5a, 0xe9, 0xc3,
+	0x61, 0x20, 0x7b, 0x8a, 0xe6, 0x25, 0x98, 0xbc, 0x60, 0x91, 0xf4, 0x8c, 0xdb, 0xe0, 0x69, 0x8b,
+	0x01, 0x94, 0xa9, 0xcb, 0xa1, 0x4c, 0x8f, 0x40, 0xb9, 0x86, 0xe6, 0x75, 0xbd, 0x73, 0x5a, 0x5b,
+	0x2d, 0xf0, 0x17, 0xd1, 0x4a, 0x04, 0x67, 0xa2, 0x3c, 0x84, 0xd8, 0xbc, 0xaa, 0x6b, 0x49, 0x8a,
+	0x0f, 0x13, 0xd4, 0x9c, 0x4f, 0x51, 0x4e, 0x66, 0x9e, 0xec, 0xe2, 0xfa, 0x60, 0xa3, 0x35, 0x29,
+	0xf2, 0x6f, 0xc9, 0x0d, 0x1f, 0xda, 0xaf, 0xd4, 0xcc, 0xfb, 0x35, 0x0e, 0x5c, 0x7a, 0x12, 0xb8,
+	0x17, 0xe8, 0x96, 0x8e, 0x6e, 0x40, 0x7b, 0xff, 0xda, 0xd4, 0x35, 0x94, 0xfd, 0xa3, 0x85, 0x96,
+	0x8e, 0x9b, 0xc1, 0xd0, 0x29, 0xb8, 0xaa, 0x92, 0xe4, 0x74, 0xa4, 0x6e, 0x78, 0x3a, 0x6e, 0x44,
+	0x5d, 0x8c, 0xe6, 0x40, 0xf8, 0x35, 0x43, 0x5a, 0xf5, 0xec, 0x7c, 0x0d, 0x2d, 0x69, 0x18, 0xa6,
+	0x65, 0x9c, 0x18, 0xa7, 0x86, 0x8c, 0xb7, 0xd1, 0xed, 0xe3, 0x48, 0x03, 0xab, 0x50, 0x78, 0xb3,
+	0x0b, 0xa7, 0x8c, 0xee, 0xee, 0xfa, 0xa2, 0x52, 0xd7, 0xc7, 0x5a, 0x5a, 0xf0, 0xc4, 0x64, 0xef,
+	0xfa, 0x3c, 0xdd, 0x5d, 0xbc, 0x38, 0xdf, 0x98, 0xff, 0x9e, 0x55, 0xff, 0x67, 0xd6, 0x50, 0xd6,
+	0xd9, 0x42, 0x85, 0xc9, 0x00, 0x66, 0x53, 0xf3, 0x28, 0x4d, 0x03, 0xed, 0x3f, 0xed, 0xc9, 0x47,
+	0x87, 0xa2, 0x35, 0xa5, 0xfd, 0x02, 0xc4, 0x48, 0x2e, 0xf7, 0x87, 0x34, 0x77, 0x73, 0x17, 0xe7,
+	0x1b, 0xd9, 0xfc, 0x5b, 0x3a, 0x8c, 0x94, 0xdf, 0x84, 0x84, 0x8e, 0x87, 0xee, 0x8c, 0x85, 0xfa,
+	0xbf, 0xcf, 0xa7, 0xf3, 0x55, 0x83, 0xe6, 0x5e, 0x1f, 0xff, 0x19, 0x2b, 0x70, 0x9a, 0xc8, 0xfe,
+	0x88, 0x72, 0x95, 0x49, 0xa9, 0x15, 0x50, 0xb1, 0xdf, 0x86, 0x48, 0xf0, 0x69, 0x04, 0xb8, 0x87,
+	0x54, 0x9b, 0x2b, 0x73, 0xfa, 0x1a, 0x4c, 0x4f, 0x58, 0x90, 0x82, 0x23, 0xfa, 0x1a, 0xc6, 0xba,
+	0x62, 0x7a, 0xac, 0x2b, 0x3a, 0x9f, 0x59, 0xe8, 0xde, 0xa5, 0x21, 0x0d, 0x0c, 0xfb, 0x28, 0x03,
+	0x4a, 0x62, 0x70, 0xd8, 0x9e, 0x01, 0x87, 0x81, 0x1f, 0xcf, 0x18, 0x5f, 0xd6, 0x6e, 0x52, 0x97,
+	0xb5, 0x9b, 0xaf, 0xa3, 0xd5, 0xef, 0x48, 0xe8, 0x46, 0x40, 0x7b, 0x88, 0x16, 0x64, 0x34, 0x4e,
+	0xe3, 0xc8, 0x54, 0xbf, 0x72, 0x71, 0xbe, 0x91, 0xcb, 0xff, 0x37, 0xf9, 0x59, 0x5e, 0x5f, 0xc1,
+	0xf9, 0x93, 0x85, 0xf0, 0x41, 0xa3, 0x19, 0xb3, 0x51, 0xea, 0xdc, 0x45, 0xd9, 0x80, 0x75, 0xcb,
+	0xac, 0xa5, 0x5d, 0x2c, 0x78, 0x99, 0x80, 0x75, 0xbd, 0x56, 0x84, 0x3d, 0x34, 0xc7, 0xe2, 0x8e,
+	0xa4, 0x8c, 0x2c, 0xef, 0xbd, 0x29, 0xe5, 0x4d, 0x7a, 0x76, 0xbd, 0xb8, 0xb3, 0x9b, 0xbd, 0x38,
+	0xdf, 0x48, 0xcb, 0x5d, 0x54, 0xbe, 0xec, 0x22, 0x4a, 0x7b, 0x71, 0x47, 0x1e, 0xcc, 0xc8, 0x6f,
+	0x80, 0x0a, 0xb8, 0xe8, 0xa9, 0x67, 0xd9, 0x8d, 0xa1, 0xe1, 0xd3, 0xd0, 0x94, 0xaf, 0x17, 0xce,
+	0xaf, 0xd3, 0xe8, 0xf6, 0x88, 0x6b, 0x83, 0xbe, 0x8d, 0x16, 0x68, 0xc4, 0x81, 0xc9, 0xf6, 0xa8,
+	0xef, 0xcd, 0xfe, 0x1a, 0x17, 0x50, 0x96, 0x9f, 0xd2, 0x66, 0x13, 0x02, 0xb3, 0xe7, 0xc9, 0x52,
+	0x5d, 0x62, 0x3e, 0x0d, 0x4d, 0x4b, 0x9d, 0xf7, 0xcc, 0x0a, 0x7f, 0x1b, 0x2d, 0x34, 0x59, 0x7c,
+	0x12, 0x42, 0x83, 0x17, 0xe6, 0x54, 0xb9, 0xcf, 0xae, 0x53, 0xae, 0xce, 0xc9, 0x3d, 0xd4, 0x2e,
+	0xbc, 0xbe, 0xaf, 0x61, 0x6c, 0xe7, 0x87, 0xb1, 0xb5, 0xff, 0x66, 0xa1, 0xac, 0x51, 0x97, 0xa7,
+	0x9c, 0xc5, 0x1d, 0x53, 0x85, 0x7c, 0xc4, 0x47, 0x68, 0xee, 0x94, 0x46, 0x3a, 0xfb, 0xe5, 0x9d,
+	0x0f, 0x6e, 0x9e, 0x8a, 0xfb, 0x92, 0x46, 0x81, 0xa7, 0x9c, 0x49, 0x54, 0x1a, 0xc0, 0xb9, 0x5f,
+	0x03, 0xc3, 0xf5, 0x64, 0xe9, 0xbc, 0x87, 0xe6, 0xa4, 0x1e, 0x5e, 0x43, 0xf9, 0x97, 0x07, 0xaf,
+	0xf6, 0xca, 0xc7, 0xaf, 0x8e, 0x0e, 0xf7, 0x3f, 0x3c, 0x78, 0x7e, 0xb0, 0xbf, 0x97, 0x7f, 0x0b,
+	0xe7, 0x50, 0xf6, 0xe8, 0xe5, 0xc1, 0xe1, 0xe1, 0xfe, 0x5e, 0xde, 0xc2, 0x08, 0x65, 0x9e, 0x97,
+	0x0e, 0x3e, 0xda, 0xdf, 0xcb, 0xa7, 0x9c, 0x6f, 0x21, 0xbc, 0x7f, 0x36, 0x41, 0xa7, 0xc1, 0x9c,
+	0x60, 0x8d, 0xcc, 0x09, 0xe3, 0x77, 0x5a, 0x6a, 0xe2, 0x4e, 0xdb, 0xf9, 0xf7, 0x1a, 0xca, 0x49,
+	0x5f, 0x47, 0xc0, 0xda, 0xb4, 0x02, 0xb8, 0x67, 0xa1, 0x8c, 0x6e, 0x8b, 0x78, 0x6b, 0x0a, 0x06,
+	0x23, 0x73, 0x9c, 0xbd, 0x3d, 0xa3, 0xb6, 0x06, 0xcb, 0x79, 0xd8, 0x2b, 0xad, 0xe2, 0x15, 0x2d,
+	0x24, 0x11, 0x74, 0x48, 0x8b, 0x03, 0xfb, 0xd1, 0xdf, 0x3f, 0xff, 0x45, 0x6a, 0xd5, 0x59, 0x2c,
+	0xb6, 0x1f, 0x17, 0xe5, 0x9a, 0x3f, 0xd3, 0x97, 0xd8, 0x6f, 0x2d, 0x94, 0x35, 0x13, 0x0b, 0x9e,
+	0x16, 0x67, 0x74, 0xd0, 0xb3, 0xdd, 0x59, 0xd5, 0x4d, 0x5e, 0x8f, 0x7b, 0xa5, 0xfb, 0xf8, 0xde,
+	0x0b, 0x10, 0xc4, 0x0f, 0x43, 0x95, 0x14, 0x27, 0x9b, 0x1d, 0x2a, 0xea, 0xa4, 0xe9, 0xd7, 0x68,
+	0x54, 0xfb, 0x92, 0xca, 0x31, 0x87, 0x07, 0x39, 0xe2, 0x5f, 0x5a, 0x68, 0x4e, 0xba, 0xc1, 0x0f,
+	0x66, 0x88, 0x95, 0xe4, 0xf5, 0x70, 0x26, 0x5d, 0x93, 0xd4, 0x93, 0x5e, 0x69, 0x0d, 0x63, 0x99,
+	0x54, 0x1c, 0x81, 0x4a, 0x8a, 0x9c, 0x74, 0x09, 0x0d, 0x54, 0x2e, 0xeb, 0x78, 0xb9, 0x9f, 0x4b,
+	0xf1, 0x07, 0x34, 0xf8, 0xe1, 0x89, 0x06, 0xed, 0xa7, 0x16, 0xca, 0xe8, 0x21, 0x63, 0xea, 0x4e,
+	0x8e, 0xcc, 0x22, 0xf6, 0xfa, 0xc4, 0x45, 0xb5, 0x2f, 0x3f, 0x17, 0x9c, 0xa7, 0xbd, 0x92, 0x8d,
+	0x0b, 0x5a, 0x57, 0x27, 0xa1, 0x6f, 0xad, 0xe1, 0x5c, 0x76, 0xc6, 0x72, 0x31, 0x1b, 0xf8, 0x29,
+	0xca, 0x68, 0xc2, 0x4d, 0x4d, 0x65, 0x64, 0xc8, 0xb8, 0x32, 0x95, 0xad, 0x5e, 0xe9, 0x36, 0x5e,
+	0xd5, 0xba, 0xe3, 0x78, 0xe4, 0x1f, 0x8c, 0xe5, 0x80, 0xff, 0x65, 0xa1, 0x5b, 0xc3, 0xf3, 0x07,
+	0xde, 0x99, 0x86, 0xc7, 0xe4, 0xb0, 0x72, 0x65, 0x2a, 0x3f, 0xb7, 0x7a, 0xa5, 0x10, 0xdb, 0x1e,
+	0x70, 0x11, 0x33, 0x20, 0xe6, 0xa8, 0x0d, 0x25, 0x65, 0xbf, 0xda, 0x1b, 0x92, 0x71, 0xe2, 0x33,
+	0x20, 0xcd, 0x16, 0xab, 0x41, 0x40, 0xfc, 0xaa, 0x00, 0x46, 0x78, 0xdc, 0x00, 0x22, 0x68, 0x03,
+	0xb6, 0x88, 0xa8, 0x43, 0x97, 0x54, 0xfc, 0x88, 0x44, 0xb1, 0x20, 0x27, 0x40, 0x98, 0xf6, 0x9a,
+	0x68, 0x8a, 0xba, 0x2f, 0x5c, 0x55, 0xe4, 0x3b, 0xce, 0xdd, 0x31, 0xa0, 0x5b, 0x26, 0xe7, 0x67,
+	0xd6, 0x03, 0xfc, 0x79, 0x0a, 0xe5, 0xc7, 0x07, 0x1c, 0xfc, 0x95, 0x29, 0x45, 0x5f, 0x31, 0x72,
+	0xd9, 0xef, 0x5f, 0xdb, 0xce, 0xb0, 0xf6, 0x1f, 0x56, 0xaf, 0xf4, 0x57, 0x0b, 0xbf, 0xad, 0xdf,
+	0x91, 0x86, 0x1f, 0x75, 0x13, 0x08, 0x24, 0x91, 0x2b, 0x60, 0xff, 0xce, 0x2a, 0x85, 0xe1, 0x10,
+	0x2c, 0x15, 0xa5, 0x19, 0x10, 0x1a, 0x29, 0xa2, 0x0b, 0xe6, 0x47, 0xdc, 0xaf, 0xc8, 0xef, 0x49,
+	0x97, 0x1c, 0x54, 0x35, 0x50, 0x03, 0x6d, 0x1a, 0xa9, 0x8f, 0xcb, 0x2d, 0x09, 0x56, 0x9d, 0x46,
+	0x35, 0x42, 0x79, 0xdf, 0x83, 0x1f, 0x05, 0x04, 0xda, 0xc0, 0xba, 0x89, 0x96, 0xde, 0x10, 0xca,
+	0x09, 0x03, 0xd9, 0x3e, 0x75, 0x10, 0xb3, 0xa3, 0xac, 0x59, 0x71, 0x77, 0xfb, 0x47, 0x93, 0x04,
+	0x20, 0x7c, 0x1a, 0x72, 0x0d, 0xb4, 0xed, 0xdc, 0x19, 0x74, 0xa3, 0x93, 0x41, 0xa9, 0x12, 0xe6,
+	0x5e, 0x0a, 0x2d, 0x8d, 0x8c, 0x6b, 0xf8, 0xc9, 0x2c, 0x58, 0x8d, 0xcd, 0x91, 0xf6, 0x97, 0xaf,
+	0x67, 0x64, 0xd0, 0xfd, 0x95, 0xd5, 0x2b, 0x9d, 0xe1, 0x3b, 0xb2, 0x29, 0x0c, 0x21, 0xab, 0x18,
+	0xc7, 0xed, 0xf2, 0x71, 0x1f, 0x25, 0x06, 0xa2, 0xc5, 0x22, 0x03, 0x2a, 0x0b, 0x80, 0x91, 0xb8,
+	0x4a, 0x98, 0x0e, 0x2d, 0xa5, 0x01, 0x1f, 0xc5, 0x96, 0x04, 0x31, 0x70, 0xc5, 0x3f, 0x38, 0xa3,
+	0x5c, 0x8c, 0xa0, 0x9b, 0xf8, 0xd2, 0xd0, 0xac, 0x61, 0x3c, 0x06, 0xcd, 0x0b, 0x10, 0xf8, 0x3f,
+	0x96, 0xe1, 0xde, 0xd0, 0xbc, 0x39, 0x1b, 0xf7, 0x26, 0x07, 0xd4, 0x2b, 0x0f, 0xdd, 0x6f, 0xac,
+	0x5e, 0xa9, 0x8d, 0x0b, 0xda, 0xe2, 0x92, 0xfa, 0x3f, 0x19, 0xe5, 0x55, 0x72, 0x28, 0xa7, 0xf1,
+	0xea, 0x4d, 0xb5, 0x1b, 0x1f, 0x57, 0xb1, 0x62, 0xaf, 0x7f, 0xf8, 0xfe, 0x9c, 0x42, 0xb7, 0x2f,
+	0x99, 0x61, 0xf1, 0xd3, 0x29, 0x18, 0x5c, 0x3d, 0x6a, 0xdb, 0xcf, 0x6e, 0x62, 0x6a, 0x78, 0xf2,
+	0x07, 0xab, 0x57, 0xfa, 0x89, 0x85, 0xb7, 0x24, 0x51, 0xea, 0x54, 0xb6, 0x93, 0xae, 0x64, 0x40,
+	0xa5, 0xee, 0x47, 0x35, 0xe0, 0xf2, 0x51, 0x55, 0x3e, 0x72, 0xc7, 0xd9, 0xdf, 0xd5, 0x4e, 0x46,
+	0x09, 0x54, 0x65, 0x71, 0x43, 0x76, 0x28, 0x12, 0x87, 0x81, 0x3c, 0x27, 0x22, 0x56, 0xab, 0x08,
+	0x3a, 0xc0, 0x85, 0x4b, 0xbe, 0x61, 0x9c, 0x53, 0x4e, 0x4e, 0xa1, 0x29, 0x4c, 0xcb, 0x4a, 0x8e,
+	0x9d, 0xee, 0x78, 0xae, 0xb9, 0xb1, 0xd6, 0x46, 0x9b, 0x57, 0xd1, 0x97, 0x89, 0xe3, 0x9f, 0xa5,
+	0x10, 0x1a, 0xcc, 0xdb, 0xf8, 0xd1, 0x94, 0xaa, 0x27, 0x46, 0x73, 0x7b, 0x73, 0x86, 0xcf, 0x01,
+	0x55, 0x99, 0xf3, 0x7b, 0xab, 0x57, 0xfa, 0xb1, 0x85, 0xd7, 0x95, 0x8f, 0x71, 0x30, 0xb8, 0xdd,
+	0x38, 0x12, 0x0c, 0xfc, 0x86, 0x4c, 0x3c, 0x82, 0x36, 0x30, 0x02, 0x51, 0x00, 0x81, 0x64, 0x17,
+	0x07, 0xd6, 0x06, 0xe6, 0x92, 0x43, 0x9f, 0x73, 0x92, 0x8c, 0xf9, 0xd2, 0x50, 0x42, 0x11, 0xfa,
+	0x5c, 0x10, 0x06, 0x15, 0xa0, 0x6d, 0x50, 0xfd, 0x27, 0x52, 0x28, 0x31, 0xe0, 0xad, 0x06, 0x90,
+	0x8e, 0x8c, 0x25, 0xd9, 0xa4, 0x91, 0x61, 0x50, 0x89, 0xa3, 0x08, 0x2a, 0xa6, 0xa3, 0xaf, 0xe2,
+	0x95, 0x01, 0xa5, 0x94, 0xea, 0x23, 0x0b, 0xb7, 0x51, 0x6e, 0x68, 0xd2, 0xc4, 0x8f, 0xaf, 0xfd,
+	0x3d, 0x60, 0xef, 0x5c, 0x7f, 0x90, 0xdd, 0xb4, 0x70, 0x0d, 0xe5, 0xf6, 0xcf, 0x66, 0x8f, 0x3b,
+	0x39, 0x92, 0xda, 0xb3, 0x7c, 0xa1, 0x3e, 0xb2, 0x76, 0xdd, 0x4f, 0xb6, 0x8c, 0x5e, 0x25, 0x6e,
+	0x14, 0x8d, 0x6e, 0xb1, 0x16, 0x87, 0x7e, 0x54, 0xdb, 0xd6, 0x26, 0xc5, 0xe6, 0x69, 0xad, 0x68,
+	0xcc, 0x4e, 0x32, 0xaa, 0x11, 0x3c, 0xf9, 0xdf, 0x00, 0xca, 0x22, 0x3a, 0x30, 0x22, 0x15, 0x00,
+	0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// UserServiceClient is the client API for UserService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type UserServiceClient interface {
+	Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error)
+	ReadAll(ctx context.Context, in *ReadAllRequest, opts ...grpc.CallOption) (*ReadAllResponse, error)
+	Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (*ReadResponse, error)
+	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	UndeleteUser(ctx context.Context, in *UndeleteUserRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	BatchCreateUsers(ctx context.Context, in *BatchCreateUsersRequest, opts ...grpc.CallOption) (*BatchCreateUsersResponse, error)
+	BatchGetUsers(ctx context.Context, in *BatchGetUsersRequest, opts ...grpc.CallOption) (*BatchGetUsersResponse, error)
+	BatchDeleteUsers(ctx context.Context, in *BatchDeleteUsersRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	ListUserAuditEvents(ctx context.Context, in *ListUserAuditEventsRequest, opts ...grpc.CallOption) (*ListUserAuditEventsResponse, error)
+	WatchUsers(ctx context.Context, in *WatchUsersRequest, opts ...grpc.CallOption) (UserService_WatchUsersClient, error)
+	// Create users sent in stream. Rows are validated one by one, invalid rows and rows with email
+	// of existing user or of previous row are reported and skipped. Every message is imported
+	// in its own transaction. Gateway accepts CSV and NDJSON at POST /v1/users:import.
+	ImportUsers(ctx context.Context, opts ...grpc.CallOption) (UserService_ImportUsersClient, error)
+	// Stream users selected by filter ordered by id.
+	// Gateway gives CSV or NDJSON at GET /v1/users:export.
+	ExportUsers(ctx context.Context, in *ExportUsersRequest, opts ...grpc.CallOption) (UserService_ExportUsersClient, error)
+}
+
+type userServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewUserServiceClient(cc *grpc.ClientConn) UserServiceClient {
+	return &userServiceClient{cc}
+}
+
+func (c *userServiceClient) Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error) {
+	out := new(CreateResponse)
+	err := c.cc.Invoke(ctx, "/github.reviz0r.layout.profile.UserService/Create", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) ReadAll(ctx context.Context, in *ReadAllRequest, opts ...grpc.CallOption) (*ReadAllResponse, error) {
+	out := new(ReadAllResponse)
+	err := c.cc.Invoke(ctx, "/github.reviz0r.layout.profile.UserService/ReadAll", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (*ReadResponse, error) {
+	out := new(ReadResponse)
+	err := c.cc.Invoke(ctx, "/github.reviz0r.layout.profile.UserService/Read", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/github.reviz0r.layout.profile.UserService/Update", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/github.reviz0r.layout.profile.UserService/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) UndeleteUser(ctx context.Context, in *UndeleteUserRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/github.reviz0r.layout.profile.UserService/UndeleteUser", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) BatchCreateUsers(ctx context.Context, in *BatchCreateUsersRequest, opts ...grpc.CallOption) (*BatchCreateUsersResponse, error) {
+	out := new(BatchCreateUsersResponse)
+	err := c.cc.Invoke(ctx, "/github.reviz0r.layout.profile.UserService/BatchCreateUsers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) BatchGetUsers(ctx context.Context, in *BatchGetUsersRequest, opts ...grpc.CallOption) (*BatchGetUsersResponse, error) {
+	out := new(BatchGetUsersResponse)
+	err := c.cc.Invoke(ctx, "/github.reviz0r.layout.profile.UserService/BatchGetUsers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) BatchDeleteUsers(ctx context.Context, in *BatchDeleteUsersRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/github.reviz0r.layout.profile.UserService/BatchDeleteUsers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) ListUserAuditEvents(ctx context.Context, in *ListUserAuditEventsRequest, opts ...grpc.CallOption) (*ListUserAuditEventsResponse, error) {
+	out := new(ListUserAuditEventsResponse)
+	err := c.cc.Invoke(ctx, "/github.reviz0r.layout.profile.UserService/ListUserAuditEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) WatchUsers(ctx context.Context, in *WatchUsersRequest, opts ...grpc.CallOption) (UserService_WatchUsersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_UserService_serviceDesc.Streams[0], "/github.reviz0r.layout.profile.UserService/WatchUsers", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &userServiceWatchUsersClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type UserService_WatchUsersClient interface {
+	Recv() (*UserEvent, error)
+	grpc.ClientStream
+}
+
+type userServiceWatchUsersClient struct {
+	grpc.ClientStream
+}
+
+func (x *userServiceWatchUsersClient) Recv() (*UserEvent, error) {
+	m := new(UserEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *userServiceClient) ImportUsers(ctx context.Context, opts ...grpc.CallOption) (UserService_ImportUsersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_UserService_serviceDesc.Streams[1], "/github.reviz0r.layout.profile.UserService/ImportUsers", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &userServiceImportUsersClient{stream}
+	return x, nil
+}
+
+type UserService_ImportUsersClient interface {
+	Send(*ImportUsersRequest) error
+	CloseAndRecv() (*ImportUsersResponse, error)
+	grpc.ClientStream
+}
+
+type userServiceImportUsersClient struct {
+	grpc.ClientStream
+}
+
+func (x *userServiceImportUsersClient) Send(m *ImportUsersRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *userServiceImportUsersClient) CloseAndRecv() (*ImportUsersResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportUsersResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *userServiceClient) ExportUsers(ctx context.Context, in *ExportUsersRequest, opts ...grpc.CallOption) (UserService_ExportUsersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_UserService_serviceDesc.Streams[2], "/github.reviz0r.layout.profile.UserService/ExportUsers", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &userServiceExportUsersClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type UserService_ExportUsersClient interface {
+	Recv() (*User, error)
+	grpc.ClientStream
+}
+
+type userServiceExportUsersClient struct {
+	grpc.ClientStream
+}
+
+func (x *userServiceExportUsersClient) Recv() (*User, error) {
+	m := new(User)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// UserServiceServer is the server API for UserService service.
+type UserServiceServer interface {
+	Create(context.Context, *CreateRequest) (*CreateResponse, error)
+	ReadAll(context.Context, *ReadAllRequest) (*ReadAllResponse, error)
+	Read(context.Context, *ReadRequest) (*ReadResponse, error)
+	Update(context.Context, *UpdateRequest) (*empty.Empty, error)
+	Delete(context.Context, *DeleteRequest) (*empty.Empty, error)
+	UndeleteUser(context.Context, *UndeleteUserRequest) (*empty.Empty, error)
+	BatchCreateUsers(context.Context, *BatchCreateUsersRequest) (*BatchCreateUsersResponse, error)
+	BatchGetUsers(context.Context, *BatchGetUsersRequest) (*BatchGetUsersResponse, error)
+	BatchDeleteUsers(context.Context, *BatchDeleteUsersRequest) (*empty.Empty, error)
+	ListUserAuditEvents(context.Context, *ListUserAuditEventsRequest) (*ListUserAuditEventsResponse, error)
+	WatchUsers(*WatchUsersRequest, UserService_WatchUsersServer) error
+	// Create users sent in stream. Rows are validated one by one, invalid rows and rows with email
+	// of existing user or of previous row are reported and skipped. Every message is imported
+	// in its own transaction. Gateway accepts CSV and NDJSON at POST /v1/users:import.
+	ImportUsers(UserService_ImportUsersServer) error
+	// Stream users selected by filter ordered by id.
+	// Gateway gives CSV or NDJSON at GET /v1/users:export.
+	ExportUsers(*ExportUsersRequest, UserService_ExportUsersServer) error
+}
+
+// UnimplementedUserServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedUserServiceServer struct {
+}
+
+func (*UnimplementedUserServiceServer) Create(ctx context.Context, req *CreateRequest) (*CreateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (*UnimplementedUserServiceServer) ReadAll(ctx context.Context, req *ReadAllRequest) (*ReadAllResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadAll not implemented")
+}
+func (*UnimplementedUserServiceServer) Read(ctx context.Context, req *ReadRequest) (*ReadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Read not implemented")
+}
+func (*UnimplementedUserServiceServer) Update(ctx context.Context, req *UpdateRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (*UnimplementedUserServiceServer) Delete(ctx context.Context, req *DeleteRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (*UnimplementedUserServiceServer) UndeleteUser(ctx context.Context, req *UndeleteUserRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UndeleteUser not implemented")
+}
+func (*UnimplementedUserServiceServer) BatchCreateUsers(ctx context.Context, req *BatchCreateUsersRequest) (*BatchCreateUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchCreateUsers not implemented")
+}
+func (*UnimplementedUserServiceServer) BatchGetUsers(ctx context.Context, req *BatchGetUsersRequest) (*BatchGetUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetUsers not implemented")
+}
+func (*UnimplementedUserServiceServer) BatchDeleteUsers(ctx context.Context, req *BatchDeleteUsersRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchDeleteUsers not implemented")
+}
+func (*UnimplementedUserServiceServer) ListUserAuditEvents(ctx context.Context, req *ListUserAuditEventsRequest) (*ListUserAuditEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUserAuditEvents not implemented")
+}
+func (*UnimplementedUserServiceServer) WatchUsers(req *WatchUsersRequest, srv UserService_WatchUsersServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchUsers not implemented")
+}
+func (*UnimplementedUserServiceServer) ImportUsers(srv UserService_ImportUsersServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportUsers not implemented")
+}
+func (*UnimplementedUserServiceServer) ExportUsers(req *ExportUsersRequest, srv UserService_ExportUsersServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportUsers not implemented")
+}
+
+func RegisterUserServiceServer(s *grpc.Server, srv UserServiceServer) {
+	s.RegisterService(&_UserService_serviceDesc, srv)
+}
+
+func _UserService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/github.reviz0r.layout.profile.UserService/Create",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).Create(ctx, req.(*CreateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_ReadAll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadAllRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).ReadAll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/github.reviz0r.layout.profile.UserService/ReadAll",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).ReadAll(ctx, req.(*ReadAllRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_Read_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).Read(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/github.reviz0r.layout.profile.UserService/Read",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).Read(ctx, req.(*ReadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).Update(ctx, in)
+	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
 		FullMethod: "/github.reviz0r.layout.profile.UserService/Update",
@@ -658,6 +1720,164 @@ func _UserService_Delete_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UserService_UndeleteUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UndeleteUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).UndeleteUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/github.reviz0r.layout.profile.UserService/UndeleteUser",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).UndeleteUser(ctx, req.(*UndeleteUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_BatchCreateUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCreateUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).BatchCreateUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/github.reviz0r.layout.profile.UserService/BatchCreateUsers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).BatchCreateUsers(ctx, req.(*BatchCreateUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_BatchGetUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).BatchGetUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/github.reviz0r.layout.profile.UserService/BatchGetUsers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).BatchGetUsers(ctx, req.(*BatchGetUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_BatchDeleteUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchDeleteUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).BatchDeleteUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/github.reviz0r.layout.profile.UserService/BatchDeleteUsers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).BatchDeleteUsers(ctx, req.(*BatchDeleteUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_ListUserAuditEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUserAuditEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).ListUserAuditEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/github.reviz0r.layout.profile.UserService/ListUserAuditEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).ListUserAuditEvents(ctx, req.(*ListUserAuditEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_WatchUsers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchUsersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UserServiceServer).WatchUsers(m, &userServiceWatchUsersServer{stream})
+}
+
+type UserService_WatchUsersServer interface {
+	Send(*UserEvent) error
+	grpc.ServerStream
+}
+
+type userServiceWatchUsersServer struct {
+	grpc.ServerStream
+}
+
+func (x *userServiceWatchUsersServer) Send(m *UserEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _UserService_ImportUsers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(UserServiceServer).ImportUsers(&userServiceImportUsersServer{stream})
+}
+
+type UserService_ImportUsersServer interface {
+	SendAndClose(*ImportUsersResponse) error
+	Recv() (*ImportUsersRequest, error)
+	grpc.ServerStream
+}
+
+type userServiceImportUsersServer struct {
+	grpc.ServerStream
+}
+
+func (x *userServiceImportUsersServer) SendAndClose(m *ImportUsersResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *userServiceImportUsersServer) Recv() (*ImportUsersRequest, error) {
+	m := new(ImportUsersRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _UserService_ExportUsers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportUsersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UserServiceServer).ExportUsers(m, &userServiceExportUsersServer{stream})
+}
+
+type UserService_ExportUsersServer interface {
+	Send(*User) error
+	grpc.ServerStream
+}
+
+type userServiceExportUsersServer struct {
+	grpc.ServerStream
+}
+
+func (x *userServiceExportUsersServer) Send(m *User) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _UserService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "github.reviz0r.layout.profile.UserService",
 	HandlerType: (*UserServiceServer)(nil),
@@ -682,7 +1902,43 @@ var _UserService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _UserService_Delete_Handler,
 		},
+		{
+			MethodName: "UndeleteUser",
+			Handler:    _UserService_UndeleteUser_Handler,
+		},
+		{
+			MethodName: "BatchCreateUsers",
+			Handler:    _UserService_BatchCreateUsers_Handler,
+		},
+		{
+			MethodName: "BatchGetUsers",
+			Handler:    _UserService_BatchGetUsers_Handler,
+		},
+		{
+			MethodName: "BatchDeleteUsers",
+			Handler:    _UserService_BatchDeleteUsers_Handler,
+		},
+		{
+			MethodName: "ListUserAuditEvents",
+			Handler:    _UserService_ListUserAuditEvents_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchUsers",
+			Handler:       _UserService_WatchUsers_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportUsers",
+			Handler:       _UserService_ImportUsers_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportUsers",
+			Handler:       _UserService_ExportUsers_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "profile_api.proto",
 }
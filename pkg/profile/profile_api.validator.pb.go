@@ -95,3 +95,125 @@ func (this *DeleteRequest) Validate() error {
 	}
 	return nil
 }
+func (this *UndeleteUserRequest) Validate() error {
+	if !(this.Id > 0) {
+		return github_com_mwitkow_go_proto_validators.FieldError("Id", fmt.Errorf(`value '%v' must be greater than '0'`, this.Id))
+	}
+	return nil
+}
+func (this *BatchCreateUsersRequest) Validate() error {
+	if len(this.Users) < 1 {
+		return github_com_mwitkow_go_proto_validators.FieldError("Users", fmt.Errorf(`value '%v' must contain at least 1 elements`, this.Users))
+	}
+	if len(this.Users) > 1000 {
+		return github_com_mwitkow_go_proto_validators.FieldError("Users", fmt.Errorf(`value '%v' must contain at most 1000 elements`, this.Users))
+	}
+	for _, item := range this.Users {
+		if item != nil {
+			if err := github_com_mwitkow_go_proto_validators.CallValidatorIfExists(item); err != nil {
+				return github_com_mwitkow_go_proto_validators.FieldError("Users", err)
+			}
+		}
+	}
+	return nil
+}
+func (this *BatchCreateUsersResponse) Validate() error {
+	return nil
+}
+func (this *BatchGetUsersRequest) Validate() error {
+	if len(this.Ids) < 1 {
+		return github_com_mwitkow_go_proto_validators.FieldError("Ids", fmt.Errorf(`value '%v' must contain at least 1 elements`, this.Ids))
+	}
+	if len(this.Ids) > 1000 {
+		return github_com_mwitkow_go_proto_validators.FieldError("Ids", fmt.Errorf(`value '%v' must contain at most 1000 elements`, this.Ids))
+	}
+	for _, item := range this.Ids {
+		if !(item > 0) {
+			return github_com_mwitkow_go_proto_validators.FieldError("Ids", fmt.Errorf(`value '%v' must be greater than '0'`, item))
+		}
+	}
+	if this.Fields != nil {
+		if err := github_com_mwitkow_go_proto_validators.CallValidatorIfExists(this.Fields); err != nil {
+			return github_com_mwitkow_go_proto_validators.FieldError("Fields", err)
+		}
+	}
+	return nil
+}
+func (this *BatchGetUsersResponse) Validate() error {
+	for _, item := range this.Users {
+		if item != nil {
+			if err := github_com_mwitkow_go_proto_validators.CallValidatorIfExists(item); err != nil {
+				return github_com_mwitkow_go_proto_validators.FieldError("Users", err)
+			}
+		}
+	}
+	return nil
+}
+func (this *BatchDeleteUsersRequest) Validate() error {
+	if len(this.Ids) < 1 {
+		return github_com_mwitkow_go_proto_validators.FieldError("Ids", fmt.Errorf(`value '%v' must contain at least 1 elements`, this.Ids))
+	}
+	if len(this.Ids) > 1000 {
+		return github_com_mwitkow_go_proto_validators.FieldError("Ids", fmt.Errorf(`value '%v' must contain at most 1000 elements`, this.Ids))
+	}
+	for _, item := range this.Ids {
+		if !(item > 0) {
+			return github_com_mwitkow_go_proto_validators.FieldError("Ids", fmt.Errorf(`value '%v' must be greater than '0'`, item))
+		}
+	}
+	return nil
+}
+func (this *ListUserAuditEventsRequest) Validate() error {
+	if !(this.Id > 0) {
+		return github_com_mwitkow_go_proto_validators.FieldError("Id", fmt.Errorf(`value '%v' must be greater than '0'`, this.Id))
+	}
+	return nil
+}
+func (this *ListUserAuditEventsResponse) Validate() error {
+	for _, item := range this.Events {
+		if item != nil {
+			if err := github_com_mwitkow_go_proto_validators.CallValidatorIfExists(item); err != nil {
+				return github_com_mwitkow_go_proto_validators.FieldError("Events", err)
+			}
+		}
+	}
+	return nil
+}
+func (this *WatchUsersRequest) Validate() error {
+	if !(this.Revision > -1) {
+		return github_com_mwitkow_go_proto_validators.FieldError("Revision", fmt.Errorf(`value '%v' must be greater than '-1'`, this.Revision))
+	}
+	return nil
+}
+func (this *ImportUsersRequest) Validate() error {
+	if len(this.Rows) > 1000 {
+		return github_com_mwitkow_go_proto_validators.FieldError("Rows", fmt.Errorf(`value '%v' must contain at most 1000 elements`, this.Rows))
+	}
+	for _, item := range this.Rows {
+		if item != nil {
+			if err := github_com_mwitkow_go_proto_validators.CallValidatorIfExists(item); err != nil {
+				return github_com_mwitkow_go_proto_validators.FieldError("Rows", err)
+			}
+		}
+	}
+	return nil
+}
+func (this *ImportUsersRequest_Row) Validate() error {
+	return nil
+}
+func (this *ImportUsersResponse) Validate() error {
+	for _, item := range this.Problems {
+		if item != nil {
+			if err := github_com_mwitkow_go_proto_validators.CallValidatorIfExists(item); err != nil {
+				return github_com_mwitkow_go_proto_validators.FieldError("Problems", err)
+			}
+		}
+	}
+	return nil
+}
+func (this *ImportUsersResponse_Problem) Validate() error {
+	return nil
+}
+func (this *ExportUsersRequest) Validate() error {
+	return nil
+}
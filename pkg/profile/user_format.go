@@ -0,0 +1,206 @@
+package profile
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+// Formats of imported and exported users
+const (
+	// FormatCSV has header row, import needs name and email columns only
+	FormatCSV = "csv"
+	// FormatNDJSON has JSON object of user on every line
+	FormatNDJSON = "ndjson"
+)
+
+// maxNDJSONLine limits length of line of imported NDJSON
+const maxNDJSONLine = 1 << 20
+
+// csvUserColumns are columns of exported CSV
+var csvUserColumns = []string{"id", "name", "email", "etag", "create_time", "update_time", "delete_time", "created_by", "updated_by"}
+
+// RowReader reads rows of import, it gives io.EOF after the last row
+type RowReader interface {
+	Read() (*ImportUsersRequest_Row, error)
+}
+
+// NewRowReader gives reader of rows in format
+func NewRowReader(r io.Reader, format string) (RowReader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVRowReader(r)
+	case FormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), maxNDJSONLine)
+		return &ndjsonRowReader{scanner: scanner}, nil
+	}
+
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+type csvRowReader struct {
+	r           *csv.Reader
+	name, email int
+}
+
+func newCSVRowReader(r io.Reader) (*csvRowReader, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("csv: header is missing")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("csv: %v", err)
+	}
+
+	reader := &csvRowReader{r: cr, name: -1, email: -1}
+	for i, column := range header {
+		switch strings.ToLower(strings.TrimSpace(column)) {
+		case "name":
+			reader.name = i
+		case "email":
+			reader.email = i
+		}
+	}
+	if reader.name < 0 || reader.email < 0 {
+		return nil, fmt.Errorf("csv: header must have name and email columns")
+	}
+
+	return reader, nil
+}
+
+func (r *csvRowReader) Read() (*ImportUsersRequest_Row, error) {
+	record, err := r.r.Read()
+	if err == io.EOF {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("csv: %v", err)
+	}
+
+	return &ImportUsersRequest_Row{Name: record[r.name], Email: record[r.email]}, nil
+}
+
+type ndjsonRowReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (r *ndjsonRowReader) Read() (*ImportUsersRequest_Row, error) {
+	// exported users can be imported as is, so fields other than name and email are ignored
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+
+	for r.scanner.Scan() {
+		r.line++
+		if strings.TrimSpace(r.scanner.Text()) == "" {
+			continue
+		}
+
+		var row ImportUsersRequest_Row
+		if err := unmarshaler.Unmarshal(strings.NewReader(r.scanner.Text()), &row); err != nil {
+			return nil, fmt.Errorf("ndjson: line %d: %v", r.line, err)
+		}
+		return &row, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ndjson: line %d: %v", r.line+1, err)
+	}
+	return nil, io.EOF
+}
+
+// UserWriter writes exported users, Flush must be called after the last one
+type UserWriter interface {
+	Write(user *User) error
+	Flush() error
+}
+
+// NewUserWriter gives writer of users in format
+func NewUserWriter(w io.Writer, format string) (UserWriter, error) {
+	switch format {
+	case FormatCSV:
+		return &csvUserWriter{w: csv.NewWriter(w)}, nil
+	case FormatNDJSON:
+		return &ndjsonUserWriter{w: bufio.NewWriter(w), marshaler: jsonpb.Marshaler{OrigName: true}}, nil
+	}
+
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+type csvUserWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (w *csvUserWriter) Write(user *User) error {
+	if !w.headerWritten {
+		if err := w.w.Write(csvUserColumns); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+
+	return w.w.Write([]string{
+		strconv.FormatInt(user.GetId(), 10),
+		user.GetName(),
+		user.GetEmail(),
+		user.GetEtag(),
+		formatTimestamp(user.GetCreateTime()),
+		formatTimestamp(user.GetUpdateTime()),
+		formatTimestamp(user.GetDeleteTime()),
+		user.GetCreatedBy(),
+		user.GetUpdatedBy(),
+	})
+}
+
+func (w *csvUserWriter) Flush() error {
+	if !w.headerWritten {
+		// header is written even if there are no users
+		if err := w.w.Write(csvUserColumns); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+
+	w.w.Flush()
+	return w.w.Error()
+}
+
+type ndjsonUserWriter struct {
+	w         *bufio.Writer
+	marshaler jsonpb.Marshaler
+}
+
+func (w *ndjsonUserWriter) Write(user *User) error {
+	if err := w.marshaler.Marshal(w.w, user); err != nil {
+		return err
+	}
+	return w.w.WriteByte('\n')
+}
+
+func (w *ndjsonUserWriter) Flush() error {
+	return w.w.Flush()
+}
+
+func formatTimestamp(ts *timestamp.Timestamp) string {
+	if ts == nil {
+		return ""
+	}
+
+	t, err := ptypes.Timestamp(ts)
+	if err != nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
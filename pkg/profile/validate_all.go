@@ -0,0 +1,45 @@
+package profile
+
+import (
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ValidateAll works as Validate, but reports all invalid users at once
+// as google.rpc.BadRequest details of InvalidArgument status
+func (this *BatchCreateUsersRequest) ValidateAll() error {
+	var violations []*errdetails.BadRequest_FieldViolation
+	for i, user := range this.GetUsers() {
+		var err error
+		if user == nil {
+			err = fmt.Errorf("message must exist")
+		} else {
+			err = user.Validate()
+		}
+
+		if err != nil {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       fmt.Sprintf("users[%d]", i),
+				Description: err.Error(),
+			})
+		}
+	}
+
+	if len(violations) == 0 {
+		if err := this.Validate(); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil
+	}
+
+	st, err := status.New(codes.InvalidArgument, fmt.Sprintf("%d of %d users are invalid", len(violations), len(this.GetUsers()))).
+		WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return st.Err()
+}
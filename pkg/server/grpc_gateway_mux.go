@@ -2,15 +2,20 @@ package server
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
-	"github.com/spf13/viper"
 	"go.uber.org/fx"
+
+	"github.com/reviz0r/golang-layout/pkg/config"
 )
 
 var GatewayMuxModule = fx.Options(
+	fx.Provide(NewGatewayDialOption),
 	fx.Provide(NewServeMuxMarshallerOption),
+	fx.Provide(NewServeMuxFieldMaskMarshallerOption),
+	fx.Provide(NewServeMuxHeaderMatcherOption),
 	fx.Provide(NewGatewayServeMux),
 	fx.Invoke(RegisterProtoMux),
 )
@@ -37,18 +42,88 @@ type ServeMuxMarshallerResult struct {
 	Option runtime.ServeMuxOption `group:"gateway_server_mux_options"`
 }
 
-func NewServeMuxMarshallerOption(p ServeMuxMarshallerParams, config *viper.Viper) ServeMuxMarshallerResult {
+func NewServeMuxMarshallerOption(p ServeMuxMarshallerParams, config config.GatewayConfig) ServeMuxMarshallerResult {
 	marshaller := &runtime.JSONPb{
-		EnumsAsInts:  config.GetBool("gateway.marshaler.enums_as_ints"),
-		EmitDefaults: config.GetBool("gateway.marshaler.emit_defaults"),
-		Indent:       config.GetString("gateway.marshaler.indent"),
-		OrigName:     config.GetBool("gateway.marshaler.orig_name"),
+		EnumsAsInts:  config.Marshaler.EnumsAsInts,
+		EmitDefaults: config.Marshaler.EmitDefaults,
+		Indent:       config.Marshaler.Indent,
+		OrigName:     config.Marshaler.OrigName,
 		AnyResolver:  p.AnyResolver,
 	}
 
 	return ServeMuxMarshallerResult{Option: runtime.WithMarshalerOption(runtime.MIMEWildcard, marshaller)}
 }
 
+// MIMEFieldMask is used for responses to requests with field mask.
+// Fields which were not requested have zero values and are omitted, even if emit_defaults is set.
+const MIMEFieldMask = "application/x-field-mask+json"
+
+func NewServeMuxFieldMaskMarshallerOption(p ServeMuxMarshallerParams, config config.GatewayConfig) ServeMuxMarshallerResult {
+	marshaller := &runtime.JSONPb{
+		EnumsAsInts:  config.Marshaler.EnumsAsInts,
+		EmitDefaults: false,
+		Indent:       config.Marshaler.Indent,
+		OrigName:     config.Marshaler.OrigName,
+		AnyResolver:  p.AnyResolver,
+	}
+
+	return ServeMuxMarshallerResult{Option: runtime.WithMarshalerOption(MIMEFieldMask, marshaller)}
+}
+
+// FieldMaskHandler selects MIMEFieldMask marshaller for requests with "fields" query parameter
+func FieldMaskHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hasFieldMask(r) {
+			r.Header.Set("Accept", MIMEFieldMask)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func hasFieldMask(r *http.Request) bool {
+	switch r.Header.Get("Accept") {
+	case "", "*/*", "application/json":
+	default:
+		return false
+	}
+
+	for key := range r.URL.Query() {
+		if key == "fields" || strings.HasPrefix(key, "fields.") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ServeMuxHeaderMatcherResult .
+type ServeMuxHeaderMatcherResult struct {
+	fx.Out
+
+	Option runtime.ServeMuxOption `group:"gateway_server_mux_options"`
+}
+
+// NewServeMuxHeaderMatcherOption forwards X-Request-Id, X-Read-Primary and X-Api-Key headers to grpc metadata as is,
+// other headers are forwarded as by default. Authorization header is forwarded as authorization metadata by gateway itself.
+func NewServeMuxHeaderMatcherOption() ServeMuxHeaderMatcherResult {
+	matcher := func(key string) (string, bool) {
+		switch http.CanonicalHeaderKey(key) {
+		case "X-Request-Id":
+			return "x-request-id", true
+		case "X-Read-Primary":
+			return "x-read-primary", true
+		case "X-Api-Key":
+			return "x-api-key", true
+		case "Authorization":
+			// it would be copied to grpcgateway-authorization otherwise
+			return "", false
+		}
+		return runtime.DefaultHeaderMatcher(key)
+	}
+
+	return ServeMuxHeaderMatcherResult{Option: runtime.WithIncomingHeaderMatcher(matcher)}
+}
+
 func RegisterProtoMux(mux *http.ServeMux, gatewayMux *runtime.ServeMux) {
-	mux.Handle("/", gatewayMux)
+	mux.Handle("/", FieldMaskHandler(gatewayMux))
 }
@@ -17,6 +17,15 @@ import (
 
 var InterceptorsModule = fx.Provide(NewStreamServerInterceptors, NewUnaryServerInterceptors)
 
+// ServerInterceptorParams are interceptors of other modules, e.g. of authentication.
+// They are chained after recovery and before validation of requests.
+type ServerInterceptorParams struct {
+	fx.In
+
+	Unary  []grpc.UnaryServerInterceptor  `group:"grpc_unary_interceptors"`
+	Stream []grpc.StreamServerInterceptor `group:"grpc_stream_interceptors"`
+}
+
 type ServerInterceptorResult struct {
 	fx.Out
 
@@ -24,29 +33,38 @@ type ServerInterceptorResult struct {
 }
 
 func NewStreamServerInterceptors(logger *logrus.Entry, tracer opentracing.Tracer,
-	PayloadLoggingDecider grpcLogging.ServerPayloadLoggingDecider) ServerInterceptorResult {
-	o := grpc.StreamInterceptor(grpcMiddleware.ChainStreamServer(
+	PayloadLoggingDecider grpcLogging.ServerPayloadLoggingDecider, p ServerInterceptorParams) ServerInterceptorResult {
+	interceptors := []grpc.StreamServerInterceptor{
 		grpcLogrus.StreamServerInterceptor(logger),
 		grpcLogrus.PayloadStreamServerInterceptor(logger, PayloadLoggingDecider),
 		grpcPrometheus.StreamServerInterceptor,
 		grpcOpenTracing.OpenTracingStreamServerInterceptor(tracer),
 		grpcRecovery.StreamServerInterceptor(),
+	}
+	interceptors = append(interceptors, p.Stream...)
+	interceptors = append(interceptors,
 		grpcValidator.StreamServerInterceptor(),
-	))
+	)
+	o := grpc.StreamInterceptor(grpcMiddleware.ChainStreamServer(interceptors...))
 
 	return ServerInterceptorResult{Option: o}
 }
 
 func NewUnaryServerInterceptors(logger *logrus.Entry, tracer opentracing.Tracer,
-	PayloadLoggingDecider grpcLogging.ServerPayloadLoggingDecider) ServerInterceptorResult {
-	o := grpc.UnaryInterceptor(grpcMiddleware.ChainUnaryServer(
+	PayloadLoggingDecider grpcLogging.ServerPayloadLoggingDecider, p ServerInterceptorParams) ServerInterceptorResult {
+	interceptors := []grpc.UnaryServerInterceptor{
 		grpcLogrus.UnaryServerInterceptor(logger),
 		grpcLogrus.PayloadUnaryServerInterceptor(logger, PayloadLoggingDecider),
 		grpcPrometheus.UnaryServerInterceptor,
 		grpcOpenTracing.OpenTracingServerInterceptor(tracer),
 		grpcRecovery.UnaryServerInterceptor(),
+	}
+	interceptors = append(interceptors, p.Unary...)
+	interceptors = append(interceptors,
+		ValidateAllUnaryServerInterceptor(),
 		grpcValidator.UnaryServerInterceptor(),
-	))
+	)
+	o := grpc.UnaryInterceptor(grpcMiddleware.ChainUnaryServer(interceptors...))
 
 	return ServerInterceptorResult{Option: o}
 }
@@ -2,17 +2,33 @@ package server
 
 import (
 	"context"
+	"sync/atomic"
 
 	grpc_logging "github.com/grpc-ecosystem/go-grpc-middleware/logging"
-	"github.com/spf13/viper"
 	"go.uber.org/fx"
+
+	"github.com/reviz0r/golang-layout/pkg/config"
 )
 
 var GrpcLoggingPayloadModule = fx.Provide(LoggingPayloadDecider)
 
-// LoggingPayloadDecider decide is need to log payload
-func LoggingPayloadDecider(config *viper.Viper) grpc_logging.ServerPayloadLoggingDecider {
+// LoggingPayloadDecider decide is need to log payload, logger.log_grpc_payload is applied without restart
+func LoggingPayloadDecider(loggerConfig config.LoggerConfig, w *config.Watcher) grpc_logging.ServerPayloadLoggingDecider {
+	var logPayload int32
+	store := func(c config.LoggerConfig) {
+		var v int32
+		if c.LogGRPCPayload {
+			v = 1
+		}
+		atomic.StoreInt32(&logPayload, v)
+	}
+
+	store(loggerConfig)
+	w.Subscribe(func(change config.Change) {
+		store(change.New.Logger)
+	})
+
 	return func(ctx context.Context, fullMethodName string, servingObject interface{}) bool {
-		return config.GetBool("logger.log_grpc_payload")
+		return atomic.LoadInt32(&logPayload) == 1
 	}
 }
@@ -2,13 +2,18 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"sync/atomic"
 
 	"github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
 	"go.uber.org/fx"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/reviz0r/golang-layout/pkg/config"
+	"github.com/reviz0r/golang-layout/pkg/health"
 )
 
 // Module register grpc server in DI container
@@ -21,19 +26,55 @@ type GrpcServerParams struct {
 	ServerOptions []grpc.ServerOption `group:"grpc_server_options"`
 }
 
-// NewGrpcServer gives new predefined grpc server
-func NewGrpcServer(lc fx.Lifecycle, config *viper.Viper, logger *logrus.Entry, p GrpcServerParams) *grpc.Server {
-	s := grpc.NewServer(p.ServerOptions...)
+// GrpcServerResult .
+type GrpcServerResult struct {
+	fx.Out
+
+	Server *grpc.Server
+	Check  health.Check `group:"health_checks"`
+}
+
+// NewGrpcServer gives new predefined grpc server and liveness check which fails if it is not serving.
+// Server uses TLS if grpc.tls.cert_file is set.
+func NewGrpcServer(lc fx.Lifecycle, config config.GRPCConfig, logger *logrus.Entry, p GrpcServerParams) (GrpcServerResult, error) {
+	options := p.ServerOptions
+	tlsConfig, err := serverTLS(lc, config.TLS, logger)
+	if err != nil {
+		return GrpcServerResult{}, err
+	}
+	if tlsConfig != nil {
+		options = append([]grpc.ServerOption{grpc.Creds(credentials.NewTLS(tlsConfig))}, options...)
+	}
+
+	s := grpc.NewServer(options...)
+
+	var serving int32
+	check := health.Check{
+		Name:     "grpc",
+		Liveness: true,
+		Check: func(context.Context) error {
+			if atomic.LoadInt32(&serving) == 0 {
+				return errors.New("grpc server is not serving")
+			}
+			return nil
+		},
+	}
 
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			address := config.GetString("grpc.address")
-			lis, err := net.Listen(config.GetString("grpc.network"), address)
+			address := config.Address
+			lis, err := net.Listen(config.Network, address)
 			if err != nil {
 				return fmt.Errorf("cannot listen port %s %v", address, err)
 			}
 
-			go s.Serve(lis)
+			atomic.StoreInt32(&serving, 1)
+			go func() {
+				if err := s.Serve(lis); err != nil {
+					logger.WithError(err).Error("grpc server is failed")
+				}
+				atomic.StoreInt32(&serving, 0)
+			}()
 			logger.Debugf("grpc server started on port %s", address)
 			return nil
 		},
@@ -45,5 +86,5 @@ func NewGrpcServer(lc fx.Lifecycle, config *viper.Viper, logger *logrus.Entry, p
 		},
 	})
 
-	return s
+	return GrpcServerResult{Server: s, Check: check}, nil
 }
@@ -0,0 +1,25 @@
+package server
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// allValidator is implemented by requests which can report all their errors at once
+type allValidator interface {
+	ValidateAll() error
+}
+
+// ValidateAllUnaryServerInterceptor rejects requests which have ValidateAll method and are invalid.
+// It must be chained before grpc_validator, which stops on the first error.
+func ValidateAllUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if v, ok := req.(allValidator); ok {
+			if err := v.ValidateAll(); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"go.uber.org/fx"
+
+	"github.com/reviz0r/golang-layout/pkg/config"
+)
+
+// ConfigDebugHandler .
+var ConfigDebugHandler = fx.Invoke(HandleConfig, HandleConfigSources)
+
+// HandleConfig serves effective config at /debug/config as json, or as yaml if "format" query parameter is yaml.
+// Secrets are redacted.
+func HandleConfig(mux *http.ServeMux, layers *config.Layers) {
+	mux.HandleFunc("/debug/config", func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "json"
+		}
+
+		out, err := config.Render(config.Dump(layers), format)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		contentType := "application/json"
+		if format == "yaml" {
+			contentType = "application/yaml"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(out)
+	})
+}
+
+// ConfigSource is effective value of config key and source which gave it
+type ConfigSource struct {
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"`
+}
+
+// HandleConfigSources serves effective config with source of every key at /debug/config/sources,
+// secrets are redacted
+func HandleConfigSources(mux *http.ServeMux, layers *config.Layers) {
+	mux.HandleFunc("/debug/config/sources", func(w http.ResponseWriter, r *http.Request) {
+		sources := make(map[string]ConfigSource)
+		for key, value := range layers.Values() {
+			source := ConfigSource{Value: layers.Redact(key, value), Source: layers.Origin(key)}
+			if d, ok := source.Value.(time.Duration); ok {
+				source.Value = d.String()
+			}
+			sources[key] = source
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(sources)
+	})
+}
@@ -7,26 +7,37 @@ import (
 	"net/http"
 
 	"github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
 	"go.uber.org/fx"
+
+	"github.com/reviz0r/golang-layout/pkg/config"
 )
 
 var HTTPModule = fx.Provide(NewServeMux)
 
-func NewServeMux(lc fx.Lifecycle, config *viper.Viper, logger *logrus.Entry) *http.ServeMux {
+// NewServeMux gives mux of http server, server uses TLS if http.tls.cert_file is set
+func NewServeMux(lc fx.Lifecycle, config config.HTTPConfig, logger *logrus.Entry) (*http.ServeMux, error) {
 	mux := http.NewServeMux()
 
-	address := config.GetString("http.address")
-	s := http.Server{Addr: address, Handler: mux}
+	tlsConfig, err := serverTLS(lc, config.TLS, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	address := config.Address
+	s := http.Server{Addr: address, Handler: mux, TLSConfig: tlsConfig}
 
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			lis, err := net.Listen(config.GetString("http.network"), address)
+			lis, err := net.Listen(config.Network, address)
 			if err != nil {
 				return fmt.Errorf("cannot listen port %s %v", address, err)
 			}
 
-			go s.Serve(lis)
+			if tlsConfig != nil {
+				go s.ServeTLS(lis, "", "")
+			} else {
+				go s.Serve(lis)
+			}
 			logger.Debugf("http server started on port %s", address)
 			return nil
 		},
@@ -37,5 +48,5 @@ func NewServeMux(lc fx.Lifecycle, config *viper.Viper, logger *logrus.Entry) *ht
 		},
 	})
 
-	return mux
+	return mux, nil
 }
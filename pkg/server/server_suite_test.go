@@ -0,0 +1,144 @@
+package server_test
+
+import (
+	"context"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/reviz0r/golang-layout/pkg/certs"
+	"github.com/reviz0r/golang-layout/pkg/config"
+	"github.com/reviz0r/golang-layout/pkg/mockcerts"
+	"github.com/reviz0r/golang-layout/pkg/server"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestServer(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Server Suite")
+}
+
+var _ = Describe("GatewayCredentials", func() {
+	var (
+		dir     string
+		ca      *mockcerts.Authority
+		caFile  string
+		servers []*grpc.Server
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "server")
+		Expect(err).NotTo(HaveOccurred())
+
+		ca, err = mockcerts.NewAuthority("ca")
+		Expect(err).NotTo(HaveOccurred())
+		caFile, err = ca.WriteCA(dir, "ca.crt")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		for _, s := range servers {
+			s.Stop()
+		}
+		servers = nil
+		os.RemoveAll(dir)
+	})
+
+	// serve starts grpc server of hosts with health service over TLS, client certificates are verified by CA
+	// of the suite
+	serve := func(clientAuth string, hosts ...string) string {
+		certFile, keyFile, err := ca.WriteFiles(dir, "server", x509.ExtKeyUsageServerAuth, hosts...)
+		Expect(err).NotTo(HaveOccurred())
+		store, err := certs.NewStore(certFile, keyFile, caFile)
+		Expect(err).NotTo(HaveOccurred())
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+
+		s := grpc.NewServer(grpc.Creds(credentials.NewTLS(store.ServerConfig(clientAuth))))
+		healthpb.RegisterHealthServer(s, grpchealth.NewServer())
+		go s.Serve(lis)
+		servers = append(servers, s)
+
+		return lis.Addr().String()
+	}
+
+	check := func(addr string, c config.GatewayTLSConfig) error {
+		option, _, err := server.GatewayCredentials(c, addr)
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		conn, err := grpc.DialContext(ctx, addr, option)
+		Expect(err).NotTo(HaveOccurred())
+		defer conn.Close()
+
+		_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		return err
+	}
+
+	It("gives insecure option if TLS is disabled", func() {
+		option, store, err := server.GatewayCredentials(config.GatewayTLSConfig{}, "localhost:50051")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(option).NotTo(BeNil())
+		Expect(store).To(BeNil())
+	})
+
+	It("fails if files cannot be loaded", func() {
+		_, _, err := server.GatewayCredentials(config.GatewayTLSConfig{Enabled: true, CAFile: filepath.Join(dir, "missing.crt")},
+			"localhost:50051")
+		Expect(err).To(MatchError(HavePrefix("certs: cannot load CA: ")))
+	})
+
+	It("connects to server with client certificate", func() {
+		addr := serve(certs.ClientAuthRequire, "localhost")
+		certFile, keyFile, err := ca.WriteFiles(dir, "gateway", x509.ExtKeyUsageClientAuth)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = check(addr, config.GatewayTLSConfig{
+			Enabled: true, CAFile: caFile, CertFile: certFile, KeyFile: keyFile, ServerName: "localhost",
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("is rejected without client certificate unless client auth is optional", func() {
+		c := config.GatewayTLSConfig{Enabled: true, CAFile: caFile, ServerName: "localhost"}
+
+		Expect(check(serve(certs.ClientAuthRequire, "localhost"), c)).To(HaveOccurred())
+		Expect(check(serve(certs.ClientAuthOptional, "localhost"), c)).To(Succeed())
+	})
+
+	It("fails if there is no server name", func() {
+		_, _, err := server.GatewayCredentials(config.GatewayTLSConfig{Enabled: true, CAFile: caFile}, ":50051")
+		Expect(err).To(MatchError("server: gateway.tls.server_name must be set to connect to :50051"))
+	})
+
+	It("verifies IP address of target if server name is not set", func() {
+		c := config.GatewayTLSConfig{Enabled: true, CAFile: caFile}
+
+		Expect(check(serve(certs.ClientAuthOptional, "127.0.0.1"), c)).To(Succeed())
+
+		err := check(serve(certs.ClientAuthOptional, "localhost", "10.0.0.5"), c)
+		Expect(err).To(MatchError(ContainSubstring("not 127.0.0.1")))
+	})
+
+	It("verifies name of server", func() {
+		addr := serve(certs.ClientAuthOptional, "localhost")
+
+		err := check(addr, config.GatewayTLSConfig{Enabled: true, CAFile: caFile, ServerName: "profile.example.com"})
+		Expect(err).To(MatchError(ContainSubstring("profile.example.com")))
+	})
+})
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+
+	"github.com/sirupsen/logrus"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/reviz0r/golang-layout/pkg/certs"
+	"github.com/reviz0r/golang-layout/pkg/config"
+)
+
+// serverTLS gives TLS config of server, it is nil if TLS is disabled.
+// Certificates are reloaded while the app is running.
+func serverTLS(lc fx.Lifecycle, config config.TLSConfig, logger *logrus.Entry) (*tls.Config, error) {
+	if !config.Enabled() {
+		return nil, nil
+	}
+
+	store, err := certs.NewStore(config.CertFile, config.KeyFile, config.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	watch(lc, store, logger)
+
+	return store.ServerConfig(config.ClientAuth), nil
+}
+
+// GatewayDialResult .
+type GatewayDialResult struct {
+	fx.Out
+
+	Option grpc.DialOption `name:"gateway_dial_option"`
+}
+
+// NewGatewayDialOption gives transport credentials of gateway connections to grpc server
+func NewGatewayDialOption(lc fx.Lifecycle, config config.GatewayConfig, logger *logrus.Entry) (GatewayDialResult, error) {
+	option, store, err := GatewayCredentials(config.TLS, config.ProfileServiceEndpoint)
+	if err != nil {
+		return GatewayDialResult{}, err
+	}
+	if store != nil {
+		watch(lc, store, logger)
+	}
+
+	return GatewayDialResult{Option: option}, nil
+}
+
+// GatewayCredentials gives dial option of target with TLS credentials, or insecure one if TLS is disabled.
+// Server certificate is verified for server_name, or for host of target if it is not set.
+// Store of client certificate is returned to be watched, it is nil if TLS is disabled.
+func GatewayCredentials(config config.GatewayTLSConfig, target string) (grpc.DialOption, *certs.Store, error) {
+	if !config.Enabled {
+		return grpc.WithInsecure(), nil, nil
+	}
+
+	serverName := config.ServerName
+	if serverName == "" {
+		serverName = target
+		if host, _, err := net.SplitHostPort(target); err == nil {
+			serverName = host
+		}
+	}
+	if serverName == "" {
+		return nil, nil, fmt.Errorf("server: gateway.tls.server_name must be set to connect to %s", target)
+	}
+
+	store, err := certs.NewStore(config.CertFile, config.KeyFile, config.CAFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return grpc.WithTransportCredentials(credentials.NewTLS(store.ClientConfig(serverName))), store, nil
+}
+
+func watch(lc fx.Lifecycle, store *certs.Store, logger *logrus.Entry) {
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			return store.Watch(logger)
+		},
+		OnStop: func(context.Context) error {
+			return store.Close()
+		},
+	})
+}